@@ -0,0 +1,173 @@
+// Package alerts watches queue latency, queue size, the dead set and
+// busy workers, and notifies webhooks when a rule starts or stops firing.
+//
+//	[alerts]
+//	webhooks = ["https://hooks.example.com/faktory"]
+//	interval = 30 # seconds between checks
+//
+//	[[alerts.rules]]
+//	name = "critical is backed up"
+//	kind = "latency"   # latency, size, dead or no_workers
+//	queue = "critical"
+//	threshold = 60     # seconds
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/util"
+)
+
+const (
+	// oldest job in the queue is older than Threshold seconds
+	Latency = "latency"
+	// queue holds more than Threshold jobs
+	Size = "size"
+	// dead set grew by more than Threshold jobs since the previous check
+	DeadGrowth = "dead"
+	// queue holds more than Threshold jobs but none are being processed
+	NoWorkers = "no_workers"
+)
+
+type Rule struct {
+	Name      string
+	Kind      string
+	Queue     string
+	Threshold float64
+}
+
+type Config struct {
+	Webhooks []string
+	Interval time.Duration
+	Rules    []Rule
+}
+
+func (c *Config) Enabled() bool {
+	return len(c.Webhooks) > 0 && len(c.Rules) > 0
+}
+
+type Lifecycle struct {
+	checker *checker
+}
+
+func Subsystem() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) Name() string {
+	return "Alerts"
+}
+
+func (l *Lifecycle) Start(s *server.Server) error {
+	cfg, err := parseConfig(s.Options)
+	if err != nil {
+		return err
+	}
+	l.checker = newChecker(cfg, serverGatherer(s))
+	s.AddTask(5, l.checker)
+	if cfg.Enabled() {
+		util.Infof("Alerting with %d rules to %d webhooks", len(cfg.Rules), len(cfg.Webhooks))
+	}
+	return nil
+}
+
+func (l *Lifecycle) Reload(s *server.Server) error {
+	cfg, err := parseConfig(s.Options)
+	if err != nil {
+		return err
+	}
+	l.checker.configure(cfg)
+	return nil
+}
+
+func parseConfig(opts *server.ServerOptions) (*Config, error) {
+	cfg := &Config{
+		Interval: time.Duration(opts.Int("alerts", "interval", 30)) * time.Second,
+	}
+	if cfg.Interval < 5*time.Second {
+		return nil, fmt.Errorf("alerts: interval must be at least 5 seconds")
+	}
+
+	hooks, ok := opts.Config("alerts", "webhooks", []any{}).([]any)
+	if !ok {
+		return nil, fmt.Errorf("alerts: webhooks must be an array of URLs")
+	}
+	for _, hook := range hooks {
+		url, ok := hook.(string)
+		if !ok || url == "" {
+			return nil, fmt.Errorf("alerts: invalid webhook %v", hook)
+		}
+		cfg.Webhooks = append(cfg.Webhooks, url)
+	}
+
+	var tables []map[string]any
+	switch rules := opts.Config("alerts", "rules", nil).(type) {
+	case nil:
+	case []map[string]any:
+		tables = rules
+	case []any:
+		for _, rule := range rules {
+			table, ok := rule.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("alerts: invalid rule %v", rule)
+			}
+			tables = append(tables, table)
+		}
+	default:
+		return nil, fmt.Errorf("alerts: rules must be an array of tables, use [[alerts.rules]]")
+	}
+
+	names := map[string]bool{}
+	for _, table := range tables {
+		rule, err := parseRule(table)
+		if err != nil {
+			return nil, err
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("alerts: duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+	return cfg, nil
+}
+
+func parseRule(table map[string]any) (Rule, error) {
+	rule := Rule{}
+	rule.Kind, _ = table["kind"].(string)
+	rule.Queue, _ = table["queue"].(string)
+	rule.Name, _ = table["name"].(string)
+
+	switch rule.Kind {
+	case Latency, Size, NoWorkers:
+		if rule.Queue == "" {
+			return rule, fmt.Errorf("alerts: %s rule requires a queue", rule.Kind)
+		}
+	case DeadGrowth:
+	default:
+		return rule, fmt.Errorf("alerts: unknown rule kind %q", rule.Kind)
+	}
+
+	switch val := table["threshold"].(type) {
+	case nil:
+		if rule.Kind != NoWorkers {
+			return rule, fmt.Errorf("alerts: %s rule requires a threshold", rule.Kind)
+		}
+	case int64:
+		rule.Threshold = float64(val)
+	case float64:
+		rule.Threshold = val
+	default:
+		return rule, fmt.Errorf("alerts: threshold must be a number, got %v", val)
+	}
+
+	if rule.Name == "" {
+		rule.Name = rule.Kind
+		if rule.Queue != "" {
+			rule.Name += ":" + rule.Queue
+		}
+	}
+	return rule, nil
+}
@@ -0,0 +1,151 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	opts := &server.ServerOptions{GlobalConfig: map[string]any{}}
+	cfg, err := parseConfig(opts)
+	assert.NoError(t, err)
+	assert.False(t, cfg.Enabled())
+	assert.Equal(t, 30*time.Second, cfg.Interval)
+
+	opts.GlobalConfig["alerts"] = map[string]any{
+		"webhooks": []any{"http://localhost:9999/hook"},
+		"interval": int64(10),
+		"rules": []map[string]any{
+			{"kind": "latency", "queue": "critical", "threshold": int64(60)},
+			{"name": "dying", "kind": "dead", "threshold": 2.5},
+			{"kind": "no_workers", "queue": "default"},
+		},
+	}
+	cfg, err = parseConfig(opts)
+	assert.NoError(t, err)
+	assert.True(t, cfg.Enabled())
+	assert.Equal(t, 10*time.Second, cfg.Interval)
+	assert.Equal(t, []Rule{
+		{Name: "latency:critical", Kind: Latency, Queue: "critical", Threshold: 60},
+		{Name: "dying", Kind: DeadGrowth, Threshold: 2.5},
+		{Name: "no_workers:default", Kind: NoWorkers, Queue: "default"},
+	}, cfg.Rules)
+
+	invalid := []map[string]any{
+		{"kind": "bogus", "queue": "default", "threshold": int64(1)},
+		{"kind": "size", "threshold": int64(1)},
+		{"kind": "size", "queue": "default"},
+		{"kind": "size", "queue": "default", "threshold": "lots"},
+	}
+	for _, rule := range invalid {
+		opts.GlobalConfig["alerts"] = map[string]any{"rules": []map[string]any{rule}}
+		_, err = parseConfig(opts)
+		assert.Error(t, err, rule)
+	}
+
+	opts.GlobalConfig["alerts"] = map[string]any{"rules": []map[string]any{
+		{"kind": "size", "queue": "default", "threshold": int64(1)},
+		{"kind": "size", "queue": "default", "threshold": int64(2)},
+	}}
+	_, err = parseConfig(opts)
+	assert.ErrorContains(t, err, "duplicate")
+}
+
+func TestChecker(t *testing.T) {
+	var mu sync.Mutex
+	received := []Payload{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		var payload Payload
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	cfg := &Config{
+		Webhooks: []string{ts.URL, ts.URL + "/missing"},
+		Interval: 5 * time.Second,
+		Rules: []Rule{
+			{Name: "slow", Kind: Latency, Queue: "critical", Threshold: 60},
+			{Name: "big", Kind: Size, Queue: "default", Threshold: 100},
+			{Name: "dying", Kind: DeadGrowth, Threshold: 5},
+			{Name: "stuck", Kind: NoWorkers, Queue: "default"},
+		},
+	}
+
+	snap := &Snapshot{
+		Latencies: map[string]float64{"critical": 1},
+		Sizes:     map[string]uint64{"default": 10},
+		Busy:      map[string]int{"default": 2},
+		DeadSize:  100,
+	}
+	queues := []string{}
+	c := newChecker(cfg, func(ctx context.Context, names []string) (*Snapshot, error) {
+		queues = names
+		return snap, nil
+	})
+	c.deliver = c.post
+
+	ctx := context.Background()
+	assert.NoError(t, c.Execute(ctx))
+	assert.Equal(t, []string{"critical", "default"}, queues)
+	assert.Len(t, received, 0)
+
+	// checks run no more often than the interval
+	snap.Latencies["critical"] = 120
+	assert.NoError(t, c.Execute(ctx))
+	assert.Len(t, received, 0)
+
+	now := time.Now()
+	c.evaluate(snap, now)
+	assert.Len(t, received, 1)
+	assert.Equal(t, Firing, received[0].Status)
+	assert.Equal(t, "slow", received[0].Rule)
+	assert.EqualValues(t, 120, received[0].Value)
+	assert.Contains(t, received[0].Message, "critical")
+
+	// still firing, no duplicate alerts
+	c.evaluate(snap, now)
+	assert.Len(t, received, 1)
+
+	snap.Latencies["critical"] = 0
+	snap.Sizes["default"] = 500
+	snap.Busy["default"] = 0
+	snap.DeadSize = 110
+	c.evaluate(snap, now)
+	assert.Len(t, received, 5)
+	statuses := map[string]string{}
+	for _, payload := range received[1:] {
+		statuses[payload.Rule] = payload.Status
+	}
+	assert.Equal(t, map[string]string{
+		"slow":  Resolved,
+		"big":   Firing,
+		"dying": Firing,
+		"stuck": Firing,
+	}, statuses)
+
+	stats := c.Stats(ctx)
+	assert.Equal(t, 3, stats["firing"])
+	assert.EqualValues(t, 5, stats["sent"])
+	assert.EqualValues(t, 5, stats["failed"])
+
+	// dropping a rule on reload forgets its state
+	c.configure(&Config{Webhooks: cfg.Webhooks, Interval: cfg.Interval, Rules: cfg.Rules[:1]})
+	c.evaluate(snap, now)
+	assert.Equal(t, 0, c.Stats(ctx)["firing"])
+}
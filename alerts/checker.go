@@ -0,0 +1,209 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/util"
+)
+
+// Snapshot holds the current values for everything the rules watch.
+type Snapshot struct {
+	Latencies map[string]float64
+	Sizes     map[string]uint64
+	Busy      map[string]int
+	DeadSize  uint64
+}
+
+type gatherer func(ctx context.Context, queues []string) (*Snapshot, error)
+
+func serverGatherer(s *server.Server) gatherer {
+	return func(ctx context.Context, queues []string) (*Snapshot, error) {
+		snap := &Snapshot{
+			Sizes:    map[string]uint64{},
+			Busy:     map[string]int{},
+			DeadSize: s.Store().Dead().Size(ctx),
+		}
+		latencies, err := s.QueueLatencies(ctx, queues)
+		if err != nil {
+			return nil, err
+		}
+		snap.Latencies = latencies
+
+		for _, name := range queues {
+			// don't use GetQueue, it would create any missing queue
+			if q, ok := s.Store().ExistingQueue(ctx, name); ok {
+				snap.Sizes[name] = q.Size(ctx)
+			}
+			snap.Busy[name] = s.Manager().QueueBusyCount(name)
+		}
+		return snap, nil
+	}
+}
+
+type alertState struct {
+	firing bool
+	since  time.Time
+}
+
+// checker is the Taskable which periodically evaluates the rules
+// and notifies the webhooks when a rule changes state, so each
+// incident sends one alert and one recovery.
+type checker struct {
+	gather  gatherer
+	deliver func(cfg *Config, payload *Payload)
+
+	mu        sync.Mutex
+	cfg       *Config
+	lastCheck time.Time
+	lastDead  uint64
+	deadKnown bool
+	states    map[string]*alertState
+
+	sent   int64
+	failed int64
+}
+
+func newChecker(cfg *Config, gather gatherer) *checker {
+	c := &checker{
+		gather: gather,
+		cfg:    cfg,
+		states: map[string]*alertState{},
+	}
+	c.deliver = func(cfg *Config, payload *Payload) {
+		go c.post(cfg, payload)
+	}
+	return c
+}
+
+func (c *checker) configure(cfg *Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+}
+
+func (c *checker) Name() string {
+	return "Alerts"
+}
+
+func (c *checker) Stats(context.Context) map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	firing := 0
+	for _, state := range c.states {
+		if state.firing {
+			firing++
+		}
+	}
+	return map[string]any{
+		"rules":  len(c.cfg.Rules),
+		"firing": firing,
+		"sent":   atomic.LoadInt64(&c.sent),
+		"failed": atomic.LoadInt64(&c.failed),
+	}
+}
+
+func (c *checker) Execute(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !c.cfg.Enabled() || now.Sub(c.lastCheck) < c.cfg.Interval {
+		return nil
+	}
+	c.lastCheck = now
+
+	snap, err := c.gather(ctx, c.queues())
+	if err != nil {
+		return err
+	}
+	c.evaluate(snap, now)
+	return nil
+}
+
+func (c *checker) queues() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, rule := range c.cfg.Rules {
+		if rule.Queue != "" && !seen[rule.Queue] {
+			seen[rule.Queue] = true
+			names = append(names, rule.Queue)
+		}
+	}
+	return names
+}
+
+func (c *checker) evaluate(snap *Snapshot, now time.Time) {
+	growth := uint64(0)
+	if c.deadKnown && snap.DeadSize > c.lastDead {
+		growth = snap.DeadSize - c.lastDead
+	}
+	c.lastDead = snap.DeadSize
+	c.deadKnown = true
+
+	active := map[string]bool{}
+	for _, rule := range c.cfg.Rules {
+		active[rule.Name] = true
+
+		var value float64
+		var firing bool
+		switch rule.Kind {
+		case Latency:
+			value = snap.Latencies[rule.Queue]
+			firing = value > rule.Threshold
+		case Size:
+			value = float64(snap.Sizes[rule.Queue])
+			firing = value > rule.Threshold
+		case DeadGrowth:
+			value = float64(growth)
+			firing = value > rule.Threshold
+		case NoWorkers:
+			value = float64(snap.Sizes[rule.Queue])
+			firing = value > rule.Threshold && snap.Busy[rule.Queue] == 0
+		}
+
+		state, ok := c.states[rule.Name]
+		if !ok {
+			state = &alertState{}
+			c.states[rule.Name] = state
+		}
+		if state.firing == firing {
+			continue
+		}
+
+		state.firing = firing
+		if firing {
+			state.since = now
+			util.Warnf("Alert %q firing: %s", rule.Name, rule.describe(value))
+		} else {
+			util.Infof("Alert %q resolved", rule.Name)
+		}
+		c.deliver(c.cfg, newPayload(rule, firing, value, state.since, now))
+	}
+
+	// forget rules which were removed by a config reload
+	for name := range c.states {
+		if !active[name] {
+			delete(c.states, name)
+		}
+	}
+}
+
+func (r Rule) describe(value float64) string {
+	switch r.Kind {
+	case Latency:
+		return fmt.Sprintf("queue %s latency is %.1f seconds, threshold %g", r.Queue, value, r.Threshold)
+	case Size:
+		return fmt.Sprintf("queue %s holds %.0f jobs, threshold %g", r.Queue, value, r.Threshold)
+	case DeadGrowth:
+		return fmt.Sprintf("%.0f jobs died since the last check, threshold %g", value, r.Threshold)
+	case NoWorkers:
+		return fmt.Sprintf("queue %s holds %.0f jobs but none are being processed", r.Queue, value)
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/util"
+)
+
+const (
+	Firing   = "firing"
+	Resolved = "resolved"
+)
+
+var (
+	WebhookTimeout = 10 * time.Second
+)
+
+// Payload is the JSON document POSTed to each webhook.
+type Payload struct {
+	Status    string  `json:"status"`
+	Rule      string  `json:"rule"`
+	Kind      string  `json:"kind"`
+	Queue     string  `json:"queue,omitempty"`
+	Threshold float64 `json:"threshold"`
+	Value     float64 `json:"value"`
+	Message   string  `json:"message"`
+	Since     string  `json:"since"`
+	At        string  `json:"at"`
+}
+
+func newPayload(rule Rule, firing bool, value float64, since time.Time, now time.Time) *Payload {
+	payload := &Payload{
+		Status:    Firing,
+		Rule:      rule.Name,
+		Kind:      rule.Kind,
+		Queue:     rule.Queue,
+		Threshold: rule.Threshold,
+		Value:     value,
+		Message:   rule.describe(value),
+		Since:     util.Thens(since),
+		At:        util.Thens(now),
+	}
+	if !firing {
+		payload.Status = Resolved
+		payload.Message = fmt.Sprintf("%s has recovered: %s", rule.Name, payload.Message)
+	}
+	return payload
+}
+
+func (c *checker) post(cfg *Config, payload *Payload) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		util.Error("Unable to marshal alert", err)
+		return
+	}
+
+	for _, url := range cfg.Webhooks {
+		err := postWebhook(url, data)
+		if err != nil {
+			atomic.AddInt64(&c.failed, 1)
+			util.Warnf("Unable to deliver alert %q to webhook: %v", payload.Rule, err)
+			continue
+		}
+		atomic.AddInt64(&c.sent, 1)
+	}
+}
+
+func postWebhook(url string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), WebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", client.Name+"/"+client.Version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with %s", url, resp.Status)
+	}
+	return nil
+}
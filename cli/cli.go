@@ -3,13 +3,13 @@ package cli
 import (
 	"flag"
 	"fmt"
-	"log"
 	"os"
 	"os/signal"
 	"os/user"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/contribsys/faktory/client"
@@ -25,6 +25,8 @@ type CliOptions struct {
 	ConfigDirectory  string
 	LogLevel         string
 	StorageDirectory string
+	LogFormat        string
+	LogFile          string
 }
 
 func ParseArguments() CliOptions {
@@ -34,12 +36,14 @@ func ParseArguments() CliOptions {
 		fenv = "development"
 	}
 
-	defaults := CliOptions{"localhost:7419", "localhost:7420", fenv, "/etc/faktory", "info", "/var/lib/faktory/db"}
+	defaults := CliOptions{"localhost:7419", "localhost:7420", fenv, "/etc/faktory", "info", "/var/lib/faktory/db", "", ""}
 
 	flag.Usage = help
 	flag.StringVar(&defaults.WebBinding, "w", "localhost:7420", "WebUI binding")
 	flag.StringVar(&defaults.CmdBinding, "b", "localhost:7419", "Network binding")
 	flag.StringVar(&defaults.LogLevel, "l", "info", "Logging level (error, warn, info, debug)")
+	flag.StringVar(&defaults.LogFormat, "log-format", "", "Logging format (text, json)")
+	flag.StringVar(&defaults.LogFile, "log-file", "", "Log to this file rather than stdout")
 	flag.StringVar(&defaults.Environment, "e", fenv, "Environment (development, staging, production)")
 
 	// undocumented on purpose, we don't want people changing these if possible
@@ -49,6 +53,9 @@ func ParseArguments() CliOptions {
 	flag.Parse()
 
 	if *versionPtr {
+		fmt.Println(client.Name, client.Version)
+		fmt.Println(copyright())
+		fmt.Println("Licensed under the " + license)
 		os.Exit(0)
 	}
 
@@ -75,19 +82,35 @@ func ParseArguments() CliOptions {
 		// nothing
 	default:
 		help()
-		log.Println("")
-		log.Fatalf(`Invalid environment "%s": legal values are development, staging or production`, defaults.Environment)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintf(os.Stderr, "Invalid environment %q: legal values are development, staging or production\n", defaults.Environment)
+		os.Exit(1)
 	}
 	return defaults
 }
 
 func help() {
-	log.Println("-b [binding]\tNetwork binding (use :7419 to listen on all interfaces), default: localhost:7419")
-	log.Println("-w [binding]\tWeb UI binding (use :7420 to listen on all interfaces), default: localhost:7420")
-	log.Println("-e [env]\tSet environment (development, staging, production), default: development")
-	log.Println("-l [level]\tSet logging level (error, warn, info, debug), default: info")
-	log.Println("-v\t\tShow version and license information")
-	log.Println("-h\t\tThis help screen")
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "-b [binding]\tNetwork binding (use :7419 to listen on all interfaces), default: localhost:7419")
+	fmt.Fprintln(out, "-w [binding]\tWeb UI binding (use :7420 to listen on all interfaces), default: localhost:7420")
+	fmt.Fprintln(out, "-e [env]\tSet environment (development, staging, production), default: development")
+	fmt.Fprintln(out, "-l [level]\tSet logging level (error, warn, info, debug), default: info")
+	fmt.Fprintln(out, "-log-format [format]\tSet logging format (text, json), default: text")
+	fmt.Fprintln(out, "-log-file [path]\tLog to the given file, rotated according to the [log] config, default: stdout")
+	fmt.Fprintln(out, "-v\t\tShow version and license information")
+	fmt.Fprintln(out, "-h\t\tThis help screen")
+}
+
+const license = "GNU Affero Public License 3.0"
+
+func copyright() string {
+	return fmt.Sprintf("Copyright © %d Contributed Systems LLC", time.Now().Year())
+}
+
+// logPreamble starts the log, in its configured format and file, with
+// the server's version and license.
+func logPreamble() {
+	util.Infow(client.Name+" "+client.Version, util.Fields{"copyright": copyright(), "license": license})
 }
 
 var (
@@ -133,7 +156,7 @@ func reload(s *server.Server) {
 func exit(s *server.Server) {
 	util.Infof("%s shutting down", client.Name)
 
-	close(s.Stopper())
+	s.Shutdown()
 }
 
 func threadDump(s *server.Server) {
@@ -146,15 +169,28 @@ func BuildServer(opts *CliOptions) (*server.Server, func() error, error) {
 		return nil, nil, err
 	}
 
+	err = configureLogger(opts, globalConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	logPreamble()
+
 	pwd, err := fetchPassword(globalConfig, opts.Environment)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	// REDIS_URL points to an external Redis, e.g. one shared by two
+	// Faktory servers running highly available.  Otherwise Faktory runs
+	// its own Redis.
+	redisURL := os.Getenv("REDIS_URL")
 	sock := fmt.Sprintf("%s/redis.sock", opts.StorageDirectory)
-	stopper, err := storage.Boot(opts.StorageDirectory, sock)
-	if err != nil {
-		return nil, stopper, err
+	stopper := func() error { return nil }
+	if redisURL == "" {
+		stopper, err = storage.Boot(opts.StorageDirectory, sock)
+		if err != nil {
+			return nil, stopper, err
+		}
 	}
 
 	// allow binding config element if no CLI arg spec'd:
@@ -170,6 +206,7 @@ func BuildServer(opts *CliOptions) (*server.Server, func() error, error) {
 		ConfigDirectory:  opts.ConfigDirectory,
 		Environment:      opts.Environment,
 		RedisSock:        sock,
+		RedisURL:         redisURL,
 		GlobalConfig:     globalConfig,
 		Password:         pwd,
 		PoolSize:         server.DefaultMaxPoolSize,
@@ -187,6 +224,42 @@ func BuildServer(opts *CliOptions) (*server.Server, func() error, error) {
 	return s, stopper, nil
 }
 
+// CLI arguments override the config file:
+//
+//	[log]
+//	format = "json"
+//	file = "/var/log/faktory/faktory.log"
+//	max_size = 100  # MB
+//	max_backups = 5
+func configureLogger(opts *CliOptions, cfg map[string]any) error {
+	lopts := util.LogOptions{
+		Format:     stringConfig(cfg, "log", "format", "text"),
+		File:       stringConfig(cfg, "log", "file", ""),
+		MaxSize:    int64(intConfig(cfg, "log", "max_size", 100)) * 1024 * 1024,
+		MaxBackups: intConfig(cfg, "log", "max_backups", 5),
+	}
+	if opts.LogFormat != "" {
+		lopts.Format = opts.LogFormat
+	}
+	if opts.LogFile != "" {
+		lopts.File = opts.LogFile
+	}
+	return util.ConfigureLogger(lopts)
+}
+
+func intConfig(cfg map[string]any, subsys string, elm string, defval int) int {
+	if mapp, ok := cfg[subsys]; ok {
+		if mappp, ok := mapp.(map[string]any); ok {
+			if val, ok := mappp[elm]; ok {
+				if ival, ok := val.(int64); ok {
+					return int(ival)
+				}
+			}
+		}
+	}
+	return defval
+}
+
 func stringConfig(cfg map[string]any, subsys string, elm string, defval string) string {
 	if mapp, ok := cfg[subsys]; ok {
 		if mappp, ok := mapp.(map[string]any); ok {
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrPusherClosed = errors.New("pusher is closed")
+
+// PushResult is the outcome of an asynchronous push.  Err is nil if
+// Faktory accepted the job.
+type PushResult struct {
+	Job *Job
+	Err error
+}
+
+// An AsyncPusher pipelines PUSH commands over one connection: jobs are
+// written back-to-back without waiting for each response, so throughput
+// isn't limited by the round trip to the server.  Responses are read in
+// order and each job's result is passed to the callback.
+//
+//	ap := client.NewAsyncPusher(cl, 100, func(res client.PushResult) {
+//		if res.Err != nil {
+//			log.Printf("push of %s failed: %v", res.Job.Jid, res.Err)
+//		}
+//	})
+//	for _, job := range jobs {
+//		err := ap.Push(job)
+//	}
+//	err := ap.Close()
+//
+// The pusher owns the client's connection until it is closed and does
+// not close the client.
+type AsyncPusher struct {
+	client   *Client
+	callback func(PushResult)
+
+	// bounds the jobs written or waiting to be written but not yet
+	// acknowledged
+	inflight chan struct{}
+	jobs     chan *Job
+	pending  chan pushed
+	done     chan struct{}
+
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	outstanding int
+	broken      error
+	failed      int
+	firstErr    error
+}
+
+type pushed struct {
+	job *Job
+	err error
+}
+
+// NewAsyncPusher starts pipelining pushes over the client's connection
+// with up to depth pushes in flight.  The callback, which may be nil,
+// is called from a background goroutine for each job in push order
+// and should not block.  To receive results on a channel, send them
+// from the callback.
+func NewAsyncPusher(c *Client, depth int, callback func(PushResult)) *AsyncPusher {
+	if depth < 1 {
+		depth = 1
+	}
+	ap := &AsyncPusher{
+		client:   c,
+		callback: callback,
+		inflight: make(chan struct{}, depth),
+		jobs:     make(chan *Job, depth),
+		pending:  make(chan pushed, depth),
+		done:     make(chan struct{}),
+	}
+	ap.cond = sync.NewCond(&ap.mu)
+	go ap.write()
+	go ap.read()
+	return ap
+}
+
+// Push queues the job, blocking while depth pushes are in flight.  It
+// fails if the pusher is closed or its connection has broken, otherwise
+// the job's result is passed to the callback.
+func (ap *AsyncPusher) Push(job *Job) error {
+	ap.closeMu.RLock()
+	defer ap.closeMu.RUnlock()
+	if ap.closed {
+		return ErrPusherClosed
+	}
+
+	ap.mu.Lock()
+	if ap.broken != nil {
+		ap.mu.Unlock()
+		return ap.broken
+	}
+	ap.outstanding++
+	ap.mu.Unlock()
+
+	ap.inflight <- struct{}{}
+	ap.jobs <- job
+	return nil
+}
+
+// Flush waits for the results of all queued pushes.  It returns an
+// error if any push failed since the last Flush.
+func (ap *AsyncPusher) Flush() error {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	for ap.outstanding > 0 {
+		ap.cond.Wait()
+	}
+
+	var err error
+	if ap.failed == 1 {
+		err = ap.firstErr
+	} else if ap.failed > 1 {
+		err = fmt.Errorf("%d pushes failed, the first with: %w", ap.failed, ap.firstErr)
+	}
+	ap.failed = 0
+	ap.firstErr = nil
+	return err
+}
+
+// Close flushes the queued pushes and stops the pusher.
+func (ap *AsyncPusher) Close() error {
+	ap.closeMu.Lock()
+	if ap.closed {
+		ap.closeMu.Unlock()
+		return nil
+	}
+	ap.closed = true
+	close(ap.jobs)
+	ap.closeMu.Unlock()
+
+	<-ap.done
+	return ap.Flush()
+}
+
+func (ap *AsyncPusher) isBroken() error {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	return ap.broken
+}
+
+// breaks fails this and every later push since the connection is no
+// longer in a known state.
+func (ap *AsyncPusher) breaks(err error) {
+	ap.client.markUnusable()
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if ap.broken == nil {
+		ap.broken = err
+	}
+}
+
+// write buffers each job's PUSH, flushing whenever it runs out of
+// queued jobs so bursts go out in as few writes as possible.
+func (ap *AsyncPusher) write() {
+	defer close(ap.pending)
+	c := ap.client
+	for job := range ap.jobs {
+		err := ap.isBroken()
+		if err == nil {
+			var data []byte
+			data, err = json.Marshal(job)
+			if err == nil {
+				c.setDeadline(c.conn.SetWriteDeadline, 5*time.Second)
+				err = bufferLine(c.wtr, "PUSH", data)
+				if err == nil && len(ap.jobs) == 0 {
+					err = c.wtr.Flush()
+				}
+				if err != nil {
+					ap.breaks(err)
+				}
+			}
+		}
+		ap.pending <- pushed{job: job, err: err}
+	}
+}
+
+// read waits for the response to each PUSH in the order they were
+// written.
+func (ap *AsyncPusher) read() {
+	defer close(ap.done)
+	c := ap.client
+	for p := range ap.pending {
+		err := p.err
+		if err == nil {
+			err = ap.isBroken()
+		}
+		if err == nil {
+			c.setDeadline(c.conn.SetReadDeadline, 5*time.Second)
+			err = ok(c.rdr)
+			if _, isProtocol := err.(*ProtocolError); err != nil && !isProtocol {
+				ap.breaks(err)
+			}
+		}
+		ap.finish(p.job, err)
+		<-ap.inflight
+	}
+}
+
+func (ap *AsyncPusher) finish(job *Job, err error) {
+	if ap.callback != nil {
+		ap.callback(PushResult{Job: job, Err: err})
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if err != nil {
+		ap.failed++
+		if ap.firstErr == nil {
+			ap.firstErr = err
+		}
+	}
+	ap.outstanding--
+	ap.cond.Broadcast()
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncPusher(t *testing.T) {
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		if strings.Contains(line, `"jobtype":"Rejected"`) {
+			return "-ERR rejected\r\n", false
+		}
+		return "+OK\r\n", false
+	})
+	cl, err := s.Open()
+	assert.NoError(t, err)
+	defer cl.Close()
+
+	var mu sync.Mutex
+	results := []PushResult{}
+	ap := NewAsyncPusher(cl, 4, func(res PushResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, res)
+	})
+
+	jobs := []*Job{}
+	for i := range 10 {
+		job := NewJob("Event", i)
+		if i == 3 || i == 7 {
+			job.Type = "Rejected"
+		}
+		jobs = append(jobs, job)
+		assert.NoError(t, ap.Push(job))
+	}
+	err = ap.Flush()
+	assert.ErrorContains(t, err, "2 pushes failed")
+	assert.ErrorContains(t, err, "rejected")
+
+	mu.Lock()
+	assert.Len(t, results, 10)
+	for idx, res := range results {
+		assert.Equal(t, jobs[idx], res.Job)
+		if idx == 3 || idx == 7 {
+			assert.Error(t, res.Err)
+		} else {
+			assert.NoError(t, res.Err)
+		}
+	}
+	mu.Unlock()
+
+	// the connection is still usable after an error response
+	assert.NoError(t, ap.Push(NewJob("Event")))
+	assert.NoError(t, ap.Close())
+	assert.Len(t, results, 11)
+	assert.ErrorIs(t, ap.Push(NewJob("Event")), ErrPusherClosed)
+	assert.NoError(t, ap.Close())
+
+	assert.NoError(t, cl.Push(NewJob("Sync")))
+}
+
+func TestAsyncPusherBrokenConnection(t *testing.T) {
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		if strings.HasPrefix(line, "PUSH") {
+			return "", true
+		}
+		return "+OK\r\n", false
+	})
+	cl, err := s.Open()
+	assert.NoError(t, err)
+	defer cl.Close()
+
+	failed := 0
+	ap := NewAsyncPusher(cl, 2, func(res PushResult) {
+		if res.Err != nil {
+			failed++
+		}
+	})
+	assert.NoError(t, ap.Push(NewJob("Event")))
+	assert.Error(t, ap.Flush())
+	assert.Error(t, ap.Push(NewJob("Event")))
+	assert.NoError(t, ap.Close())
+	assert.Equal(t, 1, failed)
+}
@@ -2,9 +2,11 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"crypto/sha256"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -12,6 +14,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/contribsys/faktory/internal/pool"
@@ -29,6 +32,9 @@ var (
 	// e.g. see how faktory_worker_go sets this.
 	RandomProcessWid = ""
 	Labels           = []string{"golang"}
+
+	// How long MoveQueue waits for the server to move the jobs
+	MoveTimeout = 5 * time.Minute
 )
 
 // Dialer is the interface for creating a specialized net.Conn.
@@ -47,6 +53,14 @@ type Client struct {
 	wtr      *bufio.Writer
 	poolConn *pool.PoolConn
 	Location string
+
+	// the context of the operation in progress, guarded by mu
+	mu  sync.Mutex
+	ctx context.Context
+
+	// used by Pool to check the connection
+	created  time.Time
+	lastUsed time.Time
 }
 
 // ClientData is serialized to JSON and sent
@@ -66,6 +80,10 @@ type ClientData struct {
 	// it is ignored by Faktory.
 	Username string `json:"username"`
 
+	// Namespace selects the server's namespace holding this client's
+	// jobs.  The password must be the namespace's password.
+	Namespace string `json:"namespace,omitempty"`
+
 	// Hash is hex(sha256(password + nonce))
 	PasswordHash string `json:"pwdhash"`
 
@@ -82,10 +100,15 @@ type ClientData struct {
 type Server struct {
 	TLS      *tls.Config
 	Network  string
-	Address  string
+	Address  string // or a comma-separated list of addresses tried in order
 	Username string
 	Password string //gosec:disable
-	Timeout  time.Duration
+	// Namespace is the server namespace to use, if any.
+	Namespace string
+	Timeout   time.Duration
+	// Wid identifies a worker process's connections, RandomProcessWid
+	// if empty.
+	Wid string
 }
 
 // OpenWithDialer creates a *Client with the dialer.
@@ -114,11 +137,12 @@ FOO_URL=tcp://:mypassword@faktory.example.com:7419`)
 				return err
 			}
 			s.Network = uri.Scheme
-			s.Address = fmt.Sprintf("%s:%s", uri.Hostname(), uri.Port())
+			s.Address = address(uri)
 			if uri.User != nil {
 				s.Username = uri.User.Username()
 				s.Password, _ = uri.User.Password()
 			}
+			s.Namespace = uri.Query().Get("namespace")
 			return nil
 		}
 		return fmt.Errorf("FAKTORY_PROVIDER set to invalid value: %s", val)
@@ -132,17 +156,40 @@ FOO_URL=tcp://:mypassword@faktory.example.com:7419`)
 		}
 
 		s.Network = uri.Scheme
-		s.Address = fmt.Sprintf("%s:%s", uri.Hostname(), uri.Port())
+		s.Address = address(uri)
 		if uri.User != nil {
 			s.Username = uri.User.Username()
 			s.Password, _ = uri.User.Password()
 		}
+		s.Namespace = uri.Query().Get("namespace")
 		return nil
 	}
 
 	return nil
 }
 
+// address returns the URL's host and port or, for a highly available
+// pair of servers, its comma-separated list of addresses.  Hosts listed
+// without a port use the last host's port, or 7419, so
+// tcp://faktory1,faktory2:7419 works like the URL of a single server.
+func address(uri *url.URL) string {
+	if !strings.Contains(uri.Host, ",") {
+		return fmt.Sprintf("%s:%s", uri.Hostname(), uri.Port())
+	}
+
+	hosts := strings.Split(uri.Host, ",")
+	port := "7419"
+	if _, p, err := net.SplitHostPort(hosts[len(hosts)-1]); err == nil && p != "" {
+		port = p
+	}
+	for idx, host := range hosts {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			hosts[idx] = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+	}
+	return strings.Join(hosts, ",")
+}
+
 func DefaultServer() *Server {
 	return &Server{
 		Network:  "tcp",
@@ -159,9 +206,15 @@ func DefaultServer() *Server {
 // • Use FAKTORY_PROVIDER to point to a custom URL variable.
 // • Use FAKTORY_URL as a catch-all default.
 //
-// Use the URL to configure any necessary password:
+// Use the URL to configure any necessary password and namespace:
 //
 //	tcp://:mypassword@localhost:7419
+//	tcp://:mypassword@localhost:7419?namespace=billing
+//
+// List both servers of a highly available pair to connect to whichever
+// is active:
+//
+//	tcp://:mypassword@faktory1:7419,faktory2:7419
 //
 // By default Open assumes localhost with no password
 // which is appropriate for local development.
@@ -216,11 +269,22 @@ type HIv2 struct {
 func dial(srv *Server, password string, dialer Dialer) (*Client, error) {
 	client := emptyClientData()
 	client.Username = srv.Username
+	client.Namespace = srv.Namespace
+	if srv.Wid != "" {
+		client.Wid = srv.Wid
+	}
 
 	var err error
 	var conn net.Conn
 
-	conn, err = dialer.Dial("tcp", srv.Address)
+	// a standby server doesn't listen so try each address in turn
+	addr := srv.Address
+	for _, addr = range strings.Split(srv.Address, ",") {
+		conn, err = dialer.Dial("tcp", addr)
+		if err == nil {
+			break
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -277,7 +341,7 @@ func dial(srv *Server, password string, dialer Dialer) (*Client, error) {
 		return nil, err
 	}
 
-	return &Client{Options: client, Location: srv.Address, conn: conn, rdr: r, wtr: w}, nil
+	return &Client{Options: client, Location: addr, conn: conn, rdr: r, wtr: w, created: time.Now()}, nil
 }
 
 func (c *Client) Close() error {
@@ -286,25 +350,41 @@ func (c *Client) Close() error {
 }
 
 func (c *Client) Ack(jid string) error {
-	err := c.writeLine(c.wtr, "ACK", fmt.Appendf(nil, `{"jid":%q}`, jid))
-	if err != nil {
-		return err
-	}
+	return c.AckContext(context.Background(), jid)
+}
 
-	return c.ok(c.rdr)
+// AckContext is Ack which gives up when ctx is done.
+func (c *Client) AckContext(ctx context.Context, jid string) error {
+	return c.run(ctx, func() error {
+		err := c.writeLine(c.wtr, "ACK", fmt.Appendf(nil, `{"jid":%q}`, jid))
+		if err != nil {
+			return err
+		}
+
+		return c.ok(c.rdr)
+	})
 }
 
 // Result is map[JID]ErrorMessage
 func (c *Client) PushBulk(jobs []*Job) (map[string]string, error) {
+	return c.PushBulkContext(context.Background(), jobs)
+}
+
+// PushBulkContext is PushBulk which gives up when ctx is done.
+func (c *Client) PushBulkContext(ctx context.Context, jobs []*Job) (map[string]string, error) {
 	jobBytes, err := json.Marshal(jobs)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeLine(c.wtr, "PUSHB", jobBytes)
-	if err != nil {
-		return nil, err
-	}
-	data, err := c.readResponse(c.rdr)
+	var data []byte
+	err = c.run(ctx, func() error {
+		err := c.writeLine(c.wtr, "PUSHB", jobBytes)
+		if err != nil {
+			return err
+		}
+		data, err = c.readResponse(c.rdr)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -318,28 +398,46 @@ func (c *Client) PushBulk(jobs []*Job) (map[string]string, error) {
 }
 
 func (c *Client) Push(job *Job) error {
+	return c.PushContext(context.Background(), job)
+}
+
+// PushContext is Push which gives up when ctx is done.  A push which
+// is interrupted may or may not have reached the server.
+func (c *Client) PushContext(ctx context.Context, job *Job) error {
 	jobBytes, err := json.Marshal(job)
 	if err != nil {
 		return err
 	}
-	err = c.writeLine(c.wtr, "PUSH", jobBytes)
-	if err != nil {
-		return err
-	}
-	return c.ok(c.rdr)
+	return c.run(ctx, func() error {
+		err := c.writeLine(c.wtr, "PUSH", jobBytes)
+		if err != nil {
+			return err
+		}
+		return c.ok(c.rdr)
+	})
 }
 
 func (c *Client) Fetch(q ...string) (*Job, error) {
+	return c.FetchContext(context.Background(), q...)
+}
+
+// FetchContext is Fetch which gives up when ctx is done, e.g. so
+// a worker can stop waiting for work when it shuts down.
+func (c *Client) FetchContext(ctx context.Context, q ...string) (*Job, error) {
 	if len(q) == 0 {
 		return nil, fmt.Errorf("Fetch must be called with one or more queue names")
 	}
 
-	err := c.writeLine(c.wtr, "FETCH", []byte(strings.Join(q, " ")))
-	if err != nil {
-		return nil, err
-	}
+	var data []byte
+	err := c.run(ctx, func() error {
+		err := c.writeLine(c.wtr, "FETCH", []byte(strings.Join(q, " ")))
+		if err != nil {
+			return err
+		}
 
-	data, err := c.readResponse(c.rdr)
+		data, err = c.readResponse(c.rdr)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -366,6 +464,11 @@ func (c *Client) Fetch(q ...string) (*Job, error) {
 // If backtrace is non-nil, it is assumed to be the output from
 // runtime/debug.Stack().
 func (c *Client) Fail(jid string, err error, backtrace []byte) error {
+	return c.FailContext(context.Background(), jid, err, backtrace)
+}
+
+// FailContext is Fail which gives up when ctx is done.
+func (c *Client) FailContext(ctx context.Context, jid string, err error, backtrace []byte) error {
 	failure := map[string]any{
 		"message": err.Error(),
 		"errtype": "unknown",
@@ -381,11 +484,13 @@ func (c *Client) Fail(jid string, err error, backtrace []byte) error {
 	if err != nil {
 		return err
 	}
-	err = c.writeLine(c.wtr, "FAIL", failbytes)
-	if err != nil {
-		return err
-	}
-	return c.ok(c.rdr)
+	return c.run(ctx, func() error {
+		err := c.writeLine(c.wtr, "FAIL", failbytes)
+		if err != nil {
+			return err
+		}
+		return c.ok(c.rdr)
+	})
 }
 
 func (c *Client) Flush() error {
@@ -427,17 +532,60 @@ func (c *Client) ResumeQueues(names ...string) error {
 	return c.ok(c.rdr)
 }
 
+// MoveQueue moves the jobs in the src queue to the dst queue and returns
+// the number of jobs moved.  Pass a filter to only move matching jobs or
+// nil to move every job.  Moving a large queue can take a while so this
+// waits up to MoveTimeout for a response.
+func (c *Client) MoveQueue(src string, dst string, filter *JobFilter) (uint64, error) {
+	return c.MoveQueueContext(context.Background(), src, dst, filter)
+}
+
+// MoveQueueContext is MoveQueue which gives up when ctx is done.  The
+// jobs moved before then stay moved.
+func (c *Client) MoveQueueContext(ctx context.Context, src string, dst string, filter *JobFilter) (uint64, error) {
+	payload := []byte(src + " " + dst)
+	if filter != nil {
+		data, err := json.Marshal(filter)
+		if err != nil {
+			return 0, err
+		}
+		payload = append(payload, ' ')
+		payload = append(payload, data...)
+	}
+
+	var val []byte
+	err := c.run(ctx, func() error {
+		err := c.writeLine(c.wtr, "QUEUE MOVE", payload)
+		if err != nil {
+			return err
+		}
+
+		c.setDeadline(c.conn.SetReadDeadline, MoveTimeout)
+		val, err = readResponse(c.rdr)
+		if err != nil {
+			if _, ok := err.(*ProtocolError); !ok {
+				c.markUnusable()
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(val), 10, 64)
+}
+
 // deprecated, this returns an untyped map.
 // use CurrentState() instead which provides strong typing
 func (c *Client) Info() (map[string]any, error) {
-	util.Info("client.Info() is deprecated, use client.CurrentState() instead")
+	return c.InfoContext(context.Background())
+}
 
-	err := c.writeLine(c.wtr, "INFO", nil)
-	if err != nil {
-		return nil, err
-	}
+// deprecated, use CurrentStateContext() instead.
+func (c *Client) InfoContext(ctx context.Context) (map[string]any, error) {
+	util.Info("client.Info() is deprecated, use client.CurrentState() instead")
 
-	data, err := c.readResponse(c.rdr)
+	data, err := c.info(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -455,12 +603,12 @@ func (c *Client) Info() (map[string]any, error) {
 }
 
 func (c *Client) CurrentState() (*FaktoryState, error) {
-	err := c.writeLine(c.wtr, "INFO", nil)
-	if err != nil {
-		return nil, err
-	}
+	return c.CurrentStateContext(context.Background())
+}
 
-	data, err := c.readResponse(c.rdr)
+// CurrentStateContext is CurrentState which gives up when ctx is done.
+func (c *Client) CurrentStateContext(ctx context.Context) (*FaktoryState, error) {
+	data, err := c.info(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -476,6 +624,20 @@ func (c *Client) CurrentState() (*FaktoryState, error) {
 	return &cur, nil
 }
 
+func (c *Client) info(ctx context.Context) ([]byte, error) {
+	var data []byte
+	err := c.run(ctx, func() error {
+		err := c.writeLine(c.wtr, "INFO", nil)
+		if err != nil {
+			return err
+		}
+
+		data, err = c.readResponse(c.rdr)
+		return err
+	})
+	return data, err
+}
+
 func (c *Client) QueueSizes() (map[string]uint64, error) {
 	state, err := c.CurrentState()
 	if err != nil {
@@ -503,12 +665,17 @@ func (c *Client) Generic(cmdline string) (string, error) {
  * Terminate means the process should exit within X seconds, usually ~30 seconds.
  */
 func (c *Client) Beat(args ...string) (string, error) {
+	return c.BeatContext(context.Background(), args...)
+}
+
+// BeatContext is Beat which gives up when ctx is done.
+func (c *Client) BeatContext(ctx context.Context, args ...string) (string, error) {
 	state := ""
 	if len(args) > 0 {
 		state = args[0]
 	}
 	hash := map[string]any{}
-	hash["wid"] = RandomProcessWid
+	hash["wid"] = c.Options.Wid
 	hash["rss_kb"] = RssKb()
 
 	if state != "" {
@@ -519,15 +686,83 @@ func (c *Client) Beat(args ...string) (string, error) {
 		return "", err
 	}
 	cmd := fmt.Sprintf("BEAT %s", data)
-	val, err := c.Generic(cmd)
+	var val string
+	err = c.run(ctx, func() error {
+		val, err = c.Generic(cmd)
+		return err
+	})
 	if val == "OK" {
 		return "", nil
 	}
 	return val, err
 }
 
+// run calls fn, which performs a single command, within ctx.  The
+// context's deadline applies to the connection and cancelling ctx
+// interrupts any blocking network I/O.  An interrupted command leaves
+// the connection in an unknown state so the client is marked unusable
+// and a pooled client will be discarded; other clients should be closed.
+func (c *Client) run(ctx context.Context, fn func() error) error {
+	if ctx.Done() == nil {
+		return fn()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.ctx = ctx
+	c.mu.Unlock()
+
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(interrupted)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_ = c.conn.SetDeadline(time.Unix(1, 0))
+	})
+	err := fn()
+	if !stop() {
+		<-interrupted
+	}
+
+	c.mu.Lock()
+	c.ctx = nil
+	c.mu.Unlock()
+
+	if err != nil {
+		if ctx.Err() != nil {
+			c.markUnusable()
+			return ctx.Err()
+		}
+		// the connection's deadline can pass just before the context's
+		if d, ok := ctx.Deadline(); ok && !time.Now().Before(d) {
+			c.markUnusable()
+			return context.DeadlineExceeded
+		}
+	}
+	return err
+}
+
+// setDeadline sets the connection's read or write deadline to timeout
+// from now, or the deadline of the current operation's context if that
+// is sooner.
+func (c *Client) setDeadline(set func(time.Time) error, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deadline := time.Now().Add(timeout)
+	if c.ctx != nil {
+		if c.ctx.Err() != nil {
+			deadline = time.Unix(1, 0)
+		} else if d, ok := c.ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+	}
+	_ = set(deadline)
+}
+
 func (c *Client) writeLine(wtr *bufio.Writer, op string, payload []byte) error {
-	_ = c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	c.setDeadline(c.conn.SetWriteDeadline, 5*time.Second)
 	err := writeLine(wtr, op, payload)
 	if err != nil {
 		c.markUnusable()
@@ -536,7 +771,7 @@ func (c *Client) writeLine(wtr *bufio.Writer, op string, payload []byte) error {
 }
 
 func (c *Client) readResponse(rdr *bufio.Reader) ([]byte, error) {
-	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	c.setDeadline(c.conn.SetReadDeadline, 5*time.Second)
 	data, err := readResponse(rdr)
 	if err != nil {
 		if _, ok := err.(*ProtocolError); !ok {
@@ -547,7 +782,7 @@ func (c *Client) readResponse(rdr *bufio.Reader) ([]byte, error) {
 }
 
 func (c *Client) ok(rdr *bufio.Reader) error {
-	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	c.setDeadline(c.conn.SetReadDeadline, 5*time.Second)
 	err := ok(rdr)
 	if err != nil {
 		if _, ok := err.(*ProtocolError); !ok {
@@ -558,7 +793,7 @@ func (c *Client) ok(rdr *bufio.Reader) error {
 }
 
 func (c *Client) readString(rdr *bufio.Reader) (string, error) {
-	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	c.setDeadline(c.conn.SetReadDeadline, 5*time.Second)
 	s, err := readString(rdr)
 	if err != nil {
 		if _, ok := err.(*ProtocolError); !ok {
@@ -568,6 +803,19 @@ func (c *Client) readString(rdr *bufio.Reader) (string, error) {
 	return s, err
 }
 
+// alive checks that the server hasn't closed the connection, e.g. because
+// it restarted, without a round trip.  An idle connection should have
+// nothing to read; EOF or unexpected data means it can't be used.
+func (c *Client) alive() bool {
+	if c.rdr.Buffered() > 0 {
+		return false
+	}
+	_ = c.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	_, err := c.rdr.Peek(1)
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
 func (c *Client) markUnusable() {
 	if c.poolConn == nil {
 		// if this client was not created as part of a pool,
@@ -594,6 +842,15 @@ func emptyClientData() *ClientData {
 }
 
 func writeLine(wtr *bufio.Writer, op string, payload []byte) error {
+	err := bufferLine(wtr, op, payload)
+	if err == nil {
+		err = wtr.Flush()
+	}
+	return err
+}
+
+// bufferLine writes the command to wtr without flushing it.
+func bufferLine(wtr *bufio.Writer, op string, payload []byte) error {
 	// util.Debugf("> %s %s", op, string(payload))
 
 	_, err := wtr.WriteString(op)
@@ -608,9 +865,6 @@ func writeLine(wtr *bufio.Writer, op string, payload []byte) error {
 	if err == nil {
 		_, err = wtr.WriteString("\r\n")
 	}
-	if err == nil {
-		err = wtr.Flush()
-	}
 	return err
 }
 
@@ -2,8 +2,10 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"log"
 	"net"
+	"net/url"
 	"os"
 	"os/signal"
 	"runtime"
@@ -136,6 +138,31 @@ func TestClientOperations(t *testing.T) {
 	})
 }
 
+func TestClientContextDeadline(t *testing.T) {
+	withFakeServer(t, func(req, resp chan string, addr string) {
+		resp <- "+OK\r\n"
+		srv := DefaultServer()
+		srv.Address = addr
+		cl, err := Dial(srv, "123456")
+		assert.NoError(t, err)
+		assert.Contains(t, <-req, "HELLO")
+
+		resp <- "+OK\r\n"
+		err = cl.PushContext(context.Background(), NewJob("foo", 1))
+		assert.NoError(t, err)
+		assert.Contains(t, <-req, "PUSH")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = cl.BeatContext(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, <-req, "BEAT")
+
+		resp <- "+OK\r\n"
+		_ = cl.Close()
+	})
+}
+
 const fakeServerBinding = "localhost:44434"
 
 func withFakeServer(t *testing.T, fn func(chan string, chan string, string)) {
@@ -189,3 +216,47 @@ func TestPasswordHashing(t *testing.T) {
 	result := hash(pwd, salt, iterations)
 	assert.Equal(t, "6d877f8e5544b1f2598768f817413ab8a357afffa924dedae99eb91472d4ec30", result)
 }
+
+func TestAddress(t *testing.T) {
+	urls := map[string]string{
+		"tcp://:pw@faktory.example.com:7419":   "faktory.example.com:7419",
+		"tcp://:pw@faktory1,faktory2:7419":     "faktory1:7419,faktory2:7419",
+		"tcp://:pw@faktory1,faktory2:7500":     "faktory1:7500,faktory2:7500",
+		"tcp://faktory1,faktory2":              "faktory1:7419,faktory2:7419",
+		"tcp://faktory1:7420,faktory2:7421":    "faktory1:7420,faktory2:7421",
+		"tcp://10.0.0.1,10.0.0.2:7419?ns=test": "10.0.0.1:7419,10.0.0.2:7419",
+	}
+	for val, expected := range urls {
+		uri, err := url.Parse(val)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, address(uri), val)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	active := withScriptedServer(t, func(n int, line string) (string, bool) {
+		return "+OK\r\n", false
+	})
+	// nothing listens on the standby's address
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	standby := listener.Addr().String()
+	_ = listener.Close()
+
+	t.Setenv("FAKTORY_PROVIDER", "MIKE_URL")
+	t.Setenv("MIKE_URL", "tcp://:secret@"+standby+","+active.Address+"?namespace=billing")
+	srv := DefaultServer()
+	assert.NoError(t, srv.ReadFromEnv())
+	assert.Equal(t, standby+","+active.Address, srv.Address)
+	assert.Equal(t, "secret", srv.Password)
+	assert.Equal(t, "billing", srv.Namespace)
+
+	cl, err := Open()
+	assert.NoError(t, err)
+	assert.Equal(t, active.Address, cl.Location)
+	_ = cl.Close()
+
+	srv.Address = standby
+	_, err = srv.Open()
+	assert.Error(t, err)
+}
@@ -21,6 +21,27 @@ type DataSnapshot struct {
 	TotalProcessed uint64                    `json:"total_processed"`
 	TotalEnqueued  uint64                    `json:"total_enqueued"`
 	TotalQueues    uint64                    `json:"total_queues"`
+	Metrics        *MetricsSnapshot          `json:"metrics,omitempty"`
+	// Pushes rejected with OVERLOAD for each queue, see [limits]
+	Rejected map[string]uint64 `json:"rejected,omitempty"`
+}
+
+// MetricsSnapshot summarizes the jobs executed within the last
+// Window seconds, grouped by jobtype and queue.
+type MetricsSnapshot struct {
+	Jobtypes map[string]ExecutionStats `json:"jobtypes"`
+	Queues   map[string]ExecutionStats `json:"queues"`
+	Window   uint64                    `json:"window"`
+}
+
+// Execution durations are in seconds.  Processed includes
+// failed executions.
+type ExecutionStats struct {
+	Processed uint64  `json:"processed"`
+	Failed    uint64  `json:"failed"`
+	P50       float64 `json:"p50"`
+	P95       float64 `json:"p95"`
+	P99       float64 `json:"p99"`
 }
 
 type ServerSnapshot struct {
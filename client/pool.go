@@ -1,13 +1,68 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
 
 	"github.com/contribsys/faktory/internal/pool"
 )
 
 type Pool struct {
 	pool.Pool
+
+	// Options must be changed before the pool is used.
+	Options PoolOptions
+
+	inUse     atomic.Int64
+	dials     atomic.Uint64
+	failures  atomic.Uint64
+	discarded atomic.Uint64
+}
+
+// PoolOptions control how a Pool manages its connections.
+type PoolOptions struct {
+	// Get checks that the server hasn't closed connections which have
+	// been idle for at least this long, e.g. because it restarted, and
+	// discards dead ones.  The check costs up to a millisecond.  A negative
+	// value disables the check.
+	CheckIdle time.Duration
+	// Connections older than this are closed rather than reused, zero
+	// for no limit.
+	MaxLifetime time.Duration
+	// Retry failed dials this many times, waiting with exponential
+	// backoff from BackoffMin up to BackoffMax between attempts.
+	DialRetries int
+	BackoffMin  time.Duration
+	BackoffMax  time.Duration
+	// Pool.Push retries a push which fails because of a broken connection
+	// once on a new connection.  The retry uses the same jid but the job
+	// will be enqueued twice if the server received the first push.
+	RetryPush bool
+}
+
+func DefaultPoolOptions() PoolOptions {
+	return PoolOptions{
+		CheckIdle:   time.Second,
+		DialRetries: 3,
+		BackoffMin:  100 * time.Millisecond,
+		BackoffMax:  2 * time.Second,
+	}
+}
+
+// PoolStats is a snapshot of a Pool's connections.
+type PoolStats struct {
+	Idle  int
+	InUse int
+	// Dials is the number of connection attempts, Failures the number
+	// which failed.
+	Dials    uint64
+	Failures uint64
+	// Discarded is the number of connections closed because they were
+	// broken, dead or older than MaxLifetime.
+	Discarded uint64
 }
 
 // NewPool creates a new Pool object through which multiple clients will be managed on your behalf.
@@ -19,14 +74,13 @@ type Pool struct {
 //
 // The dialer clients in this pool use is determined by the URI scheme in FAKTORY_PROVIDER.
 func NewPool(capacity int) (*Pool, error) {
-	return newPool(capacity, func() (pool.Closeable, error) { return Open() })
+	return newPool(capacity, Open)
 }
 
 // NewPoolWithDialer creates a new Pool object similar to NewPool but clients will use the
 // provided dialer instead of default ones.
 func NewPoolWithDialer(capacity int, dialer Dialer) (*Pool, error) {
-	fn := func() (pool.Closeable, error) { return OpenWithDialer(dialer) }
-	return newPool(capacity, fn)
+	return newPool(capacity, func() (*Client, error) { return OpenWithDialer(dialer) })
 }
 
 type ClientConstructor func() (*Client, error)
@@ -49,36 +103,95 @@ type ClientConstructor func() (*Client, error)
 //
 // Do NOT call Close() on the client, as the lifecycle is managed internally.
 func NewPoolWithClientConstructor(capacity int, fn ClientConstructor) (*Pool, error) {
-	return newPool(capacity, func() (pool.Closeable, error) { return fn() })
+	return newPool(capacity, fn)
 }
 
-// newPool creates a *Pool channel with the provided capacity and opener.
-func newPool(capacity int, opener pool.Factory) (*Pool, error) {
-	var p Pool
+// newPool creates a *Pool channel with the provided capacity and constructor.
+func newPool(capacity int, fn ClientConstructor) (*Pool, error) {
+	p := &Pool{Options: DefaultPoolOptions()}
 	var err error
-	p.Pool, err = pool.NewChannelPool(0, capacity, opener)
-	return &p, err
+	p.Pool, err = pool.NewChannelPool(0, capacity, p.dialer(fn))
+	return p, err
+}
+
+// dialer opens new connections, retrying with exponential backoff and
+// jitter so clients don't reconnect in lockstep when the server restarts.
+func (p *Pool) dialer(fn ClientConstructor) pool.Factory {
+	return func() (pool.Closeable, error) {
+		backoff := p.Options.BackoffMin
+		for attempt := 0; ; attempt++ {
+			p.dials.Add(1)
+			client, err := fn()
+			if err == nil {
+				return client, nil
+			}
+			p.failures.Add(1)
+			if attempt >= p.Options.DialRetries {
+				return nil, err
+			}
+			if backoff > 0 {
+				time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+			}
+			backoff = min(backoff*2, p.Options.BackoffMax)
+		}
+	}
 }
 
 // Get retrieves a Client from the pool. This Client is created, internally, by calling
-// the Open() function, and has all the same behaviors.
+// the Open() function, and has all the same behaviors.  Idle clients which are dead
+// or older than the pool's MaxLifetime are discarded.
 func (p *Pool) Get() (*Client, error) {
-	conn, err := p.Pool.Get()
-	if err != nil {
-		return nil, err
+	for {
+		conn, err := p.Pool.Get()
+		if err != nil {
+			return nil, err
+		}
+		pc := conn.(*pool.PoolConn)
+		client, ok := pc.Closeable.(*Client)
+		if !ok {
+			// Because we control the entire lifecycle of the pool, internally, this should never happen.
+			panic(fmt.Sprintf("Connection is not a Faktory client instance: %+v", conn))
+		}
+		client.poolConn = pc
+
+		if p.expired(client) || !p.healthy(client) {
+			p.discard(client)
+			continue
+		}
+		p.inUse.Add(1)
+		return client, nil
 	}
-	pc := conn.(*pool.PoolConn)
-	client, ok := pc.Closeable.(*Client)
-	if !ok {
-		// Because we control the entire lifecycle of the pool, internally, this should never happen.
-		panic(fmt.Sprintf("Connection is not a Faktory client instance: %+v", conn))
+}
+
+func (p *Pool) expired(client *Client) bool {
+	return p.Options.MaxLifetime > 0 && time.Since(client.created) > p.Options.MaxLifetime
+}
+
+func (p *Pool) healthy(client *Client) bool {
+	if p.Options.CheckIdle < 0 || client.lastUsed.IsZero() {
+		// just connected
+		return true
+	}
+	if time.Since(client.lastUsed) < p.Options.CheckIdle {
+		return true
 	}
-	client.poolConn = pc
-	return client, nil
+	return client.alive()
+}
+
+func (p *Pool) discard(client *Client) {
+	p.discarded.Add(1)
+	client.poolConn.MarkUnusable()
+	_ = client.poolConn.Close()
 }
 
 // Put returns a client to the pool.
 func (p *Pool) Put(client *Client) {
+	p.inUse.Add(-1)
+	if client.poolConn.Unusable() || p.expired(client) {
+		p.discard(client)
+		return
+	}
+	client.lastUsed = time.Now()
 	_ = client.poolConn.Close()
 }
 
@@ -90,3 +203,46 @@ func (p *Pool) With(fn func(conn *Client) error) error {
 	defer p.Put(conn)
 	return fn(conn)
 }
+
+// Push pushes the job with a client from the pool, retrying once on a
+// new connection if Options.RetryPush is set and the connection breaks.
+func (p *Pool) Push(job *Job) error {
+	return p.PushContext(context.Background(), job)
+}
+
+// PushContext is Push which gives up when ctx is done.
+func (p *Pool) PushContext(ctx context.Context, job *Job) error {
+	if job.Jid == "" {
+		// a retry must push the same job
+		job.Jid = RandomJid()
+	}
+	attempts := 1
+	if p.Options.RetryPush {
+		attempts = 2
+	}
+
+	var err error
+	for range attempts {
+		broken := false
+		err = p.With(func(cl *Client) error {
+			err := cl.PushContext(ctx, job)
+			broken = err != nil && ctx.Err() == nil && cl.poolConn.Unusable()
+			return err
+		})
+		if !broken {
+			break
+		}
+	}
+	return err
+}
+
+// Stats returns the pool's connection counts.
+func (p *Pool) Stats() PoolStats {
+	return PoolStats{
+		Idle:      p.Len(),
+		InUse:     int(p.inUse.Load()),
+		Dials:     p.dials.Load(),
+		Failures:  p.failures.Load(),
+		Discarded: p.discarded.Load(),
+	}
+}
@@ -1,8 +1,15 @@
 package client
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -158,3 +165,174 @@ func TestNewPoolWithClientConstructor(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestPoolDiscardsInterruptedClient(t *testing.T) {
+	s := DefaultServer()
+	s.Password = "foobar"
+	s.Address = fakeServerBinding
+
+	p, err := NewPoolWithClientConstructor(10, s.Open)
+	assert.NoError(t, err)
+
+	withFakeServer(t, func(req, resp chan string, addr string) {
+		resp <- "+OK\r\n"
+		cl, err := p.Get()
+		assert.NoError(t, err)
+		assert.Contains(t, <-req, "HELLO")
+
+		// a canceled context fails before sending anything
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		job, err := cl.FetchContext(ctx, "default")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, job)
+
+		// the server never responds so the FETCH is interrupted
+		ctx, cancel = context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		start := time.Now()
+		job, err = cl.FetchContext(ctx, "default")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, job)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Contains(t, <-req, "FETCH")
+
+		p.Put(cl)
+		assert.Equal(t, 0, p.Len())
+		resp <- "+OK\r\n"
+	})
+}
+
+// withScriptedServer runs a fake server which answers each line from the
+// nth connection with the reply from fn and then closes the connection
+// if fn says so.
+func withScriptedServer(t *testing.T, fn func(n int, line string) (string, bool)) *Server {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for n := 1; ; n++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(n int, conn net.Conn) {
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+				_, _ = conn.Write([]byte("+HI {\"v\":2}\r\n"))
+				buf := bufio.NewReader(conn)
+				for {
+					line, err := buf.ReadString('\n')
+					if err != nil {
+						return
+					}
+					rsp, hangup := fn(n, line)
+					_, _ = conn.Write([]byte(rsp))
+					if hangup {
+						return
+					}
+				}
+			}(n, conn)
+		}
+	}()
+
+	s := DefaultServer()
+	s.Address = listener.Addr().String()
+	return s
+}
+
+func TestPoolDiscardsDeadClient(t *testing.T) {
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		// the first connection dies while idle, like after a server restart
+		return "+OK\r\n", n == 1
+	})
+	p, err := NewPoolWithClientConstructor(10, s.Open)
+	assert.NoError(t, err)
+	p.Options.CheckIdle = 0
+
+	cl, err := p.Get()
+	assert.NoError(t, err)
+	p.Put(cl)
+	assert.Equal(t, PoolStats{Idle: 1, Dials: 1}, p.Stats())
+	time.Sleep(10 * time.Millisecond)
+
+	cl, err = p.Get()
+	assert.NoError(t, err)
+	_, err = cl.Beat()
+	assert.NoError(t, err)
+	assert.Equal(t, PoolStats{InUse: 1, Dials: 2, Discarded: 1}, p.Stats())
+	p.Put(cl)
+}
+
+func TestPoolMaxLifetime(t *testing.T) {
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		return "+OK\r\n", false
+	})
+	p, err := NewPoolWithClientConstructor(10, s.Open)
+	assert.NoError(t, err)
+	p.Options.MaxLifetime = 10 * time.Millisecond
+
+	cl, err := p.Get()
+	assert.NoError(t, err)
+	p.Put(cl)
+	assert.Equal(t, 1, p.Len())
+
+	time.Sleep(20 * time.Millisecond)
+	cl, err = p.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, PoolStats{InUse: 1, Dials: 2, Discarded: 1}, p.Stats())
+	p.Put(cl)
+}
+
+func TestPoolDialRetries(t *testing.T) {
+	calls := 0
+	p, err := NewPoolWithClientConstructor(10, func() (*Client, error) {
+		calls++
+		return nil, fmt.Errorf("connection refused")
+	})
+	assert.NoError(t, err)
+	p.Options.DialRetries = 2
+	p.Options.BackoffMin = time.Millisecond
+	p.Options.BackoffMax = 2 * time.Millisecond
+
+	cl, err := p.Get()
+	assert.Error(t, err)
+	assert.Nil(t, cl)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, PoolStats{Dials: 3, Failures: 3}, p.Stats())
+}
+
+func TestPoolRetryPush(t *testing.T) {
+	var mu sync.Mutex
+	pushes := []string{}
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		if !strings.HasPrefix(line, "PUSH") {
+			return "+OK\r\n", false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		pushes = append(pushes, line)
+		// the first two connections break before replying
+		if n <= 2 {
+			return "", true
+		}
+		return "+OK\r\n", false
+	})
+	p, err := NewPoolWithClientConstructor(10, s.Open)
+	assert.NoError(t, err)
+
+	job := NewJob("foo", 1)
+	err = p.Push(job)
+	assert.Error(t, err)
+
+	p.Options.RetryPush = true
+	err = p.Push(job)
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, pushes, 3)
+	assert.Equal(t, pushes[1], pushes[2])
+	assert.Equal(t, PoolStats{Idle: 1, Dials: 3, Discarded: 2}, p.Stats())
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Jobs carry W3C Trace Context (https://www.w3.org/TR/trace-context/)
+// in these custom attributes so traces continue from the code which
+// pushed a job, through Faktory, into the worker which executes it.
+const (
+	TraceParentKey = "traceparent"
+	TraceStateKey  = "tracestate"
+)
+
+// TraceContext identifies the span which pushed a job.
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	State   string
+}
+
+// ParseTraceParent parses a version 00 traceparent header value,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceParent(value string) (TraceContext, error) {
+	tc := TraceContext{}
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return tc, fmt.Errorf("invalid traceparent %q", value)
+	}
+	// future versions may append fields, version 00 must not
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return tc, fmt.Errorf("invalid traceparent %q", value)
+	}
+
+	var flags [1]byte
+	for _, field := range []struct {
+		src string
+		dst []byte
+	}{{parts[1], tc.TraceID[:]}, {parts[2], tc.SpanID[:]}, {parts[3], flags[:]}} {
+		if strings.ToLower(field.src) != field.src {
+			return tc, fmt.Errorf("invalid traceparent %q", value)
+		}
+		if _, err := hex.Decode(field.dst, []byte(field.src)); err != nil {
+			return tc, fmt.Errorf("invalid traceparent %q: %w", value, err)
+		}
+	}
+	tc.Flags = flags[0]
+	if !tc.IsValid() {
+		return tc, fmt.Errorf("invalid traceparent %q", value)
+	}
+	return tc, nil
+}
+
+// IsValid is false if the trace or span ID is all zeroes.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != [16]byte{} && tc.SpanID != [8]byte{}
+}
+
+func (tc TraceContext) IsSampled() bool {
+	return tc.Flags&0x01 == 0x01
+}
+
+// TraceParent formats the traceparent header value.
+func (tc TraceContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(tc.TraceID[:]), hex.EncodeToString(tc.SpanID[:]), tc.Flags)
+}
+
+type traceKey struct{}
+
+// ContextWithTrace returns a copy of ctx carrying the trace context.
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceFromContext returns the trace context added by ContextWithTrace.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceKey{}).(TraceContext)
+	return tc, ok
+}
+
+// A TracePropagator moves trace context between a context.Context and
+// the carrier stored in a job.  The default propagates the TraceContext
+// from ContextWithTrace.  To use OpenTelemetry, wrap its propagator:
+//
+//	type otelPropagator struct{}
+//
+//	func (otelPropagator) Inject(ctx context.Context, carrier map[string]string) {
+//		otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(carrier))
+//	}
+//
+//	func (otelPropagator) Extract(ctx context.Context, carrier map[string]string) context.Context {
+//		return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+//	}
+//
+//	client.Propagator = otelPropagator{}
+type TracePropagator interface {
+	Inject(ctx context.Context, carrier map[string]string)
+	Extract(ctx context.Context, carrier map[string]string) context.Context
+}
+
+var Propagator TracePropagator = w3cPropagator{}
+
+type w3cPropagator struct{}
+
+func (w3cPropagator) Inject(ctx context.Context, carrier map[string]string) {
+	tc, ok := TraceFromContext(ctx)
+	if !ok || !tc.IsValid() {
+		return
+	}
+	carrier[TraceParentKey] = tc.TraceParent()
+	if tc.State != "" {
+		carrier[TraceStateKey] = tc.State
+	}
+}
+
+func (w3cPropagator) Extract(ctx context.Context, carrier map[string]string) context.Context {
+	tc, err := ParseTraceParent(carrier[TraceParentKey])
+	if err != nil {
+		return ctx
+	}
+	tc.State = carrier[TraceStateKey]
+	return ContextWithTrace(ctx, tc)
+}
+
+// InjectTrace stores the trace context of ctx in the job's custom
+// attributes.  Call it before pushing a job so the job's spans are
+// part of the current trace.
+func (j *Job) InjectTrace(ctx context.Context) *Job {
+	carrier := map[string]string{}
+	Propagator.Inject(ctx, carrier)
+	if carrier[TraceParentKey] == "" {
+		return j
+	}
+	for _, key := range []string{TraceParentKey, TraceStateKey} {
+		if val, ok := carrier[key]; ok && val != "" {
+			j.SetCustom(key, val)
+		}
+	}
+	return j
+}
+
+// ExtractTrace returns a copy of ctx with the trace context stored in
+// the job so a worker's spans continue the trace which pushed the job.
+func (j *Job) ExtractTrace(ctx context.Context) context.Context {
+	carrier := j.traceCarrier()
+	if carrier[TraceParentKey] == "" {
+		return ctx
+	}
+	return Propagator.Extract(ctx, carrier)
+}
+
+// TraceContext returns the trace context stored in the job, if any.
+func (j *Job) TraceContext() (TraceContext, bool) {
+	return TraceFromContext(w3cPropagator{}.Extract(context.Background(), j.traceCarrier()))
+}
+
+func (j *Job) traceCarrier() map[string]string {
+	carrier := map[string]string{}
+	for _, key := range []string{TraceParentKey, TraceStateKey} {
+		if val, ok := j.GetCustom(key); ok {
+			if str, ok := val.(string); ok {
+				carrier[key] = str
+			}
+		}
+	}
+	return carrier
+}
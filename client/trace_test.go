@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tp := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tc, err := ParseTraceParent(tp)
+	assert.NoError(t, err)
+	assert.True(t, tc.IsValid())
+	assert.True(t, tc.IsSampled())
+	assert.Equal(t, byte(0x4b), tc.TraceID[0])
+	assert.Equal(t, byte(0xb7), tc.SpanID[7])
+	assert.Equal(t, tp, tc.TraceParent())
+
+	// future versions can have more fields
+	_, err = ParseTraceParent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what")
+	assert.NoError(t, err)
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	}
+	for _, value := range invalid {
+		_, err := ParseTraceParent(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestJobTrace(t *testing.T) {
+	job := NewJob("TracedJob")
+	job.InjectTrace(context.Background())
+	assert.Nil(t, job.Custom)
+	_, ok := job.TraceContext()
+	assert.False(t, ok)
+
+	tc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+	tc.State = "vendor=abc"
+	job.InjectTrace(ContextWithTrace(context.Background(), tc))
+	assert.Equal(t, tc.TraceParent(), job.Custom[TraceParentKey])
+	assert.Equal(t, "vendor=abc", job.Custom[TraceStateKey])
+
+	found, ok := job.TraceContext()
+	assert.True(t, ok)
+	assert.Equal(t, tc, found)
+
+	extracted, ok := TraceFromContext(job.ExtractTrace(context.Background()))
+	assert.True(t, ok)
+	assert.Equal(t, tc, extracted)
+
+	job.SetCustom(TraceParentKey, "garbage")
+	_, ok = TraceFromContext(job.ExtractTrace(context.Background()))
+	assert.False(t, ok)
+}
@@ -0,0 +1,365 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/contribsys/faktory/util"
+)
+
+// Perform executes a job with its arguments.  Return an error to FAIL
+// the job so Faktory retries it.  Use JobFrom(ctx) to access the job.
+type Perform func(ctx context.Context, args ...any) error
+
+// WorkerMiddleware wraps the execution of each job.  Call next to
+// continue processing or return an error to fail the job.
+type WorkerMiddleware func(ctx context.Context, job *Job, next func(ctx context.Context) error) error
+
+type jobKey struct{}
+
+// JobFrom returns the job being executed by a Manager.
+func JobFrom(ctx context.Context) *Job {
+	job, _ := ctx.Value(jobKey{}).(*Job)
+	return job
+}
+
+// A Manager fetches jobs from Faktory and executes them with the Perform
+// function registered for their jobtype.  It sends BEAT to the server
+// and follows the worker lifecycle: it stops fetching when quieted and
+// shuts down when terminated, either locally or from the Busy page.
+//
+//	mgr := client.NewManager()
+//	mgr.Register("SomeJob", func(ctx context.Context, args ...any) error {
+//		return nil
+//	})
+//	mgr.Queues = []string{"critical", "default"}
+//	err := mgr.Run(ctx)
+type Manager struct {
+	// Number of jobs to execute at once
+	Concurrency int
+	// Queues are fetched in strict priority order
+	Queues []string
+	// If set, each fetch orders these queues randomly by weight instead,
+	// so a queue with weight 3 is checked first three times as often as
+	// one with weight 1.
+	QueueWeights map[string]int
+	// How long shutdown waits for executing jobs before canceling their
+	// contexts.  Unfinished jobs are retried once their reservation expires.
+	ShutdownTimeout time.Duration
+	// How often to send BEAT, which must be well under the server's
+	// 60 second heartbeat timeout
+	BeatInterval time.Duration
+	// The connections used to talk to Faktory; defaults to a pool
+	// configured from the environment, see Open
+	Pool *Pool
+	// Wid identifies the process to Faktory.  NewManager uses
+	// RandomProcessWid if set, otherwise a random wid.  A Pool you
+	// supply should dial with the same wid, see Server.Wid.
+	Wid string
+
+	mu         sync.Mutex
+	jobs       map[string]Perform
+	middleware []WorkerMiddleware
+
+	quiet     chan struct{}
+	quietOnce sync.Once
+	term      chan struct{}
+	termOnce  sync.Once
+}
+
+func NewManager() *Manager {
+	wid := RandomProcessWid
+	if wid == "" {
+		wid = RandomJid()
+	}
+	return &Manager{
+		Wid:             wid,
+		Concurrency:     20,
+		Queues:          []string{"default"},
+		ShutdownTimeout: 25 * time.Second,
+		BeatInterval:    15 * time.Second,
+		jobs:            map[string]Perform{},
+		quiet:           make(chan struct{}),
+		term:            make(chan struct{}),
+	}
+}
+
+// Register the function which executes jobs of the given type.
+func (m *Manager) Register(jobtype string, fn Perform) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[jobtype] = fn
+}
+
+// Use adds middleware which runs around every job, in the order added.
+func (m *Manager) Use(mw ...WorkerMiddleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.middleware = append(m.middleware, mw...)
+}
+
+// Quiet stops fetching new jobs.  Executing jobs finish normally and
+// the process stays registered with Faktory.  A quiet Manager can't
+// be restarted.
+func (m *Manager) Quiet() {
+	m.quietOnce.Do(func() { close(m.quiet) })
+}
+
+// Terminate shuts down the Manager, causing Run to return.
+func (m *Manager) Terminate() {
+	m.Quiet()
+	m.termOnce.Do(func() { close(m.term) })
+}
+
+func (m *Manager) state() string {
+	select {
+	case <-m.term:
+		return "terminate"
+	default:
+	}
+	select {
+	case <-m.quiet:
+		return "quiet"
+	default:
+	}
+	return ""
+}
+
+// Run processes jobs until ctx is done or the Manager is terminated,
+// then waits up to ShutdownTimeout for executing jobs to finish.
+func (m *Manager) Run(ctx context.Context) error {
+	if m.Pool == nil {
+		srv := DefaultServer()
+		if err := srv.ReadFromEnv(); err != nil {
+			return fmt.Errorf("cannot read configuration from env: %w", err)
+		}
+		// the server identifies the process's connections by their wid
+		srv.Wid = m.Wid
+		pool, err := NewPoolWithClientConstructor(m.Concurrency+2, srv.Open)
+		if err != nil {
+			return err
+		}
+		defer pool.Close()
+		m.Pool = pool
+	}
+
+	// beat once up front so the process appears on the Busy page
+	// and a bad connection is reported immediately
+	if err := m.beat(ctx); err != nil {
+		return err
+	}
+
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+	fetchCtx, stopFetching := context.WithCancel(ctx)
+	defer stopFetching()
+
+	done := make(chan struct{})
+	var beats sync.WaitGroup
+	beats.Go(func() {
+		ticker := time.NewTicker(m.BeatInterval)
+		defer ticker.Stop()
+		quiet := m.quiet
+		for {
+			select {
+			case <-done:
+				return
+			case <-quiet:
+				stopFetching()
+				quiet = nil
+			case <-ticker.C:
+				if err := m.beat(context.WithoutCancel(ctx)); err != nil {
+					util.Warnf("Unable to send heartbeat: %v", err)
+				}
+			}
+		}
+	})
+
+	var wg sync.WaitGroup
+	for range m.Concurrency {
+		wg.Go(func() {
+			m.process(fetchCtx, jobCtx)
+		})
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-m.term:
+	}
+	m.Terminate()
+	stopFetching()
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(m.ShutdownTimeout):
+		util.Warnf("Jobs still executing after %v, canceling them", m.ShutdownTimeout)
+		cancelJobs()
+		// give them a moment to return and report so they aren't using
+		// the pool when it's closed
+		select {
+		case <-finished:
+		case <-time.After(time.Second):
+			util.Warnf("Jobs ignored cancellation, abandoning them")
+		}
+	}
+
+	// a final beat tells the server we've shut down
+	close(done)
+	beats.Wait()
+	if err := m.beat(context.WithoutCancel(ctx)); err != nil {
+		util.Warnf("Unable to send heartbeat: %v", err)
+	}
+	return nil
+}
+
+// beat sends our state to the server and acts on the state it
+// sends back.
+func (m *Manager) beat(ctx context.Context) error {
+	var resp string
+	err := m.Pool.With(func(cl *Client) error {
+		var err error
+		resp, err = cl.BeatContext(ctx, m.state())
+		return err
+	})
+	if err != nil || resp == "" {
+		return err
+	}
+
+	var hash map[string]string
+	if err := json.Unmarshal([]byte(resp), &hash); err != nil {
+		return fmt.Errorf("invalid BEAT response: %s", resp)
+	}
+	switch hash["state"] {
+	case "quiet":
+		m.Quiet()
+	case "terminate":
+		m.Terminate()
+	}
+	return nil
+}
+
+func (m *Manager) process(fetchCtx context.Context, jobCtx context.Context) {
+	for fetchCtx.Err() == nil {
+		var job *Job
+		err := m.Pool.With(func(cl *Client) error {
+			var err error
+			job, err = cl.FetchContext(fetchCtx, m.fetchQueues()...)
+			return err
+		})
+		if err != nil {
+			if fetchCtx.Err() != nil {
+				return
+			}
+			util.Warnf("Unable to fetch: %v", err)
+			select {
+			case <-fetchCtx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if job != nil {
+			m.execute(jobCtx, job)
+		}
+	}
+}
+
+func (m *Manager) fetchQueues() []string {
+	if len(m.QueueWeights) == 0 {
+		return m.Queues
+	}
+	return weightedOrder(m.QueueWeights)
+}
+
+// weightedOrder returns the queues in a random order where each queue's
+// chance of being next is proportional to its weight.
+func weightedOrder(weights map[string]int) []string {
+	names := []string{}
+	total := 0
+	for name, weight := range weights {
+		if weight > 0 {
+			names = append(names, name)
+			total += weight
+		}
+	}
+	slices.Sort(names)
+
+	order := make([]string, 0, len(names))
+	for len(names) > 0 {
+		pick := rand.Intn(total) //nolint:gosec
+		for idx, name := range names {
+			pick -= weights[name]
+			if pick < 0 {
+				order = append(order, name)
+				total -= weights[name]
+				names = slices.Delete(names, idx, idx+1)
+				break
+			}
+		}
+	}
+	return order
+}
+
+// Execute runs the job through the middleware and its Perform function
+// without reporting the result to Faktory, so tests can run jobs
+// synchronously.  A panic is returned as an error.
+func (m *Manager) Execute(ctx context.Context, job *Job) error {
+	_, err := m.perform(ctx, job)
+	return err
+}
+
+// execute runs the job and reports the result to Faktory.
+func (m *Manager) execute(ctx context.Context, job *Job) {
+	backtrace, err := m.perform(ctx, job)
+	rerr := m.Pool.With(func(cl *Client) error {
+		if err == nil {
+			return cl.Ack(job.Jid)
+		}
+		return cl.Fail(job.Jid, err, backtrace)
+	})
+	if rerr != nil {
+		util.Warnf("Unable to report result of %s: %v", job.Jid, rerr)
+	}
+}
+
+// perform runs the middleware and the job's Perform function,
+// converting a panic into an error with its backtrace.
+func (m *Manager) perform(ctx context.Context, job *Job) (backtrace []byte, err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("panic: %v", x)
+			backtrace = debug.Stack()
+		}
+	}()
+
+	m.mu.Lock()
+	fn, ok := m.jobs[job.Type]
+	chain := m.middleware
+	m.mu.Unlock()
+
+	next := func(ctx context.Context) error {
+		if !ok {
+			return fmt.Errorf("no Perform registered for jobtype %s", job.Type)
+		}
+		return fn(ctx, job.Args...)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		mw, inner := chain[i], next
+		next = func(ctx context.Context) error {
+			return mw(ctx, job, inner)
+		}
+	}
+
+	ctx = context.WithValue(job.ExtractTrace(ctx), jobKey{}, job)
+	return nil, next(ctx)
+}
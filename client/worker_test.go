@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedOrder(t *testing.T) {
+	weights := map[string]int{"critical": 3, "default": 1, "never": 0}
+	first := map[string]int{}
+	for range 1000 {
+		order := weightedOrder(weights)
+		assert.ElementsMatch(t, []string{"critical", "default"}, order)
+		first[order[0]]++
+	}
+	assert.Greater(t, first["critical"], 600)
+	assert.Greater(t, first["default"], 100)
+}
+
+func TestManager(t *testing.T) {
+	var mu sync.Mutex
+	results := []string{}
+	jobs := []*Job{NewJob("Good", 1), NewJob("Panics", 2), NewJob("Unknown")}
+
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasPrefix(line, "FETCH"):
+			if len(jobs) == 0 {
+				time.Sleep(10 * time.Millisecond)
+				return "$-1\r\n", false
+			}
+			data, _ := json.Marshal(jobs[0])
+			jobs = jobs[1:]
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(data), data), false
+		case strings.HasPrefix(line, "ACK"), strings.HasPrefix(line, "FAIL"):
+			results = append(results, line)
+		}
+		return "+OK\r\n", false
+	})
+
+	mgr := NewManager()
+	mgr.Concurrency = 2
+	mgr.Pool, _ = NewPoolWithClientConstructor(4, s.Open)
+	mgr.Register("Good", func(ctx context.Context, args ...any) error {
+		assert.Equal(t, "Good", JobFrom(ctx).Type)
+		return nil
+	})
+	mgr.Register("Panics", func(ctx context.Context, args ...any) error {
+		panic("boom")
+	})
+	seen := []string{}
+	mgr.Use(func(ctx context.Context, job *Job, next func(ctx context.Context) error) error {
+		mu.Lock()
+		seen = append(seen, job.Type)
+		mu.Unlock()
+		return next(ctx)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- mgr.Run(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(results) == 3
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{"Good", "Panics", "Unknown"}, seen)
+	all := strings.Join(results, "")
+	assert.Contains(t, all, "ACK")
+	assert.Contains(t, all, "panic: boom")
+	assert.Contains(t, all, "backtrace")
+	assert.Contains(t, all, "no Perform registered for jobtype Unknown")
+}
+
+func TestManagerTerminatedByServer(t *testing.T) {
+	var mu sync.Mutex
+	beats := []string{}
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		switch {
+		case strings.HasPrefix(line, "FETCH"):
+			time.Sleep(10 * time.Millisecond)
+			return "$-1\r\n", false
+		case strings.HasPrefix(line, "BEAT"):
+			mu.Lock()
+			defer mu.Unlock()
+			beats = append(beats, line)
+			if len(beats) > 1 {
+				return "$21\r\n{\"state\":\"terminate\"}\r\n", false
+			}
+		}
+		return "+OK\r\n", false
+	})
+
+	mgr := NewManager()
+	mgr.Concurrency = 1
+	mgr.BeatInterval = 10 * time.Millisecond
+	s.Wid = mgr.Wid
+	mgr.Pool, _ = NewPoolWithClientConstructor(3, s.Open)
+
+	done := make(chan error)
+	go func() { done <- mgr.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Manager did not terminate")
+	}
+	assert.Equal(t, "terminate", mgr.state())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, beats[len(beats)-1], `"current_state":"terminate"`)
+	assert.Contains(t, beats[0], fmt.Sprintf(`"wid":%q`, mgr.Wid))
+	assert.Empty(t, RandomProcessWid)
+}
+
+func TestManagerCancelsSlowJobs(t *testing.T) {
+	var mu sync.Mutex
+	results := []string{}
+	fetched := false
+	s := withScriptedServer(t, func(n int, line string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasPrefix(line, "FETCH"):
+			if fetched {
+				time.Sleep(10 * time.Millisecond)
+				return "$-1\r\n", false
+			}
+			fetched = true
+			data, _ := json.Marshal(NewJob("Slow"))
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(data), data), false
+		case strings.HasPrefix(line, "ACK"), strings.HasPrefix(line, "FAIL"):
+			results = append(results, line)
+		}
+		return "+OK\r\n", false
+	})
+
+	mgr := NewManager()
+	mgr.Concurrency = 1
+	mgr.ShutdownTimeout = 10 * time.Millisecond
+	mgr.Pool, _ = NewPoolWithClientConstructor(3, s.Open)
+	started := make(chan struct{})
+	mgr.Register("Slow", func(ctx context.Context, args ...any) error {
+		close(started)
+		<-ctx.Done()
+		// cleaning up takes a little while
+		time.Sleep(50 * time.Millisecond)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- mgr.Run(ctx) }()
+	<-started
+	cancel()
+	assert.NoError(t, <-done)
+
+	// the job reported its failure before Run returned
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, results, 1) {
+		assert.Contains(t, results[0], "FAIL")
+		assert.Contains(t, results[0], "context canceled")
+	}
+}
@@ -1,25 +1,20 @@
 package main
 
 import (
-	"log"
-	"time"
+	"errors"
 
+	"github.com/contribsys/faktory/alerts"
 	"github.com/contribsys/faktory/cli"
-	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/metrics"
+	"github.com/contribsys/faktory/schema"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/tracing"
 	"github.com/contribsys/faktory/util"
+	"github.com/contribsys/faktory/webhook"
 	"github.com/contribsys/faktory/webui"
 )
 
-func logPreamble() {
-	log.SetFlags(0)
-	log.Println(client.Name, client.Version)
-	log.Printf("Copyright © %d Contributed Systems LLC", time.Now().Year())
-	log.Println("Licensed under the GNU Affero Public License 3.0")
-}
-
 func main() {
-	logPreamble()
-
 	opts := cli.ParseArguments()
 	util.InitLogger(opts.LogLevel)
 	util.Debugf("Options: %v", opts)
@@ -31,15 +26,26 @@ func main() {
 	}
 	defer func() { _ = stopper() }()
 
+	// handle signals before booting, a standby server may wait in Boot
+	// for a long time
+	go cli.HandleSignals(s)
+
 	err = s.Boot()
+	if errors.Is(err, server.ErrStandbyStopped) {
+		return
+	}
 	if err != nil {
 		util.Error("Unable to boot the command server", err)
 		return
 	}
 
 	s.Register(webui.Subsystem(opts.WebBinding))
+	s.Register(alerts.Subsystem())
+	s.Register(webhook.Subsystem())
+	s.Register(metrics.Subsystem())
+	s.Register(tracing.Subsystem())
+	s.Register(schema.Subsystem())
 
-	go cli.HandleSignals(s)
 	go func() {
 		err = s.Run()
 		if err != nil {
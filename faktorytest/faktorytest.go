@@ -0,0 +1,229 @@
+// Package faktorytest runs an in-process Faktory server for testing code
+// which pushes or processes jobs.  The server stores everything in memory
+// so redis-server isn't required.
+//
+//	func TestSignup(t *testing.T) {
+//		srv := faktorytest.Start(t)
+//		cl := srv.Client()
+//
+//		err := signup(cl, "mike@example.com")
+//		assert.NoError(t, err)
+//		assert.Len(t, srv.JobsOfType("SendWelcomeEmail"), 1)
+//
+//		mgr := client.NewManager()
+//		mgr.Register("SendWelcomeEmail", sendWelcomeEmail)
+//		assert.Equal(t, 1, srv.Drain(mgr))
+//	}
+package faktorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// Server is a Faktory server listening on a random localhost port.
+type Server struct {
+	*server.Server
+	t testing.TB
+}
+
+// Start boots a server backed by an in-memory store.  It's stopped when
+// the test finishes.
+func Start(t testing.TB) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	opts := &server.ServerOptions{
+		Binding:          "localhost:0",
+		StorageDirectory: dir,
+		ConfigDirectory:  dir,
+		Environment:      "test",
+		PoolSize:         server.DefaultMaxPoolSize,
+	}
+	s, err := server.NewServer(opts)
+	if err != nil {
+		t.Fatalf("Unable to create Faktory server: %v", err)
+	}
+	err = s.BootWithStore(storage.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("Unable to boot Faktory server: %v", err)
+	}
+
+	go func() {
+		if err := s.Run(); err != nil {
+			util.Warnf("Faktory server stopped: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		s.Shutdown()
+		s.Stop(nil)
+	})
+	return &Server{Server: s, t: t}
+}
+
+// Address returns the "host:port" the server is listening on.
+func (s *Server) Address() string {
+	return s.Addr().String()
+}
+
+// ClientServer returns the client configuration for connecting to the
+// server, e.g. for client.NewPoolWithDialer or a Manager's Pool.
+func (s *Server) ClientServer() *client.Server {
+	srv := client.DefaultServer()
+	srv.Address = s.Address()
+	return srv
+}
+
+// Client opens a connection to the server which is closed when the
+// test finishes.
+func (s *Server) Client() *client.Client {
+	s.t.Helper()
+	cl, err := client.Dial(s.ClientServer(), "")
+	if err != nil {
+		s.t.Fatalf("Unable to connect to Faktory: %v", err)
+	}
+	s.t.Cleanup(func() { _ = cl.Close() })
+	return cl
+}
+
+// Jobs returns the jobs enqueued in the given queue, next to be
+// fetched first.
+func (s *Server) Jobs(queue string) []*client.Job {
+	s.t.Helper()
+	ctx := context.Background()
+	q, ok := s.Store().ExistingQueue(ctx, queue)
+	if !ok {
+		return []*client.Job{}
+	}
+
+	jobs := []*client.Job{}
+	err := q.Each(ctx, func(_ int, data []byte) error {
+		var job client.Job
+		if err := util.JsonUnmarshal(data, &job); err != nil {
+			return err
+		}
+		// queues are fetched from the end
+		jobs = append([]*client.Job{&job}, jobs...)
+		return nil
+	})
+	if err != nil {
+		s.t.Fatalf("Unable to read queue %s: %v", queue, err)
+	}
+	return jobs
+}
+
+// JobsOfType returns the pushed jobs of the given jobtype waiting in
+// any queue or scheduled for later.
+func (s *Server) JobsOfType(jobtype string) []*client.Job {
+	s.t.Helper()
+	ctx := context.Background()
+
+	jobs := []*client.Job{}
+	for _, name := range s.queueNames() {
+		for _, job := range s.Jobs(name) {
+			if job.Type == jobtype {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	err := s.Store().Scheduled().Each(ctx, func(_ int, entry storage.SortedEntry) error {
+		job, err := entry.Job()
+		if err == nil && job.Type == jobtype {
+			jobs = append(jobs, job)
+		}
+		return err
+	})
+	if err != nil {
+		s.t.Fatalf("Unable to read scheduled jobs: %v", err)
+	}
+	return jobs
+}
+
+// Drain fetches and performs jobs from the given queues, or every queue
+// if none are given, until they are empty.  Jobs pushed while draining
+// are performed too.  Each job is executed synchronously by the Manager
+// and then acknowledged, or failed into the retry set if it returns an
+// error.  Returns the number of jobs performed.
+func (s *Server) Drain(mgr *client.Manager, queues ...string) int {
+	s.t.Helper()
+	ctx := context.Background()
+
+	count := 0
+	for {
+		names := queues
+		if len(names) == 0 {
+			names = s.queueNames()
+		}
+		if !s.anyQueued(names) {
+			return count
+		}
+
+		job, err := s.Manager().Fetch(ctx, "faktorytest", names...)
+		if err != nil {
+			s.t.Fatalf("Unable to fetch job: %v", err)
+		}
+		if job == nil {
+			continue
+		}
+		count++
+
+		err = mgr.Execute(ctx, job)
+		if err == nil {
+			_, err = s.Manager().Acknowledge(ctx, job.Jid)
+		} else {
+			err = s.Manager().Fail(ctx, &manager.FailPayload{
+				Jid:          job.Jid,
+				ErrorMessage: err.Error(),
+				ErrorType:    "unknown",
+			})
+		}
+		if err != nil {
+			s.t.Fatalf("Unable to report result of %s: %v", job.Jid, err)
+		}
+	}
+}
+
+// FastForward enqueues the scheduled jobs and retries which are due
+// within the given duration, as if that much time had passed.  Returns
+// the number of jobs enqueued.
+func (s *Server) FastForward(d time.Duration) int64 {
+	s.t.Helper()
+	ctx := context.Background()
+	when := time.Now().Add(d)
+
+	scheduled, err := s.Manager().EnqueueScheduledJobs(ctx, when)
+	if err != nil {
+		s.t.Fatalf("Unable to enqueue scheduled jobs: %v", err)
+	}
+	retries, err := s.Manager().RetryJobs(ctx, when)
+	if err != nil {
+		s.t.Fatalf("Unable to enqueue retries: %v", err)
+	}
+	return scheduled + retries
+}
+
+func (s *Server) queueNames() []string {
+	names := []string{}
+	s.Store().EachQueue(context.Background(), func(q storage.Queue) {
+		names = append(names, q.Name())
+	})
+	return names
+}
+
+func (s *Server) anyQueued(names []string) bool {
+	ctx := context.Background()
+	for _, name := range names {
+		q, ok := s.Store().ExistingQueue(ctx, name)
+		if ok && q.Size(ctx) > 0 && !q.IsPaused(ctx) {
+			return true
+		}
+	}
+	return false
+}
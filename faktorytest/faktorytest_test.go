@@ -0,0 +1,75 @@
+package faktorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	srv := Start(t)
+	cl := srv.Client()
+
+	first := client.NewJob("SomeJob", 1)
+	assert.NoError(t, cl.Push(first))
+	second := client.NewJob("OtherJob", 2)
+	assert.NoError(t, cl.Push(second))
+	critical := client.NewJob("SomeJob", 3)
+	critical.Queue = "critical"
+	assert.NoError(t, cl.Push(critical))
+	later := client.NewJob("SomeJob", 4)
+	later.At = util.Thens(time.Now().Add(time.Hour))
+	assert.NoError(t, cl.Push(later))
+
+	jobs := srv.Jobs("default")
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, first.Jid, jobs[0].Jid)
+	assert.Equal(t, second.Jid, jobs[1].Jid)
+	assert.Len(t, srv.Jobs("missing"), 0)
+	assert.Len(t, srv.JobsOfType("SomeJob"), 3)
+	assert.Len(t, srv.JobsOfType("OtherJob"), 1)
+
+	state, err := cl.CurrentState()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, state.Data.TotalEnqueued)
+	assert.EqualValues(t, 1, state.Data.Sets["scheduled"])
+
+	t.Run("Fetch", func(t *testing.T) {
+		job, err := cl.Fetch("critical")
+		assert.NoError(t, err)
+		assert.Equal(t, critical.Jid, job.Jid)
+		assert.NoError(t, cl.Ack(job.Jid))
+	})
+
+	t.Run("Drain", func(t *testing.T) {
+		performed := []any{}
+		mgr := client.NewManager()
+		mgr.Register("SomeJob", func(ctx context.Context, args ...any) error {
+			performed = append(performed, args[0])
+			return nil
+		})
+		mgr.Register("OtherJob", func(ctx context.Context, args ...any) error {
+			return errors.New("oops")
+		})
+
+		assert.Equal(t, 2, srv.Drain(mgr))
+		assert.Equal(t, []any{float64(1)}, performed)
+		assert.Len(t, srv.Jobs("default"), 0)
+		assert.EqualValues(t, 1, srv.Store().Retries().Size(context.Background()))
+
+		// the first retry is due within a minute, the scheduled job
+		// in an hour
+		assert.EqualValues(t, 1, srv.FastForward(time.Minute))
+		assert.EqualValues(t, 1, srv.FastForward(2*time.Hour))
+		assert.Len(t, srv.JobsOfType("OtherJob"), 1)
+		assert.Len(t, srv.Jobs("default"), 2)
+
+		assert.Equal(t, 2, srv.Drain(mgr, "default"))
+		assert.Equal(t, []any{float64(1), float64(4)}, performed)
+	})
+}
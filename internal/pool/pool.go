@@ -86,6 +86,13 @@ func (p *PoolConn) MarkUnusable() {
 	p.mu.Unlock()
 }
 
+// Unusable returns true if the connection was marked unusable.
+func (p *PoolConn) Unusable() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.unusable
+}
+
 // wrapConn wraps a standard net.Conn to a poolConn net.Conn.
 func (c *channelPool) wrapConn(conn Closeable) Closeable {
 	p := &PoolConn{c: c}
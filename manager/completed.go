@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/util"
+)
+
+// CompletedRetention controls how much history Faktory keeps for
+// acknowledged jobs.  Both limits apply to each queue separately.
+// History is disabled if both limits are zero.
+type CompletedRetention struct {
+	// Keep no more than MaxSize jobs per queue
+	MaxSize int64
+	// Keep jobs for no longer than MaxAge
+	MaxAge time.Duration
+}
+
+func (cr CompletedRetention) Enabled() bool {
+	return cr.MaxSize > 0 || cr.MaxAge > 0
+}
+
+// A CompletedJob is the record kept for a job after it has been
+// acknowledged.  The job's attributes are inlined so the payload can be
+// searched and loaded like any other job in a SortedSet.
+type CompletedJob struct {
+	*client.Job
+	Wid        string `json:"wid"`
+	ReservedAt string `json:"reserved_at"`
+	AckedAt    string `json:"acked_at"`
+}
+
+// Duration is the time the worker took to execute the job.
+func (cj *CompletedJob) Duration() time.Duration {
+	start, err := util.ParseTime(cj.ReservedAt)
+	if err != nil {
+		return 0
+	}
+	end, err := util.ParseTime(cj.AckedAt)
+	if err != nil {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+func (m *manager) SetCompletedRetention(cr CompletedRetention) {
+	m.completed.Store(&cr)
+}
+
+func (m *manager) CompletedRetention() CompletedRetention {
+	cr := m.completed.Load()
+	if cr == nil {
+		return CompletedRetention{}
+	}
+	return *cr
+}
+
+func (m *manager) recordCompletion(ctx context.Context, res *Reservation, now time.Time) error {
+	if !m.CompletedRetention().Enabled() {
+		return nil
+	}
+
+	cj := &CompletedJob{
+		Job:        res.Job,
+		Wid:        res.Wid,
+		ReservedAt: res.Since,
+		AckedAt:    util.Thens(now),
+	}
+	data, err := json.Marshal(cj)
+	if err != nil {
+		return fmt.Errorf("cannot marshal completed job: %w", err)
+	}
+	return m.store.Completed(res.Job.Queue).AddElement(ctx, cj.AckedAt, res.Job.Jid, data)
+}
+
+// PurgeCompleted trims each queue's completed job history according
+// to the current retention policy.
+func (m *manager) PurgeCompleted(ctx context.Context, when time.Time) (int64, error) {
+	cr := m.CompletedRetention()
+	if !cr.Enabled() {
+		return 0, nil
+	}
+
+	names, err := m.store.CompletedQueues(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := int64(0)
+	for _, name := range names {
+		set := m.store.Completed(name)
+		if cr.MaxAge > 0 {
+			cutoff := util.Thens(when.Add(-cr.MaxAge))
+			for {
+				count, err := set.RemoveBefore(ctx, cutoff, 100, func([]byte) error {
+					return nil
+				})
+				total += count
+				if err != nil {
+					return total, err
+				}
+				if count < 100 {
+					break
+				}
+			}
+		}
+		if cr.MaxSize > 0 {
+			count, err := set.Trim(ctx, cr.MaxSize)
+			total += count
+			if err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, nil
+}
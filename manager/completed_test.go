@@ -0,0 +1,89 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletedHistory(t *testing.T) {
+	withRedis(t, "completed", func(t *testing.T, store storage.Store) {
+		bg := context.Background()
+
+		t.Run("Disabled", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+
+			job := client.NewJob("CompletedJob", 1, 2, 3)
+			assert.NoError(t, m.reserve(bg, "workerId", &simpleLease{job: job}))
+			_, err := m.Acknowledge(bg, job.Jid)
+			assert.NoError(t, err)
+			assert.EqualValues(t, 0, store.Completed("default").Size(bg))
+		})
+
+		t.Run("Record", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+			m.SetCompletedRetention(CompletedRetention{MaxSize: 10})
+
+			job := client.NewJob("CompletedJob", 1, 2, 3)
+			job.EnqueuedAt = util.Nows()
+			assert.NoError(t, m.reserve(bg, "workerId", &simpleLease{job: job}))
+			_, err := m.Acknowledge(bg, job.Jid)
+			assert.NoError(t, err)
+
+			set := store.Completed("default")
+			assert.EqualValues(t, 1, set.Size(bg))
+			names, err := store.CompletedQueues(bg)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"default"}, names)
+
+			_, err = set.Page(bg, 0, 1, func(idx int, entry storage.SortedEntry) error {
+				var cj CompletedJob
+				assert.NoError(t, util.JsonUnmarshal(entry.Value(), &cj))
+				assert.Equal(t, job.Jid, cj.Jid)
+				assert.Equal(t, "workerId", cj.Wid)
+				assert.Equal(t, job.EnqueuedAt, cj.EnqueuedAt)
+				assert.NotEmpty(t, cj.ReservedAt)
+				assert.NotEmpty(t, cj.AckedAt)
+				assert.True(t, cj.Duration() >= 0)
+
+				j, err := entry.Job()
+				assert.NoError(t, err)
+				assert.Equal(t, job.Jid, j.Jid)
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+
+		t.Run("Purge", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+			m.SetCompletedRetention(CompletedRetention{MaxSize: 3, MaxAge: time.Hour})
+
+			for range 5 {
+				job := client.NewJob("CompletedJob", 1, 2, 3)
+				assert.NoError(t, m.reserve(bg, "workerId", &simpleLease{job: job}))
+				_, err := m.Acknowledge(bg, job.Jid)
+				assert.NoError(t, err)
+			}
+			set := store.Completed("default")
+			assert.EqualValues(t, 5, set.Size(bg))
+
+			count, err := m.PurgeCompleted(bg, time.Now())
+			assert.NoError(t, err)
+			assert.EqualValues(t, 2, count)
+			assert.EqualValues(t, 3, set.Size(bg))
+
+			count, err = m.PurgeCompleted(bg, time.Now().Add(2*time.Hour))
+			assert.NoError(t, err)
+			assert.EqualValues(t, 3, count)
+			assert.EqualValues(t, 0, set.Size(bg))
+		})
+	})
+}
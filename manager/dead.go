@@ -0,0 +1,258 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// DeadLimit bounds how long dead jobs are kept and how many are kept.
+// Zero values fall back to the next broader limit.
+type DeadLimit struct {
+	TTL     time.Duration
+	MaxSize int64
+}
+
+// DeadRetention controls the Dead set.  The most specific limit wins:
+// a job's `dead_ttl` custom attribute (in seconds), then its jobtype,
+// then its queue, then the global limit.
+//
+// TTLs are applied when a job dies so changes only affect jobs which die
+// afterwards.  Size limits are enforced by the Purge task which removes
+// the jobs closest to expiry first, which with differing TTLs aren't
+// necessarily the jobs which died first.
+type DeadRetention struct {
+	DeadLimit
+	Queues   map[string]DeadLimit
+	Jobtypes map[string]DeadLimit
+}
+
+func (dr DeadRetention) hasScopedSizes() bool {
+	for _, dl := range dr.Queues {
+		if dl.MaxSize > 0 {
+			return true
+		}
+	}
+	for _, dl := range dr.Jobtypes {
+		if dl.MaxSize > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// TTLFor returns how long the given job should be kept once dead.
+func (dr DeadRetention) TTLFor(job *client.Job) time.Duration {
+	if ttl := customDeadTTL(job); ttl > 0 {
+		return ttl
+	}
+	if dl, ok := dr.Jobtypes[job.Type]; ok && dl.TTL > 0 {
+		return dl.TTL
+	}
+	if dl, ok := dr.Queues[job.Queue]; ok && dl.TTL > 0 {
+		return dl.TTL
+	}
+	if dr.TTL > 0 {
+		return dr.TTL
+	}
+	return DeadTTL
+}
+
+func customDeadTTL(job *client.Job) time.Duration {
+	val, ok := job.GetCustom("dead_ttl")
+	if !ok {
+		return 0
+	}
+
+	var secs float64
+	switch v := val.(type) {
+	case float64:
+		secs = v
+	case int:
+		secs = float64(v)
+	case int64:
+		secs = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0
+		}
+		secs = f
+	default:
+		return 0
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+func (m *manager) SetDeadRetention(dr DeadRetention) {
+	m.dead.Store(&dr)
+}
+
+func (m *manager) DeadRetention() DeadRetention {
+	dr := m.dead.Load()
+	if dr == nil {
+		return DeadRetention{}
+	}
+	return *dr
+}
+
+// DeadExpiry returns the time at which the given job should be
+// purged from the Dead set if it dies now.
+func (m *manager) DeadExpiry(job *client.Job) time.Time {
+	return time.Now().Add(m.DeadRetention().TTLFor(job))
+}
+
+func (m *manager) sendToMorgue(ctx context.Context, job *client.Job) error {
+	bytes, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("cannot marshal job payload: %w", err)
+	}
+
+	expiry := util.Thens(m.DeadExpiry(job))
+	return m.store.Dead().AddElement(ctx, expiry, job.Jid, bytes)
+}
+
+const (
+	// trimDead reads the Dead set in pages of this many entries and at
+	// most deadTrimPages pages per Purge so a huge Dead set doesn't
+	// hold up the other tasks.
+	deadTrimBatch = 1000
+	deadTrimPages = 10
+)
+
+// deadTrim is trimDead's progress through the Dead set, kept between
+// Purge runs.  A pass first counts the jobs of each queue and jobtype,
+// then removes the excess.
+type deadTrim struct {
+	mu       sync.Mutex
+	removing bool
+	cursor   int
+	queues   map[string]int64
+	jobtypes map[string]int64
+}
+
+func (dt *deadTrim) reset() {
+	dt.removing = false
+	dt.cursor = 0
+	dt.queues = map[string]int64{}
+	dt.jobtypes = map[string]int64{}
+}
+
+// trimDead enforces the per-queue and per-jobtype size limits.  This
+// needs to scan the Dead set twice, once to count jobs and once to
+// remove the excess, so it only runs if such a limit is configured and
+// scans a bounded number of entries per run, picking up where the last
+// run stopped.  Jobs which die or expire during a pass may be missed or
+// counted twice; the next pass corrects for them.
+//
+// The Dead set is ordered by expiry so the jobs closest to expiry are
+// removed first.  With differing TTLs that isn't necessarily the jobs
+// which died first.
+func (m *manager) trimDead(ctx context.Context, dr DeadRetention) (int64, error) {
+	dt := &m.trim
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	if dt.queues == nil {
+		dt.reset()
+	}
+
+	dead := m.store.Dead()
+	total := int64(0)
+	for range deadTrimPages {
+		doomed := []storage.SortedEntry{}
+		count, err := dead.Page(ctx, dt.cursor, deadTrimBatch, func(_ int, ent storage.SortedEntry) error {
+			job, err := ent.Job()
+			if err != nil {
+				return nil
+			}
+			if !dt.removing {
+				dt.queues[job.Queue]++
+				dt.jobtypes[job.Type]++
+			} else if dt.queues[job.Queue] > 0 || dt.jobtypes[job.Type] > 0 {
+				// the job counts against whichever limits still have
+				// excess, the other count must not go negative
+				take(dt.queues, job.Queue)
+				take(dt.jobtypes, job.Type)
+				doomed = append(doomed, ent)
+			}
+			return nil
+		})
+		if err != nil {
+			dt.reset()
+			return total, err
+		}
+
+		// remove after reading the page so removals don't shift it
+		// under us, then step over the entries which remain
+		for _, ent := range doomed {
+			if err := dead.RemoveEntry(ctx, ent); err != nil {
+				dt.reset()
+				return total, err
+			}
+			total++
+		}
+		dt.cursor += count - len(doomed)
+		finished := count < deadTrimBatch || (dt.removing && !dt.pending())
+		if !finished {
+			continue
+		}
+
+		if dt.removing || !dt.excess(dr) {
+			dt.reset()
+			return total, nil
+		}
+		dt.removing = true
+		dt.cursor = 0
+	}
+	return total, nil
+}
+
+// take counts one removed job against the name if it has jobs left to
+// remove.
+func take(counts map[string]int64, name string) {
+	if counts[name] > 0 {
+		counts[name]--
+	}
+}
+
+// pending returns true if any queue or jobtype still has jobs to remove.
+func (dt *deadTrim) pending() bool {
+	for _, count := range dt.queues {
+		if count > 0 {
+			return true
+		}
+	}
+	for _, count := range dt.jobtypes {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// excess turns the counts into the number of jobs to remove from each
+// queue and jobtype, returning true if there are any.
+func (dt *deadTrim) excess(dr DeadRetention) bool {
+	excess := false
+	for name, count := range dt.queues {
+		dt.queues[name] = max(0, count-dr.Queues[name].MaxSize)
+		if dr.Queues[name].MaxSize <= 0 {
+			dt.queues[name] = 0
+		}
+		excess = excess || dt.queues[name] > 0
+	}
+	for name, count := range dt.jobtypes {
+		dt.jobtypes[name] = max(0, count-dr.Jobtypes[name].MaxSize)
+		if dr.Jobtypes[name].MaxSize <= 0 {
+			dt.jobtypes[name] = 0
+		}
+		excess = excess || dt.jobtypes[name] > 0
+	}
+	return excess
+}
@@ -0,0 +1,205 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadTTL(t *testing.T) {
+	dr := DeadRetention{}
+	job := client.NewJob("DeadJob", 1)
+	assert.Equal(t, DeadTTL, dr.TTLFor(job))
+
+	dr = DeadRetention{
+		DeadLimit: DeadLimit{TTL: 30 * 24 * time.Hour},
+		Queues:    map[string]DeadLimit{"critical": {TTL: 90 * 24 * time.Hour}},
+		Jobtypes:  map[string]DeadLimit{"NoisyJob": {TTL: 24 * time.Hour}, "SizedJob": {MaxSize: 10}},
+	}
+	assert.Equal(t, 30*24*time.Hour, dr.TTLFor(job))
+	job.Queue = "critical"
+	assert.Equal(t, 90*24*time.Hour, dr.TTLFor(job))
+	job.Type = "NoisyJob"
+	assert.Equal(t, 24*time.Hour, dr.TTLFor(job))
+	// a size-only limit doesn't override the queue's TTL
+	job.Type = "SizedJob"
+	assert.Equal(t, 90*24*time.Hour, dr.TTLFor(job))
+
+	job.SetCustom("dead_ttl", 3600)
+	assert.Equal(t, time.Hour, dr.TTLFor(job))
+
+	// custom attributes arrive from the network as JSON
+	var pushed client.Job
+	assert.NoError(t, json.Unmarshal([]byte(`{"jid":"abcdefghijkl","jobtype":"NoisyJob","custom":{"dead_ttl":60}}`), &pushed))
+	assert.Equal(t, time.Minute, dr.TTLFor(&pushed))
+
+	pushed.SetCustom("dead_ttl", "forever")
+	assert.Equal(t, 24*time.Hour, dr.TTLFor(&pushed))
+	pushed.SetCustom("dead_ttl", -5)
+	assert.Equal(t, 24*time.Hour, dr.TTLFor(&pushed))
+}
+
+func TestDeadRetention(t *testing.T) {
+	withRedis(t, "dead", func(t *testing.T, store storage.Store) {
+		bg := context.Background()
+		dead := store.Dead()
+
+		kill := func(m *manager, jobtype string, queue string) {
+			job := client.NewJob(jobtype, 1)
+			job.Queue = queue
+			assert.NoError(t, m.sendToMorgue(bg, job))
+		}
+
+		t.Run("Expiry", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+			m.SetDeadRetention(DeadRetention{DeadLimit: DeadLimit{TTL: time.Hour}})
+
+			job := client.NewJob("DeadJob", 1)
+			job.SetCustom("dead_ttl", 60)
+			assert.NoError(t, m.sendToMorgue(bg, job))
+			kill(m, "DeadJob", "default")
+			assert.EqualValues(t, 2, dead.Size(bg))
+
+			count, err := m.Purge(bg, time.Now().Add(2*time.Minute))
+			assert.NoError(t, err)
+			assert.EqualValues(t, 1, count)
+
+			_, err = dead.Page(bg, 0, 1, func(idx int, entry storage.SortedEntry) error {
+				j, err := entry.Job()
+				assert.NoError(t, err)
+				assert.NotEqual(t, job.Jid, j.Jid)
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+
+		t.Run("MaxSize", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+
+			for i := 0; i < 5; i++ {
+				kill(m, "DeadJob", "default")
+			}
+			count, err := m.Purge(bg, time.Now())
+			assert.NoError(t, err)
+			assert.EqualValues(t, 0, count)
+
+			m.SetDeadRetention(DeadRetention{DeadLimit: DeadLimit{MaxSize: 3}})
+			count, err = m.Purge(bg, time.Now())
+			assert.NoError(t, err)
+			assert.EqualValues(t, 2, count)
+			assert.EqualValues(t, 3, dead.Size(bg))
+		})
+
+		t.Run("ScopedMaxSize", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+			m.SetDeadRetention(DeadRetention{
+				Queues:   map[string]DeadLimit{"bulk": {MaxSize: 2}},
+				Jobtypes: map[string]DeadLimit{"NoisyJob": {MaxSize: 1}},
+			})
+
+			first := client.NewJob("BulkJob", 1)
+			first.Queue = "bulk"
+			assert.NoError(t, m.store.Dead().AddElement(bg, util.Thens(time.Now().Add(time.Hour)), first.Jid, mustMarshal(t, first)))
+			for i := 0; i < 3; i++ {
+				kill(m, "BulkJob", "bulk")
+			}
+			for i := 0; i < 3; i++ {
+				kill(m, "NoisyJob", "default")
+			}
+			kill(m, "DeadJob", "default")
+			assert.EqualValues(t, 8, dead.Size(bg))
+
+			count, err := m.Purge(bg, time.Now())
+			assert.NoError(t, err)
+			assert.EqualValues(t, 4, count)
+			assert.EqualValues(t, 4, dead.Size(bg))
+
+			// the bulk job closest to expiry is removed first
+			err = dead.Each(bg, func(idx int, entry storage.SortedEntry) error {
+				j, err := entry.Job()
+				assert.NoError(t, err)
+				assert.NotEqual(t, first.Jid, j.Jid)
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+	})
+}
+
+func TestTrimDeadCounts(t *testing.T) {
+	bg := context.Background()
+	store := storage.NewMemoryStore()
+	m := newManager(store)
+	m.SetDeadRetention(DeadRetention{
+		Queues:   map[string]DeadLimit{"bulk": {MaxSize: 10}},
+		Jobtypes: map[string]DeadLimit{"NoisyJob": {MaxSize: 10}},
+	})
+	dead := store.Dead()
+	expiry := time.Now().Add(time.Hour)
+	for i := range 12500 {
+		job := client.NewJob("NoisyJob", i)
+		if i%2 == 1 {
+			job.Type = "BulkJob"
+			job.Queue = "bulk"
+		}
+		assert.NoError(t, dead.AddElement(bg, util.Thens(expiry.Add(time.Duration(i)*time.Millisecond)), job.Jid, mustMarshal(t, job)))
+	}
+
+	// stop halfway through removing, each removal only counts against
+	// the limit it exceeds
+	for range 2 {
+		_, err := m.Purge(bg, time.Now())
+		assert.NoError(t, err)
+	}
+	assert.True(t, m.trim.removing)
+	for name, count := range m.trim.queues {
+		assert.GreaterOrEqual(t, count, int64(0), name)
+	}
+	for name, count := range m.trim.jobtypes {
+		assert.GreaterOrEqual(t, count, int64(0), name)
+	}
+
+	for range 2 {
+		_, err := m.Purge(bg, time.Now())
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 20, dead.Size(bg))
+}
+
+func mustMarshal(t *testing.T, job *client.Job) []byte {
+	data, err := json.Marshal(job)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestTrimDeadIncrementally(t *testing.T) {
+	bg := context.Background()
+	store := storage.NewMemoryStore()
+	m := newManager(store)
+	m.SetDeadRetention(DeadRetention{
+		Jobtypes: map[string]DeadLimit{"NoisyJob": {MaxSize: 10}},
+	})
+	dead := store.Dead()
+	expiry := time.Now().Add(time.Hour)
+	for i := range 12500 {
+		job := client.NewJob("NoisyJob", i)
+		assert.NoError(t, dead.AddElement(bg, util.Thens(expiry.Add(time.Duration(i)*time.Millisecond)), job.Jid, mustMarshal(t, job)))
+	}
+
+	// each run scans a bounded part of the set: counting, then removing
+	for _, removed := range []int64{0, 7000, 5490, 0} {
+		count, err := m.Purge(bg, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, removed, count)
+	}
+	assert.EqualValues(t, 10, dead.Size(bg))
+}
@@ -8,6 +8,7 @@ import (
 	"slices"
 
 	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
 	"github.com/contribsys/faktory/util"
 	"github.com/redis/go-redis/v9"
 )
@@ -102,11 +103,11 @@ restart:
 			return nil, err
 		}
 		ctxh := context.WithValue(ctx, MiddlewareHelperKey, Ctx{job, m, nil})
-		err = callMiddleware(ctxh, m.fetchChain, func() error {
+		err = callMiddleware(ctxh, m.hooks.fetch, func() error {
 			return m.reserve(ctxh, wid, lease)
 		})
 		if h, ok := err.(KnownError); ok {
-			util.Infof("JID %s: %s", job.Jid, h.Error())
+			util.Infow(h.Error(), jobFields(job, util.Fields{"wid": wid, "code": h.Code()}))
 			if h.Code() == "DISCARD" {
 				goto restart
 			}
@@ -177,6 +178,43 @@ func (f *BasicFetch) Fetch(ctx context.Context, wid string, queues ...string) (L
 	return Nothing, nil
 }
 
+// StoreFetcher polls the queues through the Store interface, for
+// stores which aren't backed by Redis and so can't BRPOP.
+func StoreFetcher(s storage.Store) Fetcher {
+	return &storeFetch{store: s}
+}
+
+type storeFetch struct {
+	store storage.Store
+}
+
+func (f *storeFetch) Fetch(ctx context.Context, wid string, queues ...string) (Lease, error) {
+	timeout := time.After(2 * time.Second)
+	for {
+		for _, name := range queues {
+			q, ok := f.store.ExistingQueue(ctx, name)
+			if !ok {
+				continue
+			}
+			data, err := q.Pop(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if data != nil {
+				return &simpleLease{payload: data}, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return Nothing, nil
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
 func brpop(ctx context.Context, r *redis.Client, queues ...string) ([]byte, error) {
 	// util.Infof("Fetching %v", queues)
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/contribsys/faktory/client"
@@ -19,7 +20,8 @@ const (
 	// in the job payload.
 	DefaultTimeout = 30 * 60
 
-	// Save dead jobs for 180 days, after that they will be purged
+	// Save dead jobs for 180 days by default, after that they will be purged.
+	// See DeadRetention to customize this.
 	DeadTTL = 180 * 24 * time.Hour
 )
 
@@ -55,6 +57,10 @@ func ExpectedError(code string, msg string) error {
 
 type Manager interface {
 	Push(ctx context.Context, job *client.Job) error
+	// Replace runs the push middleware and offloader on a job which
+	// replaces a stored one, e.g. a job edited in the Web UI, and passes
+	// its payload to store rather than enqueuing it.
+	Replace(ctx context.Context, job *client.Job, store func(data []byte) error) error
 	// TODO PushBulk(jobs []*client.Job) map[*client.Job]error
 
 	PauseQueue(ctx context.Context, qName string) error
@@ -99,8 +105,24 @@ type Manager interface {
 
 	ReapExpiredJobs(ctx context.Context, when time.Time) (int64, error)
 
-	// Purge deletes all dead jobs
+	// Purge deletes expired dead jobs and trims the Dead set
+	// according to the retention policy.
 	Purge(ctx context.Context, when time.Time) (int64, error)
+	SetDeadRetention(dr DeadRetention)
+	DeadRetention() DeadRetention
+	// DeadExpiry returns when the job should be purged if it dies now.
+	DeadExpiry(job *client.Job) time.Time
+
+	// PurgeCompleted trims the completed job history according to
+	// the retention policy.
+	PurgeCompleted(ctx context.Context, when time.Time) (int64, error)
+	SetCompletedRetention(cr CompletedRetention)
+	CompletedRetention() CompletedRetention
+
+	// Per-minute execution metrics are recorded on ACK and FAIL
+	// and kept for the retention period, zero disables them.
+	SetMetricsRetention(d time.Duration)
+	MetricsRetention() time.Duration
 
 	// EnqueueScheduledJobs enqueues scheduled jobs
 	EnqueueScheduledJobs(ctx context.Context, when time.Time) (int64, error)
@@ -109,9 +131,16 @@ type Manager interface {
 	RetryJobs(ctx context.Context, when time.Time) (int64, error)
 
 	BusyCount(wid string) int
+	QueueBusyCount(queue string) int
 
 	AddMiddleware(fntype string, fn MiddlewareFunc)
 
+	// SetOffloader installs a function which is called with each pushed
+	// job after the push middleware, just before the job is stored, so
+	// it can encrypt the job or move large arguments out of storage.
+	// Call it before the server starts.
+	SetOffloader(fn Offloader)
+
 	KV() storage.KV
 	Redis() *redis.Client
 	SetFetcher(f Fetcher)
@@ -121,23 +150,37 @@ func NewManager(s storage.Store) Manager {
 	return newManager(s)
 }
 
+// NewSharedManager creates a manager for another store which shares the
+// middleware and offloader of parent, e.g. for a namespace, so the
+// middleware added to either applies to both.
+func NewSharedManager(parent Manager, s storage.Store) Manager {
+	m := newManager(s)
+	m.hooks = parent.(*manager).hooks
+	return m
+}
+
 func newManager(s storage.Store) *manager {
 	m := &manager{
 		store:      s,
 		workingMap: map[string]*Reservation{},
-		pushChain:  make(MiddlewareChain, 0),
-		failChain:  make(MiddlewareChain, 0),
-		ackChain:   make(MiddlewareChain, 0),
-		fetchChain: make(MiddlewareChain, 0),
+		hooks:      &hooks{},
 	}
 	ctx := context.Background()
 	_ = m.loadWorkingSet(ctx)
 	p, _ := s.PausedQueues(ctx)
 	m.paused = p
-	m.fetcher = BasicFetcher(m.Redis())
+	if m.Redis() != nil {
+		m.fetcher = BasicFetcher(m.Redis())
+	} else {
+		m.fetcher = StoreFetcher(s)
+	}
 	return m
 }
 
+func (m *manager) SetOffloader(fn Offloader) {
+	m.hooks.offloader = fn
+}
+
 func (m *manager) SetFetcher(f Fetcher) {
 	m.fetcher = f
 }
@@ -153,18 +196,21 @@ func (m *manager) Redis() *redis.Client {
 func (m *manager) AddMiddleware(fntype string, fn MiddlewareFunc) {
 	switch fntype {
 	case "push":
-		m.pushChain = append(m.pushChain, fn)
+		m.hooks.push = append(m.hooks.push, fn)
 	case "ack":
-		m.ackChain = append(m.ackChain, fn)
+		m.hooks.ack = append(m.hooks.ack, fn)
 	case "fail":
-		m.failChain = append(m.failChain, fn)
+		m.hooks.fail = append(m.hooks.fail, fn)
 	case "fetch":
-		m.fetchChain = append(m.fetchChain, fn)
+		m.hooks.fetch = append(m.hooks.fetch, fn)
 	default:
 		panic(fmt.Sprintf("Unknown middleware type: %s", fntype))
 	}
 }
 
+// An Offloader may rewrite a job before it is stored, see SetOffloader.
+type Offloader func(ctx context.Context, job *client.Job) error
+
 type Lease interface {
 	Release() error
 	Payload() []byte
@@ -181,26 +227,74 @@ type manager struct {
 	// When client ack's JID, we can lookup reservation
 	// and remove stored entry quickly.
 	workingMap   map[string]*Reservation
-	pushChain    MiddlewareChain
-	fetchChain   MiddlewareChain
-	failChain    MiddlewareChain
-	ackChain     MiddlewareChain
+	hooks        *hooks
 	paused       []string
 	workingMutex sync.RWMutex
+
+	completed        atomic.Pointer[CompletedRetention]
+	dead             atomic.Pointer[DeadRetention]
+	metricsRetention atomic.Int64
+	trim             deadTrim
 }
 
-func (m *manager) Push(ctx context.Context, job *client.Job) error {
+// hooks are the middleware chains and offloader, which may be shared by
+// several managers.
+type hooks struct {
+	push      MiddlewareChain
+	fetch     MiddlewareChain
+	fail      MiddlewareChain
+	ack       MiddlewareChain
+	offloader Offloader
+}
+
+// jobFields adds the job's identifying attributes to the log fields.
+func jobFields(job *client.Job, fields util.Fields) util.Fields {
+	if fields == nil {
+		fields = util.Fields{}
+	}
+	if job != nil {
+		fields["jid"] = job.Jid
+		fields["jobtype"] = job.Type
+		fields["queue"] = job.Queue
+	}
+	return fields
+}
+
+// ValidateJob checks the job has the required attributes to be pushed.
+func ValidateJob(job *client.Job) error {
+	_, err := validateJob(job)
+	return err
+}
+
+// validateJob also returns the parsed at, which is zero for a job which
+// isn't scheduled.
+func validateJob(job *client.Job) (time.Time, error) {
+	var at time.Time
 	if job.Jid == "" || len(job.Jid) < 8 {
-		return fmt.Errorf("jobs must have a reasonable jid parameter")
+		return at, fmt.Errorf("jobs must have a reasonable jid parameter")
 	}
 	if job.Type == "" {
-		return fmt.Errorf("jobs must have a jobtype parameter")
+		return at, fmt.Errorf("jobs must have a jobtype parameter")
 	}
 	if job.Args == nil {
-		return fmt.Errorf("jobs must have an args parameter")
+		return at, fmt.Errorf("jobs must have an args parameter")
 	}
 	if job.ReserveFor > 86400 {
-		return fmt.Errorf("jobs cannot be reserved for more than one day")
+		return at, fmt.Errorf("jobs cannot be reserved for more than one day")
+	}
+	if job.At != "" {
+		var err error
+		if at, err = util.ParseTime(job.At); err != nil {
+			return at, fmt.Errorf("invalid timestamp for 'at': %q: %w", job.At, err)
+		}
+	}
+	return at, nil
+}
+
+func (m *manager) Push(ctx context.Context, job *client.Job) error {
+	t, err := validateJob(job)
+	if err != nil {
+		return err
 	}
 
 	if job.CreatedAt == "" {
@@ -211,17 +305,13 @@ func (m *manager) Push(ctx context.Context, job *client.Job) error {
 		job.Queue = "default"
 	}
 
-	var err error
-	var t time.Time
-	if job.At != "" {
-		t, err = util.ParseTime(job.At)
-		if err != nil {
-			return fmt.Errorf("invalid timestamp for 'at': %q: %w", job.At, err)
-		}
-	}
-
 	ctxh := context.WithValue(ctx, MiddlewareHelperKey, Ctx{job, m, nil})
-	err = callMiddleware(ctxh, m.pushChain, func() error {
+	err = callMiddleware(ctxh, m.hooks.push, func() error {
+		if m.hooks.offloader != nil {
+			if err := m.hooks.offloader(ctx, job); err != nil {
+				return err
+			}
+		}
 		if job.At != "" {
 			if t.After(time.Now()) {
 				data, err := json.Marshal(job)
@@ -237,12 +327,32 @@ func (m *manager) Push(ctx context.Context, job *client.Job) error {
 	})
 	if err != nil {
 		if k, ok := err.(KnownError); ok {
-			util.Infof("JID %s: %s", job.Jid, k.Error())
+			util.Infow(k.Error(), jobFields(job, util.Fields{"code": k.Code()}))
 		}
 	}
 	return err
 }
 
+func (m *manager) Replace(ctx context.Context, job *client.Job, store func(data []byte) error) error {
+	if err := ValidateJob(job); err != nil {
+		return err
+	}
+
+	ctxh := context.WithValue(ctx, MiddlewareHelperKey, Ctx{job, m, nil})
+	return callMiddleware(ctxh, m.hooks.push, func() error {
+		if m.hooks.offloader != nil {
+			if err := m.hooks.offloader(ctx, job); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("cannot marshal job payload: %w", err)
+		}
+		return store(data)
+	})
+}
+
 func (m *manager) enqueue(ctx context.Context, job *client.Job) error {
 	q, err := m.store.GetQueue(ctx, job.Queue)
 	if err != nil {
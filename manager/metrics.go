@@ -0,0 +1,44 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// SetMetricsRetention enables per-minute execution metrics for each
+// queue and jobtype, kept for the given duration.  Zero disables metrics.
+func (m *manager) SetMetricsRetention(d time.Duration) {
+	m.metricsRetention.Store(int64(d))
+}
+
+func (m *manager) MetricsRetention() time.Duration {
+	return time.Duration(m.metricsRetention.Load())
+}
+
+func (m *manager) recordExecution(ctx context.Context, res *Reservation, failed bool) {
+	ttl := m.MetricsRetention()
+	if ttl == 0 || res.Job == nil {
+		return
+	}
+
+	now := time.Now()
+	sample := storage.ExecutionSample{
+		At:      now,
+		Queue:   res.Job.Queue,
+		Jobtype: res.Job.Type,
+		Failed:  failed,
+	}
+	if since := res.ReservedAt(); !since.IsZero() {
+		sample.Duration = now.Sub(since)
+	}
+
+	// keep the bucket a minute longer than the window so the
+	// oldest minute shown is always complete
+	err := m.store.RecordExecution(ctx, sample, ttl+time.Minute)
+	if err != nil {
+		util.Errorw("Unable to record execution metrics", err, jobFields(res.Job, util.Fields{"wid": res.Wid}))
+	}
+}
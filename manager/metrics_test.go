@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionMetrics(t *testing.T) {
+	withRedis(t, "metrics", func(t *testing.T, store storage.Store) {
+		bg := context.Background()
+
+		summary := func() (map[string]*storage.ExecutionMetrics, map[string]*storage.ExecutionMetrics) {
+			minutes := []*storage.MinuteMetrics{}
+			err := store.ExecutionHistory(bg, time.Now().Add(-time.Minute), func(mm *storage.MinuteMetrics) {
+				minutes = append(minutes, mm)
+			})
+			assert.NoError(t, err)
+			return storage.SumExecutions(minutes)
+		}
+
+		t.Run("Disabled", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+			assert.EqualValues(t, 0, m.MetricsRetention())
+
+			job := client.NewJob("MetricsJob", 1, 2, 3)
+			assert.NoError(t, m.reserve(bg, "workerId", &simpleLease{job: job}))
+			_, err := m.Acknowledge(bg, job.Jid)
+			assert.NoError(t, err)
+
+			queues, jobtypes := summary()
+			assert.Len(t, queues, 0)
+			assert.Len(t, jobtypes, 0)
+		})
+
+		t.Run("Record", func(t *testing.T) {
+			assert.NoError(t, store.Flush(bg))
+			m := newManager(store)
+			m.SetMetricsRetention(time.Hour)
+			assert.Equal(t, time.Hour, m.MetricsRetention())
+
+			job := client.NewJob("MetricsJob", 1, 2, 3)
+			assert.NoError(t, m.reserve(bg, "workerId", &simpleLease{job: job}))
+			_, err := m.Acknowledge(bg, job.Jid)
+			assert.NoError(t, err)
+
+			job = client.NewJob("MetricsJob", 1, 2, 3)
+			job.Queue = "critical"
+			assert.NoError(t, m.reserve(bg, "workerId", &simpleLease{job: job}))
+			assert.NoError(t, m.Fail(bg, &FailPayload{Jid: job.Jid, ErrorMessage: "boom", ErrorType: "RuntimeError"}))
+
+			queues, jobtypes := summary()
+			assert.EqualValues(t, 1, queues["default"].Processed)
+			assert.EqualValues(t, 0, queues["default"].Failed)
+			assert.EqualValues(t, 1, queues["critical"].Processed)
+			assert.EqualValues(t, 1, queues["critical"].Failed)
+			assert.EqualValues(t, 2, jobtypes["MetricsJob"].Processed)
+			assert.EqualValues(t, 1, jobtypes["MetricsJob"].Failed)
+		})
+	})
+}
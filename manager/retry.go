@@ -103,6 +103,7 @@ func (m *manager) processFailure(ctx context.Context, jid string, failure *FailP
 	}
 
 	_ = m.store.Failure(ctx)
+	m.recordExecution(ctx, res, true)
 
 	job := res.Job
 
@@ -129,7 +130,7 @@ func (m *manager) processFailure(ctx context.Context, jid string, failure *FailP
 	}
 
 	ctxh := context.WithValue(ctx, MiddlewareHelperKey, Ctx{job, m, res})
-	return callMiddleware(ctxh, m.failChain, func() error {
+	return callMiddleware(ctxh, m.hooks.fail, func() error {
 		if job.Retry == nil || *job.Retry == 0 {
 			// no retry, no death, completely ephemeral, goodbye
 			return nil
@@ -137,7 +138,7 @@ func (m *manager) processFailure(ctx context.Context, jid string, failure *FailP
 		if job.Failure.RetryCount < *job.Retry {
 			return retryLater(ctx, m.store, job)
 		}
-		return sendToMorgue(ctx, m.store, job)
+		return m.sendToMorgue(ctx, job)
 	})
 }
 
@@ -152,16 +153,6 @@ func retryLater(ctx context.Context, store storage.Store, job *client.Job) error
 	return store.Retries().AddElement(ctx, when, job.Jid, bytes)
 }
 
-func sendToMorgue(ctx context.Context, store storage.Store, job *client.Job) error {
-	bytes, err := json.Marshal(job)
-	if err != nil {
-		return fmt.Errorf("cannot marshal job payload: %w", err)
-	}
-
-	expiry := util.Thens(time.Now().Add(DeadTTL))
-	return store.Dead().AddElement(ctx, expiry, job.Jid, bytes)
-}
-
 func nextRetry(job *client.Job) time.Time {
 	count := job.Failure.RetryCount
 	secs := (count * count * count * count) + 15 + (rand.Intn(30) * (count + 1)) //nolint:gosec
@@ -11,17 +11,36 @@ import (
 )
 
 func (m *manager) Purge(ctx context.Context, when time.Time) (int64, error) {
-	// TODO We need to purge the dead set if it collects more
-	// than N elements.  The dead set shouldn't be able to collect
-	// millions or billions of jobs.  Sidekiq uses a default max size
-	// of 10,000 jobs.
-	dead, err := m.store.Dead().RemoveBefore(ctx, util.Thens(when), 100, func([]byte) error {
-		return nil
-	})
-	if err != nil {
-		return 0, err
+	total := int64(0)
+	for {
+		count, err := m.store.Dead().RemoveBefore(ctx, util.Thens(when), 100, func([]byte) error {
+			return nil
+		})
+		total += count
+		if err != nil {
+			return total, err
+		}
+		if count < 100 {
+			break
+		}
+	}
+
+	dr := m.DeadRetention()
+	if dr.MaxSize > 0 {
+		count, err := m.store.Dead().Trim(ctx, dr.MaxSize)
+		total += count
+		if err != nil {
+			return total, err
+		}
 	}
-	return dead, nil
+	if dr.hasScopedSizes() {
+		count, err := m.trimDead(ctx, dr)
+		total += count
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
 }
 
 func (m *manager) EnqueueScheduledJobs(ctx context.Context, when time.Time) (int64, error) {
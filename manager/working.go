@@ -30,6 +30,13 @@ type Reservation struct {
 }
 
 func (res *Reservation) ReservedAt() time.Time {
+	if res.tsince.IsZero() && res.Since != "" {
+		// reservations restored from Redis only have the timestamp string
+		tm, err := util.ParseTime(res.Since)
+		if err == nil {
+			res.tsince = tm
+		}
+	}
 	return res.tsince
 }
 
@@ -68,6 +75,22 @@ func (m *manager) BusyCount(wid string) int {
 	return count
 }
 
+// QueueBusyCount returns the number of jobs from the given
+// queue which are currently being processed.
+func (m *manager) QueueBusyCount(queue string) int {
+	m.workingMutex.RLock()
+	defer m.workingMutex.RUnlock()
+
+	count := 0
+	for _, res := range m.workingMap {
+		if res.Job != nil && res.Job.Queue == queue {
+			count++
+		}
+	}
+
+	return count
+}
+
 /*
  * When we restart the server, we need to load the
  * current set of Reservations back into memory so any
@@ -157,7 +180,7 @@ func (m *manager) reserve(ctx context.Context, wid string, lease Lease) error {
 func (m *manager) Acknowledge(ctx context.Context, jid string) (*client.Job, error) {
 	res := m.clearReservation(jid)
 	if res == nil {
-		util.Infof("No such job to acknowledge %s", jid)
+		util.Infow("No such job to acknowledge", util.Fields{"jid": jid})
 		return nil, nil
 	}
 
@@ -172,14 +195,18 @@ func (m *manager) Acknowledge(ctx context.Context, jid string) (*client.Job, err
 	if res.lease != nil {
 		err = res.lease.Release()
 		if err != nil {
-			util.Error("Error releasing lease for "+jid, err)
+			util.Errorw("Error releasing lease", err, jobFields(res.Job, util.Fields{"wid": res.Wid}))
 		}
 	}
 
 	if res.Job != nil {
 		_ = m.store.Success(ctx)
+		m.recordExecution(ctx, res, false)
+		if err := m.recordCompletion(ctx, res, time.Now()); err != nil {
+			util.Errorw("Unable to record completed job", err, jobFields(res.Job, util.Fields{"wid": res.Wid}))
+		}
 		ctxh := context.WithValue(ctx, MiddlewareHelperKey, Ctx{res.Job, m, res})
-		err = callMiddleware(ctxh, m.ackChain, func() error {
+		err = callMiddleware(ctxh, m.hooks.ack, func() error {
 			return nil
 		})
 	}
@@ -103,6 +103,8 @@ func TestLoadWorkingSet(t *testing.T) {
 
 			assert.EqualValues(t, 1, m.BusyCount("workerId"))
 			assert.EqualValues(t, 0, m.BusyCount("fakeId"))
+			assert.EqualValues(t, 1, m.QueueBusyCount("default"))
+			assert.EqualValues(t, 0, m.QueueBusyCount("critical"))
 
 			aJob, err := m.Acknowledge(bg, job.Jid)
 			assert.NoError(t, err)
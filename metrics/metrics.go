@@ -0,0 +1,237 @@
+// Package metrics pushes Faktory's job and queue metrics to StatsD
+// or the Datadog agent.
+//
+//	[metrics]
+//	enabled = true
+//	statsd = "localhost:8125"
+//	prefix = "faktory."
+//	tags = ["env:production"]
+//	dogstatsd = true # send tags with each metric
+//
+// Counters: jobs.pushed, jobs.fetched, jobs.succeeded, jobs.failed
+// Timers: jobs.latency (enqueue to fetch), jobs.perform (fetch to ACK/FAIL)
+// Gauges: queue.size, retries.size, scheduled.size, dead.size, working.size
+//
+// Gauges are reported for every namespace, tagged with namespace:<name>
+// except for the default namespace.  Counters and timers cover the jobs
+// of all namespaces.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+type Options struct {
+	Address   string
+	Prefix    string
+	Tags      []string
+	DogStatsD bool
+}
+
+type Lifecycle struct {
+	opts   Options
+	client atomic.Pointer[Client]
+}
+
+func Subsystem() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) Name() string {
+	return "Metrics"
+}
+
+func (l *Lifecycle) Start(s *server.Server) error {
+	opts, err := parseOptions(s.Options)
+	if err != nil {
+		return err
+	}
+	if err := l.connect(opts); err != nil {
+		return err
+	}
+
+	// Middleware can't be removed so it is always installed and
+	// does nothing while metrics are disabled.
+	m := s.Manager()
+	m.AddMiddleware("push", l.pushed)
+	m.AddMiddleware("fetch", l.fetched)
+	m.AddMiddleware("ack", l.acked)
+	m.AddMiddleware("fail", l.failed)
+	s.AddTask(10, &gauges{l: l, s: s})
+
+	go func() {
+		<-s.Stopper()
+		if old := l.client.Swap(nil); old != nil {
+			_ = old.Close()
+		}
+	}()
+	return nil
+}
+
+func (l *Lifecycle) Reload(s *server.Server) error {
+	opts, err := parseOptions(s.Options)
+	if err != nil {
+		return err
+	}
+	if fmt.Sprint(opts) == fmt.Sprint(l.opts) {
+		return nil
+	}
+	return l.connect(opts)
+}
+
+func (l *Lifecycle) connect(opts Options) error {
+	var c *Client
+	if opts.Address != "" {
+		var err error
+		c, err = NewClient(opts.Address, opts.Prefix, opts.Tags, opts.DogStatsD)
+		if err != nil {
+			return fmt.Errorf("metrics: %w", err)
+		}
+		util.Infof("Sending metrics to StatsD at %s", opts.Address)
+	}
+	l.opts = opts
+	if old := l.client.Swap(c); old != nil {
+		_ = old.Close()
+	}
+	return nil
+}
+
+func parseOptions(so *server.ServerOptions) (Options, error) {
+	opts := Options{}
+	if !so.Bool("metrics", "enabled", false) {
+		return opts, nil
+	}
+
+	opts.Address = so.String("metrics", "statsd", "localhost:8125")
+	opts.Prefix = so.String("metrics", "prefix", "faktory.")
+	opts.DogStatsD = so.Bool("metrics", "dogstatsd", true)
+	tags, ok := so.Config("metrics", "tags", []any{}).([]any)
+	if !ok {
+		return opts, fmt.Errorf("metrics: tags must be an array of strings")
+	}
+	for _, tag := range tags {
+		str, ok := tag.(string)
+		if !ok {
+			return opts, fmt.Errorf("metrics: invalid tag %v", tag)
+		}
+		opts.Tags = append(opts.Tags, str)
+	}
+	return opts, nil
+}
+
+func jobTags(job *client.Job) []string {
+	return []string{"queue:" + job.Queue, "jobtype:" + job.Type}
+}
+
+func helper(ctx context.Context) manager.Context {
+	mh, _ := ctx.Value(manager.MiddlewareHelperKey).(manager.Context)
+	return mh
+}
+
+func (l *Lifecycle) pushed(ctx context.Context, next func() error) error {
+	err := next()
+	c := l.client.Load()
+	if c == nil || err != nil {
+		return err
+	}
+	if mh := helper(ctx); mh != nil {
+		c.Count("jobs.pushed", 1, jobTags(mh.Job())...)
+	}
+	return nil
+}
+
+func (l *Lifecycle) fetched(ctx context.Context, next func() error) error {
+	err := next()
+	c := l.client.Load()
+	if c == nil || err != nil {
+		return err
+	}
+	if mh := helper(ctx); mh != nil {
+		job := mh.Job()
+		tags := jobTags(job)
+		c.Count("jobs.fetched", 1, tags...)
+		if tm, err := util.ParseTime(job.EnqueuedAt); err == nil {
+			c.Timing("jobs.latency", time.Since(tm), tags...)
+		}
+	}
+	return nil
+}
+
+func (l *Lifecycle) acked(ctx context.Context, next func() error) error {
+	l.finished(ctx, "jobs.succeeded")
+	return next()
+}
+
+func (l *Lifecycle) failed(ctx context.Context, next func() error) error {
+	l.finished(ctx, "jobs.failed")
+	return next()
+}
+
+func (l *Lifecycle) finished(ctx context.Context, name string) {
+	c := l.client.Load()
+	mh := helper(ctx)
+	if c == nil || mh == nil {
+		return
+	}
+	tags := jobTags(mh.Job())
+	c.Count(name, 1, tags...)
+	if res := mh.Reservation(); res != nil {
+		if since := res.ReservedAt(); !since.IsZero() {
+			c.Timing("jobs.perform", time.Since(since), tags...)
+		}
+	}
+}
+
+// gauges periodically reports the size of each namespace's queues and
+// sorted sets.
+type gauges struct {
+	l    *Lifecycle
+	s    *server.Server
+	sent int64
+}
+
+func (g *gauges) Name() string {
+	return "Metrics"
+}
+
+func (g *gauges) Execute(ctx context.Context) error {
+	c := g.l.client.Load()
+	if c == nil {
+		return nil
+	}
+
+	batch := c.Batch()
+	for _, ns := range g.s.Namespaces() {
+		var tags []string
+		if ns.Name != "" {
+			tags = []string{"namespace:" + ns.Name}
+		}
+		store := ns.Store()
+		store.EachQueue(ctx, func(q storage.Queue) {
+			batch.Gauge("queue.size", int64(q.Size(ctx)), append([]string{"queue:" + q.Name()}, tags...)...) // nolint:gosec
+		})
+		batch.Gauge("retries.size", int64(store.Retries().Size(ctx)), tags...)     // nolint:gosec
+		batch.Gauge("scheduled.size", int64(store.Scheduled().Size(ctx)), tags...) // nolint:gosec
+		batch.Gauge("dead.size", int64(store.Dead().Size(ctx)), tags...)           // nolint:gosec
+		batch.Gauge("working.size", int64(ns.Manager().WorkingCount()), tags...)
+	}
+	batch.Flush()
+	atomic.AddInt64(&g.sent, 1)
+	return nil
+}
+
+func (g *gauges) Stats(context.Context) map[string]any {
+	return map[string]any{
+		"enabled": g.l.client.Load() != nil,
+		"flushes": atomic.LoadInt64(&g.sent),
+	}
+}
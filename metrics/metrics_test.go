@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func listen(t *testing.T) (*net.UDPConn, func() string) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.NoError(t, err)
+	buf := make([]byte, 65536)
+	return conn, func() string {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		assert.NoError(t, err)
+		return string(buf[:n])
+	}
+}
+
+func TestClient(t *testing.T) {
+	conn, read := listen(t)
+	defer conn.Close()
+
+	c, err := NewClient(conn.LocalAddr().String(), "faktory.", []string{"env:test"}, true)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	c.Count("jobs.pushed", 1, "queue:default", "jobtype:Foo::Bar|Baz")
+	assert.Equal(t, "faktory.jobs.pushed:1|c|#env:test,queue:default,jobtype:Foo::Bar_Baz", read())
+
+	c.Timing("jobs.perform", 1500*time.Microsecond)
+	assert.Equal(t, "faktory.jobs.perform:1.500|ms|#env:test", read())
+
+	plain, err := NewClient(conn.LocalAddr().String(), "", []string{"env:test"}, false)
+	assert.NoError(t, err)
+	defer plain.Close()
+	plain.Gauge("dead.size", 12, "ignored:tag")
+	assert.Equal(t, "dead.size:12|g", read())
+
+	batch := plain.Batch()
+	for range 200 {
+		batch.Gauge("queue.size", 123456)
+	}
+	batch.Flush()
+	first := read()
+	assert.LessOrEqual(t, len(first), maxPacketSize)
+	lines := strings.Count(first, "\n") + 1
+	for lines < 200 {
+		lines += strings.Count(read(), "\n") + 1
+	}
+	assert.Equal(t, 200, lines)
+}
+
+type testHelper struct {
+	job *client.Job
+	res *manager.Reservation
+}
+
+func (th testHelper) Job() *client.Job                  { return th.job }
+func (th testHelper) Reservation() *manager.Reservation { return th.res }
+func (th testHelper) Manager() manager.Manager          { return nil }
+
+func TestMiddleware(t *testing.T) {
+	conn, read := listen(t)
+	defer conn.Close()
+
+	l := Subsystem()
+	job := client.NewJob("SomeJob", 1)
+	job.EnqueuedAt = util.Thens(time.Now().Add(-time.Second))
+	ctx := context.WithValue(context.Background(), manager.MiddlewareHelperKey, testHelper{
+		job: job,
+		res: &manager.Reservation{Job: job, Since: util.Thens(time.Now().Add(-2 * time.Second))},
+	})
+	next := func() error { return nil }
+
+	// disabled, nothing is sent
+	assert.NoError(t, l.pushed(ctx, next))
+
+	assert.NoError(t, l.connect(Options{Address: conn.LocalAddr().String(), DogStatsD: true}))
+	assert.NoError(t, l.pushed(ctx, next))
+	assert.Equal(t, "jobs.pushed:1|c|#queue:default,jobtype:SomeJob", read())
+
+	halt := manager.Halt("NOTUNIQUE", "job is not unique")
+	assert.Equal(t, halt, l.pushed(ctx, func() error { return halt }))
+
+	assert.NoError(t, l.fetched(ctx, next))
+	assert.Equal(t, "jobs.fetched:1|c|#queue:default,jobtype:SomeJob", read())
+	assert.True(t, strings.HasPrefix(read(), "jobs.latency:1"))
+
+	assert.NoError(t, l.acked(ctx, next))
+	assert.Equal(t, "jobs.succeeded:1|c|#queue:default,jobtype:SomeJob", read())
+	assert.True(t, strings.HasPrefix(read(), "jobs.perform:2"))
+
+	assert.NoError(t, l.failed(ctx, next))
+	assert.Equal(t, "jobs.failed:1|c|#queue:default,jobtype:SomeJob", read())
+}
+
+func TestGauges(t *testing.T) {
+	conn, read := listen(t)
+	defer conn.Close()
+
+	dir := t.TempDir()
+	s, err := server.NewServer(&server.ServerOptions{
+		GlobalConfig: map[string]any{
+			"namespaces": map[string]any{
+				"billing": map[string]any{"password": "billpass", "database": int64(1)},
+			},
+		},
+		Binding:          "localhost:0",
+		StorageDirectory: dir,
+		ConfigDirectory:  dir,
+		PoolSize:         server.DefaultMaxPoolSize,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.BootWithStore(storage.NewMemoryStore()))
+	defer s.Stop(nil)
+
+	bg := context.Background()
+	assert.NoError(t, s.Manager().Push(bg, client.NewJob("DefaultJob", 1)))
+	assert.NoError(t, s.Namespace("billing").Manager().Push(bg, client.NewJob("BillingJob", 2)))
+	assert.NoError(t, s.Namespace("billing").Manager().Push(bg, client.NewJob("BillingJob", 3)))
+
+	l := Subsystem()
+	assert.NoError(t, l.connect(Options{Address: conn.LocalAddr().String(), DogStatsD: true}))
+	g := &gauges{l: l, s: s}
+	assert.NoError(t, g.Execute(bg))
+
+	lines := strings.Split(read(), "\n")
+	assert.Contains(t, lines, "queue.size:1|g|#queue:default")
+	assert.Contains(t, lines, "dead.size:0|g")
+	assert.Contains(t, lines, "queue.size:2|g|#queue:default,namespace:billing")
+	assert.Contains(t, lines, "dead.size:0|g|#namespace:billing")
+}
+
+func TestParseOptions(t *testing.T) {
+	so := &server.ServerOptions{GlobalConfig: map[string]any{}}
+	opts, err := parseOptions(so)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{}, opts)
+
+	so.GlobalConfig["metrics"] = map[string]any{"enabled": true, "tags": []any{"env:prod"}}
+	opts, err = parseOptions(so)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Address: "localhost:8125", Prefix: "faktory.", Tags: []string{"env:prod"}, DogStatsD: true}, opts)
+
+	so.GlobalConfig["metrics"] = map[string]any{"enabled": true, "tags": []any{1}}
+	_, err = parseOptions(so)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Keep packets under the typical network MTU
+const maxPacketSize = 1432
+
+var tagEscaper = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_", " ", "_")
+
+// Client sends metrics to StatsD over UDP.  With DogStatsD enabled each
+// metric also carries the global and per-metric tags.  Sends are best
+// effort, a missing agent must never slow down or break Faktory.
+type Client struct {
+	conn      net.Conn
+	prefix    string
+	tags      []string
+	dogstatsd bool
+	mu        sync.Mutex
+}
+
+func NewClient(addr string, prefix string, tags []string, dogstatsd bool) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:      conn,
+		prefix:    prefix,
+		tags:      tags,
+		dogstatsd: dogstatsd,
+	}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) Count(name string, value int64, tags ...string) {
+	c.send(c.format(name, strconv.FormatInt(value, 10), "c", tags))
+}
+
+func (c *Client) Timing(name string, d time.Duration, tags ...string) {
+	ms := strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+	c.send(c.format(name, ms, "ms", tags))
+}
+
+func (c *Client) Gauge(name string, value int64, tags ...string) {
+	c.send(c.format(name, strconv.FormatInt(value, 10), "g", tags))
+}
+
+// A Batch collects metrics so they can be sent in as few packets as possible.
+type Batch struct {
+	c     *Client
+	lines [][]byte
+}
+
+func (c *Client) Batch() *Batch {
+	return &Batch{c: c}
+}
+
+func (b *Batch) Gauge(name string, value int64, tags ...string) {
+	b.lines = append(b.lines, b.c.format(name, strconv.FormatInt(value, 10), "g", tags))
+}
+
+func (b *Batch) Flush() {
+	var packet bytes.Buffer
+	for _, line := range b.lines {
+		if packet.Len() > 0 && packet.Len()+len(line)+1 > maxPacketSize {
+			b.c.send(packet.Bytes())
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.Write(line)
+	}
+	if packet.Len() > 0 {
+		b.c.send(packet.Bytes())
+	}
+	b.lines = nil
+}
+
+func (c *Client) format(name string, value string, kind string, tags []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(c.prefix)
+	buf.WriteString(name)
+	buf.WriteByte(':')
+	buf.WriteString(value)
+	buf.WriteByte('|')
+	buf.WriteString(kind)
+
+	if c.dogstatsd && len(c.tags)+len(tags) > 0 {
+		buf.WriteString("|#")
+		first := true
+		for _, list := range [][]string{c.tags, tags} {
+			for _, tag := range list {
+				if !first {
+					buf.WriteByte(',')
+				}
+				first = false
+				buf.WriteString(tagEscaper.Replace(tag))
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+func (c *Client) send(packet []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = c.conn.Write(packet)
+}
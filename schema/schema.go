@@ -0,0 +1,148 @@
+// Package schema validates the arguments and custom attributes of jobs
+// when they are pushed, so a producer sending bad data gets an error
+// rather than filling the dead set with jobs which can never succeed.
+//
+// Put a JSON Schema for each jobtype in conf.d/schemas, named after the
+// jobtype, e.g. conf.d/schemas/SendEmail.json:
+//
+//	{
+//	  "type": "object",
+//	  "properties": {
+//	    "args": {
+//	      "prefixItems": [{"type": "integer"}, {"type": "string", "pattern": "@"}],
+//	      "minItems": 2,
+//	      "maxItems": 2
+//	    },
+//	    "custom": {"required": ["tenant"]}
+//	  }
+//	}
+//
+// The schema validates an object holding the job's "args" and, if the
+// job has any, its "custom" attributes.  A push which doesn't match is
+// rejected with an INVALID error giving the path to the invalid value:
+//
+//	INVALID SendEmail: /args/0: expected integer but got string
+//
+// Only the keywords listed on Schema are supported; a schema using any
+// other keyword fails to load.  Jobtypes without a schema aren't
+// validated.  Schemas are reloaded on SIGHUP.
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/util"
+)
+
+type Lifecycle struct {
+	schemas atomic.Pointer[map[string]*Schema]
+}
+
+func Subsystem() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) Name() string {
+	return "Schemas"
+}
+
+func (l *Lifecycle) Start(s *server.Server) error {
+	schemas, err := Load(directory(s.Options))
+	if err != nil {
+		return err
+	}
+	l.configure(schemas)
+
+	// Middleware can't be removed so it is always installed and does
+	// nothing for jobtypes without a schema.
+	s.Manager().AddMiddleware("push", l.validate)
+	return nil
+}
+
+// Reload keeps the current schemas if any of the new ones are invalid.
+func (l *Lifecycle) Reload(s *server.Server) error {
+	schemas, err := Load(directory(s.Options))
+	if err != nil {
+		return err
+	}
+	l.configure(schemas)
+	return nil
+}
+
+func (l *Lifecycle) configure(schemas map[string]*Schema) {
+	if len(schemas) > 0 {
+		util.Infof("Validating pushes of %d jobtypes with JSON Schemas", len(schemas))
+	}
+	l.schemas.Store(&schemas)
+}
+
+func directory(opts *server.ServerOptions) string {
+	return filepath.Join(opts.ConfigDirectory, "conf.d", "schemas")
+}
+
+// Load parses the *.json files in dir, returning the schemas by jobtype.
+// A missing directory holds no schemas.
+func Load(dir string) (map[string]*Schema, error) {
+	schemas := map[string]*Schema{}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %w", file, err)
+		}
+		jobtype := strings.TrimSuffix(filepath.Base(file), ".json")
+		schemas[jobtype] = schema
+	}
+	return schemas, nil
+}
+
+func (l *Lifecycle) validate(ctx context.Context, next func() error) error {
+	mh, ok := ctx.Value(manager.MiddlewareHelperKey).(manager.Context)
+	if !ok {
+		return next()
+	}
+	job := mh.Job()
+	schema := (*l.schemas.Load())[job.Type]
+	if schema == nil {
+		return next()
+	}
+
+	doc, err := document(job)
+	if err != nil {
+		return err
+	}
+	if err := schema.Validate(doc); err != nil {
+		return manager.Halt("INVALID", fmt.Sprintf("%s: %v", job.Type, err))
+	}
+	return next()
+}
+
+// document converts the job's args and custom attributes to the
+// decoded JSON which is validated.
+func document(job *client.Job) (any, error) {
+	fields := map[string]any{"args": job.Args}
+	if job.Custom != nil {
+		fields["custom"] = job.Custom
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal job payload: %w", err)
+	}
+	return decode(data)
+}
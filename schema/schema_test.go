@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/faktorytest"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSchema(t *testing.T, dir string, jobtype string, schema string) {
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "conf.d", "schemas"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "conf.d", "schemas", jobtype+".json"), []byte(schema), 0o644))
+}
+
+func TestSchemas(t *testing.T) {
+	srv := faktorytest.Start(t)
+	dir := t.TempDir()
+	srv.Options.ConfigDirectory = dir
+	writeSchema(t, dir, "SendEmail", `{
+		"properties": {
+			"args": {"prefixItems": [{"type": "integer"}], "minItems": 1},
+			"custom": {"required": ["tenant"]}
+		}
+	}`)
+
+	l := Subsystem()
+	assert.NoError(t, l.Start(srv.Server))
+	cl := srv.Client()
+
+	assert.NoError(t, cl.Push(client.NewJob("SendEmail", 123)))
+	assert.NoError(t, cl.Push(client.NewJob("Unvalidated", "abc")))
+
+	err := cl.Push(client.NewJob("SendEmail", "123"))
+	assert.Error(t, err)
+	assert.Equal(t, "INVALID SendEmail: /args/0: expected integer but got string", err.Error())
+	assert.IsType(t, &client.ProtocolError{}, err)
+
+	job := client.NewJob("SendEmail", 123)
+	job.SetCustom("region", "us")
+	err = cl.Push(job)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `/custom: missing required property "tenant"`)
+	assert.Len(t, srv.JobsOfType("SendEmail"), 1)
+
+	t.Run("Reload", func(t *testing.T) {
+		// an invalid schema keeps the current ones
+		writeSchema(t, dir, "Broken", `{"type": "int"}`)
+		assert.Error(t, l.Reload(srv.Server))
+		assert.Error(t, cl.Push(client.NewJob("SendEmail", "123")))
+
+		assert.NoError(t, os.Remove(filepath.Join(dir, "conf.d", "schemas", "Broken.json")))
+		writeSchema(t, dir, "SendEmail", `{"properties": {"args": {"items": {"type": "string"}}}}`)
+		assert.NoError(t, l.Reload(srv.Server))
+		assert.NoError(t, cl.Push(client.NewJob("SendEmail", "123")))
+		assert.Error(t, cl.Push(client.NewJob("SendEmail", 123)))
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		schemas, err := Load(filepath.Join(dir, "missing"))
+		assert.NoError(t, err)
+		assert.Len(t, schemas, 0)
+	})
+}
+
+func TestAdmission(t *testing.T) {
+	dir := t.TempDir()
+	writeSchema(t, dir, "SendEmail", `{"properties": {"args": {"prefixItems": [{"type": "integer"}]}}}`)
+	s, err := server.NewServer(&server.ServerOptions{
+		GlobalConfig: map[string]any{
+			"limits": map[string]any{"enabled": true, "push_rate": int64(1), "push_burst": int64(1)},
+		},
+		Binding:          "localhost:0",
+		StorageDirectory: dir,
+		ConfigDirectory:  dir,
+		PoolSize:         server.DefaultMaxPoolSize,
+	})
+	assert.NoError(t, err)
+	s.Register(Subsystem())
+	assert.NoError(t, s.BootWithStore(storage.NewMemoryStore()))
+	go func() {
+		_ = s.Run()
+	}()
+	defer func() {
+		s.Shutdown()
+		s.Stop(nil)
+	}()
+
+	srv := client.DefaultServer()
+	srv.Address = s.Addr().String()
+	cl, err := client.Dial(srv, "")
+	assert.NoError(t, err)
+	defer cl.Close()
+
+	// invalid jobs are rejected before they use up the rate limit
+	for range 3 {
+		err := cl.Push(client.NewJob("SendEmail", "123"))
+		assert.ErrorContains(t, err, "INVALID")
+	}
+	assert.NoError(t, cl.Push(client.NewJob("SendEmail", 123)))
+	err = cl.Push(client.NewJob("SendEmail", 123))
+	assert.ErrorContains(t, err, "OVERLOAD")
+}
@@ -0,0 +1,448 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Schema is a compiled JSON Schema.  Only the subset of keywords
+// needed to check the shape of job payloads is supported:
+//
+//	type, enum, const
+//	minimum, maximum
+//	minLength, maxLength, pattern
+//	prefixItems, items, minItems, maxItems
+//	properties, required, additionalProperties
+//
+// The annotations $schema, $id, $comment, title, description, default
+// and examples are ignored.  Any other keyword, e.g. $ref, anyOf or
+// format, is rejected when the schema is loaded rather than silently
+// not checked.  Patterns use Go's regexp syntax.
+type Schema struct {
+	// set for the boolean schemas true and false
+	always *bool
+
+	types    []string
+	enum     []any
+	constant any
+	hasConst bool
+
+	minimum *float64
+	maximum *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	prefixItems []*Schema
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+}
+
+// A ValidationError describes the first part of a document which
+// doesn't match the schema.  Path is a JSON Pointer to the invalid
+// value, e.g. "/args/0".
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (ve *ValidationError) Error() string {
+	path := ve.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, ve.Message)
+}
+
+var (
+	typeNames   = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+	annotations = []string{"$schema", "$id", "$comment", "title", "description", "default", "examples"}
+)
+
+// Parse compiles the JSON Schema document.
+func Parse(data []byte) (*Schema, error) {
+	doc, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return compile(doc, "#")
+}
+
+// decode parses JSON keeping numbers exact so large integers aren't
+// rounded.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// Validate checks the value, which must be decoded JSON, against the
+// schema.  It returns a *ValidationError for the first mismatch.
+func (s *Schema) Validate(value any) error {
+	return s.validate(value, "")
+}
+
+func compile(value any, loc string) (*Schema, error) {
+	s := &Schema{}
+	if b, ok := value.(bool); ok {
+		s.always = &b
+		return s, nil
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: a schema must be an object or boolean", loc)
+	}
+
+	var err error
+	for _, keyword := range slices.Sorted(maps.Keys(obj)) {
+		arg := obj[keyword]
+		at := loc + "/" + escape(keyword)
+		switch keyword {
+		case "type":
+			if name, ok := arg.(string); ok {
+				s.types = []string{name}
+			} else {
+				s.types, err = stringArray(arg, at)
+			}
+			if err == nil {
+				for _, name := range s.types {
+					if !slices.Contains(typeNames, name) {
+						err = fmt.Errorf("%s: unknown type %q", at, name)
+					}
+				}
+			}
+		case "enum":
+			s.enum, ok = arg.([]any)
+			if !ok {
+				err = fmt.Errorf("%s must be an array", at)
+			}
+		case "const":
+			s.constant = arg
+			s.hasConst = true
+		case "minimum":
+			s.minimum, err = number(arg, at)
+		case "maximum":
+			s.maximum, err = number(arg, at)
+		case "minLength":
+			s.minLength, err = count(arg, at)
+		case "maxLength":
+			s.maxLength, err = count(arg, at)
+		case "pattern":
+			s.pattern, err = pattern(arg, at)
+		case "prefixItems":
+			list, ok := arg.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an array of schemas", at)
+			}
+			s.prefixItems = make([]*Schema, len(list))
+			for idx, item := range list {
+				s.prefixItems[idx], err = compile(item, fmt.Sprintf("%s/%d", at, idx))
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "items":
+			s.items, err = compile(arg, at)
+		case "minItems":
+			s.minItems, err = count(arg, at)
+		case "maxItems":
+			s.maxItems, err = count(arg, at)
+		case "properties":
+			props, ok := arg.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s must be an object", at)
+			}
+			s.properties = map[string]*Schema{}
+			for name, prop := range props {
+				s.properties[name], err = compile(prop, at+"/"+escape(name))
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = stringArray(arg, at)
+		case "additionalProperties":
+			s.additionalProperties, err = compile(arg, at)
+		default:
+			if !slices.Contains(annotations, keyword) {
+				err = fmt.Errorf("%s: unsupported keyword", at)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func stringArray(arg any, at string) ([]string, error) {
+	list, ok := arg.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", at)
+	}
+	strs := make([]string, len(list))
+	for idx, val := range list {
+		strs[idx], ok = val.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings", at)
+		}
+	}
+	return strs, nil
+}
+
+func number(arg any, at string) (*float64, error) {
+	num, ok := arg.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a number", at)
+	}
+	f, err := num.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", at, err)
+	}
+	return &f, nil
+}
+
+func count(arg any, at string) (*int, error) {
+	f, err := number(arg, at)
+	if err != nil {
+		return nil, err
+	}
+	if *f < 0 || *f != math.Trunc(*f) {
+		return nil, fmt.Errorf("%s must be a non-negative integer", at)
+	}
+	n := int(*f)
+	return &n, nil
+}
+
+func pattern(arg any, at string) (*regexp.Regexp, error) {
+	str, ok := arg.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a string", at)
+	}
+	rx, err := regexp.Compile(str)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", at, err)
+	}
+	return rx, nil
+}
+
+func (s *Schema) fail(path string, format string, args ...any) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Schema) validate(value any, path string) error {
+	if s.always != nil {
+		if !*s.always {
+			return s.fail(path, "not allowed")
+		}
+		return nil
+	}
+
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(name string) bool { return isType(value, name) }) {
+		return s.fail(path, "expected %s but got %s", strings.Join(s.types, " or "), typeOf(value))
+	}
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(v any) bool { return equal(v, value) }) {
+		return s.fail(path, "must be one of %s", jsonString(s.enum))
+	}
+	if s.hasConst && !equal(s.constant, value) {
+		return s.fail(path, "must be %s", jsonString(s.constant))
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		return s.validateNumber(v, path)
+	case string:
+		return s.validateString(v, path)
+	case []any:
+		return s.validateArray(v, path)
+	case map[string]any:
+		return s.validateObject(v, path)
+	}
+	return nil
+}
+
+func (s *Schema) validateNumber(num json.Number, path string) error {
+	f, err := num.Float64()
+	if err != nil {
+		return s.fail(path, "invalid number %s", num)
+	}
+	if s.minimum != nil && f < *s.minimum {
+		return s.fail(path, "must be at least %v", *s.minimum)
+	}
+	if s.maximum != nil && f > *s.maximum {
+		return s.fail(path, "must be at most %v", *s.maximum)
+	}
+	return nil
+}
+
+func (s *Schema) validateString(str string, path string) error {
+	length := utf8.RuneCountInString(str)
+	if s.minLength != nil && length < *s.minLength {
+		return s.fail(path, "must be at least %d characters", *s.minLength)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		return s.fail(path, "must be at most %d characters", *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		return s.fail(path, "must match %s", s.pattern)
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(list []any, path string) error {
+	if s.minItems != nil && len(list) < *s.minItems {
+		return s.fail(path, "must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(list) > *s.maxItems {
+		return s.fail(path, "must have at most %d items", *s.maxItems)
+	}
+	for idx, item := range list {
+		sub := s.items
+		if idx < len(s.prefixItems) {
+			sub = s.prefixItems[idx]
+		}
+		if sub == nil {
+			continue
+		}
+		if err := sub.validate(item, path+"/"+strconv.Itoa(idx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(obj map[string]any, path string) error {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			return s.fail(path, "missing required property %q", name)
+		}
+	}
+
+	// sorted so the same document always reports the same error
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		propPath := path + "/" + escape(name)
+		sub, ok := s.properties[name]
+		if !ok {
+			sub = s.additionalProperties
+		}
+		if sub == nil {
+			continue
+		}
+		if !ok && sub.always != nil && !*sub.always {
+			return s.fail(propPath, "unknown property")
+		}
+		if err := sub.validate(obj[name], propPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isType(value any, name string) bool {
+	switch name {
+	case "integer":
+		num, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := num.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeOf(value) == name
+	}
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// equal compares decoded JSON values, treating numbers as equal if
+// they have the same value, e.g. 1 and 1.0.
+func equal(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		fx, errx := x.Float64()
+		fy, erry := y.Float64()
+		return errx == nil && erry == nil && fx == fy
+	case []any:
+		y, ok := b.([]any)
+		return ok && slices.EqualFunc(x, y, equal)
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, xv := range x {
+			yv, ok := y[key]
+			if !ok || !equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func jsonString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		schema string
+		doc    string
+		err    string
+	}{
+		{`true`, `{"any": "thing"}`, ""},
+		{`false`, `1`, "/: not allowed"},
+		{`{"type": "integer"}`, `3`, ""},
+		{`{"type": "integer"}`, `3.0`, ""},
+		{`{"type": "integer"}`, `3.5`, "/: expected integer but got number"},
+		{`{"type": "integer"}`, `"3"`, "/: expected integer but got string"},
+		{`{"type": ["string", "null"]}`, `null`, ""},
+		{`{"type": ["string", "null"]}`, `true`, "/: expected string or null but got boolean"},
+		{`{"enum": ["a", 1]}`, `1.0`, ""},
+		{`{"enum": ["a", 1]}`, `"b"`, `/: must be one of ["a",1]`},
+		{`{"const": {"a": [1]}}`, `{"a": [1]}`, ""},
+		{`{"const": {"a": [1]}}`, `{"a": [2]}`, `/: must be {"a":[1]}`},
+		{`{"maxLength": 2}`, `"éé"`, ""},
+		{`{"maxLength": 2}`, `"abc"`, "/: must be at most 2 characters"},
+		{`{"pattern": "^[a-z]+@"}`, `"mike@example.com"`, ""},
+		{`{"pattern": "^[a-z]+@"}`, `"nope"`, "/: must match ^[a-z]+@"},
+		{`{"minimum": 1, "maximum": 10}`, `11`, "/: must be at most 10"},
+		{`{"minimum": 1, "maximum": 10}`, `0`, "/: must be at least 1"},
+		{`{"items": {"type": "string"}}`, `["a", 2]`, "/1: expected string but got number"},
+		{`{"prefixItems": [{"type": "integer"}], "items": false}`, `[1, 2]`, "/1: not allowed"},
+		{`{"minItems": 1}`, `[]`, "/: must have at least 1 items"},
+		{`{"required": ["id"]}`, `{}`, `/: missing required property "id"`},
+		{`{"properties": {"a/b": {"type": "string"}}}`, `{"a/b": 1}`, "/a~1b: expected string but got number"},
+		{`{"properties": {"id": true}, "additionalProperties": false}`, `{"id": 1, "x": 2}`, "/x: unknown property"},
+		{`{"additionalProperties": {"type": "string"}}`, `{"a": "b", "c": 1}`, "/c: expected string but got number"},
+		{`{"title": "Email", "description": "an address", "type": "string"}`, `"mike@example.com"`, ""},
+	}
+
+	for _, tc := range cases {
+		s, err := Parse([]byte(tc.schema))
+		assert.NoError(t, err, tc.schema)
+		if err != nil {
+			continue
+		}
+		doc, err := decode([]byte(tc.doc))
+		assert.NoError(t, err, tc.doc)
+
+		err = s.Validate(doc)
+		if tc.err == "" {
+			assert.NoError(t, err, "%s %s", tc.schema, tc.doc)
+		} else if assert.Error(t, err, "%s %s", tc.schema, tc.doc) {
+			assert.Equal(t, tc.err, err.Error(), tc.schema)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		`{`:                                    "invalid JSON: unexpected EOF",
+		`{} {}`:                                "invalid JSON: unexpected data after the JSON value",
+		`[]`:                                   "#: a schema must be an object or boolean",
+		`{"type": "int"}`:                      `#/type: unknown type "int"`,
+		`{"minLength": -1}`:                    "#/minLength must be a non-negative integer",
+		`{"pattern": "("}`:                     "#/pattern: error parsing regexp: missing closing ): `(`",
+		`{"properties": {"a": 1}}`:             "#/properties/a: a schema must be an object or boolean",
+		`{"required": ["a", 1]}`:               "#/required must be an array of strings",
+		`{"minimum": true}`:                    "#/minimum must be a number",
+		`{"items": [{"type": "integer"}]}`:     "#/items: a schema must be an object or boolean",
+		`{"format": "email"}`:                  "#/format: unsupported keyword",
+		`{"$ref": "#/$defs/id"}`:               "#/$ref: unsupported keyword",
+		`{"properties": {"a": {"anyOf": []}}}`: "#/properties/a/anyOf: unsupported keyword",
+	}
+	for schema, msg := range cases {
+		_, err := Parse([]byte(schema))
+		if assert.Error(t, err, schema) {
+			assert.Equal(t, msg, err.Error(), schema)
+		}
+	}
+}
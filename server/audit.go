@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// The audit log records destructive administrative actions, like FLUSH
+// or clearing the Dead set, along with who performed them.  It is
+// disabled by default:
+//
+//	[audit]
+//	enabled = true
+//	max_size = 10000 # entries kept in Redis
+//	file = "/var/log/faktory/audit.log"
+type auditLog struct {
+	mu      sync.Mutex
+	enabled bool
+	maxSize int64
+	path    string
+	file    *util.RotatingFile
+}
+
+func (s *Server) configureAudit() {
+	al := &s.audit
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	al.enabled = s.Options.Bool("audit", "enabled", false)
+	al.maxSize = int64(s.Options.Int("audit", "max_size", 10000))
+
+	path := ""
+	if al.enabled {
+		path = s.Options.String("audit", "file", "")
+	}
+	if path == al.path {
+		return
+	}
+
+	if al.file != nil {
+		_ = al.file.Close()
+		al.file = nil
+	}
+	al.path = path
+	if path != "" {
+		file, err := util.OpenRotatingFile(path, 100*1024*1024, 5)
+		if err != nil {
+			util.Errorw("Unable to open audit log file", err, util.Fields{"file": path})
+			return
+		}
+		al.file = file
+	}
+}
+
+func (al *auditLog) close() {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if al.file != nil {
+		_ = al.file.Close()
+		al.file = nil
+	}
+	al.path = ""
+}
+
+// AuditEnabled returns true if administrative actions are being recorded.
+func (s *Server) AuditEnabled() bool {
+	s.audit.mu.Lock()
+	defer s.audit.mu.Unlock()
+	return s.audit.enabled
+}
+
+// Audit records an administrative action in the audit log.  Failure
+// to record the entry is logged but does not fail the action itself.
+func (s *Server) Audit(ctx context.Context, entry storage.AuditEntry) {
+	al := &s.audit
+	al.mu.Lock()
+	enabled, maxSize := al.enabled, al.maxSize
+	al.mu.Unlock()
+	if !enabled {
+		return
+	}
+
+	if entry.At.IsZero() {
+		entry.At = time.Now().UTC()
+	}
+	fields := util.Fields{"actor": entry.Actor, "command": entry.Command, "target": entry.Target, "count": entry.Count}
+
+	// a slow Redis mustn't hold up other audited actions
+	err := s.store.RecordAudit(ctx, entry, maxSize)
+	if err != nil {
+		util.Errorw("Unable to record audit entry", err, fields)
+	}
+
+	// the lock keeps the file open and its lines whole
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if al.file != nil {
+		data, err := json.Marshal(entry)
+		if err == nil {
+			_, err = al.file.Write(append(data, '\n'))
+		}
+		if err != nil {
+			util.Errorw("Unable to write audit log file", err, fields)
+		}
+	}
+}
+
+func (c *Connection) audit(s *Server, command string, target string, count uint64) {
+	// the audit log is kept in the default namespace
+	if c.client != nil && c.client.Namespace != "" {
+		target = strings.TrimSuffix(c.client.Namespace+"/"+target, "/")
+	}
+	s.Audit(c.Context, storage.AuditEntry{
+		Actor:   c.client.actor(),
+		Remote:  c.remoteAddr,
+		Command: command,
+		Target:  target,
+		Count:   count,
+	})
+}
+
+// actor identifies the client as "username@hostname", falling
+// back to the hostname for connections without a username.
+func (cd *ClientData) actor() string {
+	if cd == nil {
+		return ""
+	}
+	if cd.Username == "" {
+		return cd.Hostname
+	}
+	if cd.Hostname == "" {
+		return cd.Username
+	}
+	return cd.Username + "@" + cd.Hostname
+}
+
+// totalJobs counts the jobs in all queues and sets.
+func totalJobs(ctx context.Context, store storage.Store) uint64 {
+	count := store.Retries().Size(ctx) + store.Scheduled().Size(ctx) + store.Dead().Size(ctx) + store.Working().Size(ctx)
+	store.EachQueue(ctx, func(q storage.Queue) {
+		count += q.Size(ctx)
+	})
+	return count
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientActor(t *testing.T) {
+	assert.Equal(t, "foobar.example.com", (&ClientData{Hostname: "foobar.example.com"}).actor())
+	assert.Equal(t, "mike@foobar.example.com", (&ClientData{Hostname: "foobar.example.com", Username: "mike"}).actor())
+	assert.Equal(t, "mike", (&ClientData{Username: "mike"}).actor())
+	var cd *ClientData
+	assert.Equal(t, "", cd.actor())
+}
+
+func TestAuditLog(t *testing.T) {
+	runServer("localhost:7423", func(s *Server) {
+		bg := context.Background()
+		logfile := filepath.Join(t.TempDir(), "audit.log")
+		s.Options.GlobalConfig = map[string]any{
+			"audit": map[string]any{"enabled": true, "max_size": int64(2), "file": logfile},
+		}
+		s.Reload()
+		assert.True(t, s.AuditEnabled())
+
+		cl, err := client.Dial(&client.Server{Network: "tcp", Address: "localhost:7423", Timeout: time.Second}, "")
+		assert.NoError(t, err)
+		defer cl.Close()
+
+		assert.NoError(t, cl.Push(client.NewJob("AuditJob", 1)))
+		assert.NoError(t, cl.Push(client.NewJob("AuditJob", 2)))
+		assert.NoError(t, cl.Flush())
+
+		assert.NoError(t, cl.Push(client.NewJob("AuditJob", 3)))
+		assert.NoError(t, cl.RemoveQueues("default"))
+		assert.NoError(t, cl.Clear(client.Retries))
+
+		entries, err := s.Store().AuditLog(bg, 0, 10)
+		assert.NoError(t, err)
+		// max_size caps the log at two entries, newest first
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "MUTATE clear", entries[0].Command)
+		assert.Equal(t, "retries", entries[0].Target)
+		assert.Equal(t, "QUEUE REMOVE", entries[1].Command)
+		assert.Equal(t, "default", entries[1].Target)
+		assert.EqualValues(t, 1, entries[1].Count)
+		assert.NotEmpty(t, entries[1].Remote)
+		assert.False(t, entries[1].At.IsZero())
+
+		data, err := os.ReadFile(logfile)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		assert.Len(t, lines, 3)
+		assert.Contains(t, lines[0], `"command":"FLUSH","count":2`)
+
+		s.Options.GlobalConfig = map[string]any{}
+		s.Reload()
+		assert.False(t, s.AuditEnabled())
+		assert.NoError(t, cl.Clear(client.Dead))
+		size := s.Store().AuditSize(bg)
+		assert.EqualValues(t, 2, size)
+	})
+}
+
+func TestAuditLogSurvivesFlush(t *testing.T) {
+	config := map[string]any{
+		"audit": map[string]any{"enabled": true},
+	}
+	runMemoryServer(t, config, func(s *Server, cl *client.Client) {
+		bg := context.Background()
+		assert.NoError(t, cl.Push(client.NewJob("AuditJob", 1)))
+		assert.NoError(t, cl.RemoveQueues("default"))
+		assert.NoError(t, cl.Flush())
+		assert.NoError(t, cl.Flush())
+
+		entries, err := s.Store().AuditLog(bg, 0, 10)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 3)
+		assert.Equal(t, "FLUSH", entries[0].Command)
+		assert.Equal(t, "FLUSH", entries[1].Command)
+		assert.Equal(t, "QUEUE REMOVE", entries[2].Command)
+	})
+}
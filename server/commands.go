@@ -46,28 +46,43 @@ func batch(c *Connection, s *Server, cmd string) {
 // QUEUE PAUSE foo bar baz
 // QUEUE RESUME *
 // QUEUE REMOVE [names...]
+// QUEUE MOVE src dst [{filter}]
 func queue(c *Connection, s *Server, cmd string) {
 	qs := strings.Split(cmd, " ")[1:]
 	subcmd := strings.ToUpper(qs[0])
 	ctx := c.Context
-	m := s.Manager()
+	ns := s.namespaceFor(c)
+	m := ns.manager
 	var op func(ctx context.Context, qName string) error
 
 	switch subcmd {
 	case "LATENCY":
 		queueLatency(c, s, cmd, qs[1:])
 		return
+	case "MOVE":
+		queueMove(c, s, cmd)
+		return
 	case "PAUSE":
 		op = m.PauseQueue
 	case "RESUME":
 		op = m.ResumeQueue
 	case "REMOVE":
-		op = m.RemoveQueue
+		op = func(ctx context.Context, name string) error {
+			var count uint64
+			if q, ok := ns.store.ExistingQueue(ctx, name); ok {
+				count = q.Size(ctx)
+			}
+			err := m.RemoveQueue(ctx, name)
+			if err == nil {
+				c.audit(s, "QUEUE REMOVE", name, count)
+			}
+			return err
+		}
 	}
 
 	if op != nil {
 		if qs[1] == "*" {
-			s.Store().EachQueue(ctx, func(q storage.Queue) {
+			ns.store.EachQueue(ctx, func(q storage.Queue) {
 				_ = op(ctx, q.Name())
 			})
 		} else {
@@ -83,13 +98,61 @@ func queue(c *Connection, s *Server, cmd string) {
 	_ = c.Ok()
 }
 
+// QUEUE MOVE gives up after this long, a little less than the Go
+// client's MoveTimeout so the client still hears how many jobs were
+// moved.
+var moveTimeout = 4 * time.Minute
+
+func queueMove(c *Connection, s *Server, cmd string) {
+	// the filter is JSON which may contain spaces
+	parts := strings.SplitN(cmd, " ", 5)
+	if len(parts) < 4 {
+		_ = c.Error(cmd, fmt.Errorf("QUEUE MOVE requires a source and destination queue"))
+		return
+	}
+	src, dst := parts[2], parts[3]
+
+	var filter *client.JobFilter
+	if len(parts) == 5 {
+		filter = &client.JobFilter{}
+		err := util.JsonUnmarshal([]byte(parts[4]), filter)
+		if err != nil {
+			_ = c.Error(cmd, fmt.Errorf("invalid filter: %w", err))
+			return
+		}
+	}
+
+	// moving a large queue can take longer than the usual command
+	// timeout but shouldn't outlive the client waiting for the answer
+	// or hold up shutdown
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Context), moveTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.Stopper():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	count, err := s.namespaceFor(c).MoveQueue(ctx, src, dst, filter)
+	if count > 0 {
+		c.audit(s, "QUEUE MOVE", src+" to "+dst, count)
+	}
+	if err != nil {
+		_ = c.Error(cmd, fmt.Errorf("QUEUE: %w", err))
+		return
+	}
+	_ = c.Number(int(count)) // nolint:gosec
+}
+
 func queueLatency(c *Connection, s *Server, cmd string, names []string) {
 	if len(names) == 1 && names[0] == "*" {
 		_ = c.Error(cmd, fmt.Errorf("QUEUE LATENCY does not support wildcards"))
 		return
 	}
 
-	times, err := gatherLatencies(c.Context, names, s.Store())
+	times, err := gatherLatencies(c.Context, names, s.namespaceFor(c).store)
 	if err != nil {
 		_ = c.Error(cmd, fmt.Errorf("QUEUE: %w", err))
 		return
@@ -103,23 +166,22 @@ func queueLatency(c *Connection, s *Server, cmd string, names []string) {
 	_ = c.Result(res)
 }
 
+// QueueLatencies returns the age in seconds of the oldest job
+// in each of the given queues.
+func (s *Server) QueueLatencies(ctx context.Context, names []string) (map[string]float64, error) {
+	return gatherLatencies(ctx, names, s.Store())
+}
+
 func gatherLatencies(ctx context.Context, qs []string, store storage.Store) (map[string]float64, error) {
-	queueCmd := map[string]*redis.StringCmd{}
-	_, err := store.Redis().Pipelined(ctx, func(pipe redis.Pipeliner) error {
-		for _, q := range qs {
-			queueCmd[q] = pipe.LIndex(ctx, "q:"+q, -1)
-		}
-		return nil
-	})
-	if err != nil && err != redis.Nil {
-		util.Error("Unable to gather queue latencies", err)
+	payloads, err := oldestPayloads(ctx, qs, store)
+	if err != nil {
+		util.Errorw("Unable to gather queue latencies", err, util.Fields{"queues": qs})
 		return nil, err
 	}
 
 	result := map[string]float64{}
-	for name, lindex := range queueCmd {
+	for name, payload := range payloads {
 		latency := 0.0
-		payload := lindex.Val()
 		if payload != "" {
 			var job client.Job
 			err := json.Unmarshal([]byte(payload), &job)
@@ -141,18 +203,62 @@ func gatherLatencies(ctx context.Context, qs []string, store storage.Store) (map
 	return result, nil
 }
 
+// oldestPayloads returns the next job to be fetched from each queue,
+// or "" if the queue is empty.
+func oldestPayloads(ctx context.Context, qs []string, store storage.Store) (map[string]string, error) {
+	payloads := map[string]string{}
+	if store.Redis() == nil {
+		for _, name := range qs {
+			payloads[name] = ""
+			q, ok := store.ExistingQueue(ctx, name)
+			if !ok {
+				continue
+			}
+			err := q.Page(ctx, -1, 0, func(_ int, data []byte) error {
+				payloads[name] = string(data)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return payloads, nil
+	}
+
+	queueCmd := map[string]*redis.StringCmd{}
+	_, err := store.Redis().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, q := range qs {
+			queueCmd[q] = pipe.LIndex(ctx, "q:"+q, -1)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	for name, lindex := range queueCmd {
+		payloads[name] = lindex.Val()
+	}
+	return payloads, nil
+}
+
 // FLUSH
 func flush(c *Connection, s *Server, cmd string) {
+	ns := s.namespaceFor(c)
+	fields := util.Fields{"namespace": ns.Name, "remote": c.remoteAddr}
 	if s.Options.Environment == "development" {
-		util.Info("Flushing dataset")
+		util.Infow("Flushing dataset", fields)
 	} else {
-		util.Warn("Flushing dataset")
+		util.Warnw("Flushing dataset", fields)
 	}
-	err := s.store.Flush(c.Context)
+	store := ns.store
+	count := totalJobs(c.Context, store)
+	err := store.Flush(c.Context)
 	if err != nil {
 		_ = c.Error(cmd, err)
 		return
 	}
+	// Flush keeps the audit log, this entry joins the earlier ones
+	c.audit(s, "FLUSH", "", count)
 
 	_ = c.Ok()
 }
@@ -175,6 +281,7 @@ func pushBulk(c *Connection, s *Server, cmd string) {
 
 	result := map[string]string{}
 	ts := util.Nows()
+	m := s.namespaceFor(c).manager
 
 	for idx := range jobs {
 		job := jobs[idx]
@@ -188,7 +295,7 @@ func pushBulk(c *Connection, s *Server, cmd string) {
 		}
 		// TODO we aren't optimizing the roundtrips to Redis yet
 		// We need a new `manager.PushBulk` API
-		err = s.manager.Push(c.Context, &job)
+		err = m.Push(c.Context, &job)
 		if err != nil {
 			result[job.Jid] = err.Error()
 		}
@@ -224,7 +331,7 @@ func push(c *Connection, s *Server, cmd string) {
 		job.Retry = &client.RetryPolicyDefault
 	}
 
-	err = s.manager.Push(c.Context, &job)
+	err = s.namespaceFor(c).manager.Push(c.Context, &job)
 	if err != nil {
 		_ = c.Error(cmd, err)
 		return
@@ -246,12 +353,18 @@ func fetch(c *Connection, s *Server, cmd string) {
 	defer cancel()
 
 	qs := strings.Split(cmd, " ")[1:]
-	job, err := s.manager.Fetch(ctx, c.client.Wid, qs...)
+	mgr := s.namespaceFor(c).manager
+	job, err := mgr.Fetch(ctx, c.client.Wid, qs...)
 	if err != nil {
 		_ = c.Error(cmd, err)
 		return
 	}
 	if job != nil {
+		job, err = s.Deliver(ctx, mgr, job)
+		if err != nil {
+			_ = c.Error(cmd, err)
+			return
+		}
 		res, err := json.Marshal(job)
 		if err != nil {
 			_ = c.Error(cmd, err)
@@ -278,7 +391,7 @@ func ack(c *Connection, s *Server, cmd string) {
 		_ = c.Error(cmd, fmt.Errorf("invalid ACK %s", data))
 		return
 	}
-	_, err = s.manager.Acknowledge(c.Context, jid)
+	_, err = s.namespaceFor(c).manager.Acknowledge(c.Context, jid)
 	if err != nil {
 		_ = c.Error(cmd, err)
 		return
@@ -298,7 +411,7 @@ func fail(c *Connection, s *Server, cmd string) {
 		return
 	}
 
-	err = s.manager.Fail(c.Context, &failure)
+	err = s.namespaceFor(c).manager.Fail(c.Context, &failure)
 	if err != nil {
 		_ = c.Error(cmd, err)
 		return
@@ -308,7 +421,7 @@ func fail(c *Connection, s *Server, cmd string) {
 
 // INFO
 func info(c *Connection, s *Server, cmd string) {
-	data, err := s.CurrentState()
+	data, err := s.namespaceFor(c).CurrentState()
 	if err != nil {
 		_ = c.Error(cmd, err)
 		return
@@ -15,6 +15,8 @@ type ServerOptions struct {
 	Environment      string
 	Password         string //gosec:disable
 	PoolSize         uint64
+	// RedisURL points to an external Redis used instead of RedisSock.
+	RedisURL string
 }
 
 func (so *ServerOptions) String(subsys string, key string, defval string) string {
@@ -27,6 +29,29 @@ func (so *ServerOptions) String(subsys string, key string, defval string) string
 	return str
 }
 
+func (so *ServerOptions) Int(subsys string, key string, defval int) int {
+	val := so.Config(subsys, key, defval)
+	switch num := val.(type) {
+	case int:
+		return num
+	case int64:
+		return int(num)
+	default:
+		util.Warnf("Config error: %s/%s is not an Integer", subsys, key)
+		return defval
+	}
+}
+
+func (so *ServerOptions) Bool(subsys string, key string, defval bool) bool {
+	val := so.Config(subsys, key, defval)
+	b, ok := val.(bool)
+	if !ok {
+		util.Warnf("Config error: %s/%s is not a Boolean", subsys, key)
+		return defval
+	}
+	return b
+}
+
 func (so *ServerOptions) Config(subsys string, key string, defval any) any {
 	mapp, ok := so.GlobalConfig[subsys]
 	if !ok {
@@ -17,9 +17,12 @@ import (
 // Shout out to antirez for his nice design document on it.
 // https://redis.io/topics/protocol
 type Connection struct {
-	client *ClientData
-	conn   io.WriteCloser
-	buf    *bufio.Reader
+	client     *ClientData
+	conn       io.WriteCloser
+	buf        *bufio.Reader
+	remoteAddr string
+	// admission control's push rate for this connection
+	pushes tokenBucket
 	context.Context
 }
 
@@ -0,0 +1,303 @@
+package server
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/util"
+)
+
+// Job args and custom attributes can be encrypted at rest so they don't
+// appear in plaintext in Redis, its RDB files or the Web UI:
+//
+//	[encryption]
+//	key_file = "/etc/faktory/encryption_keys"
+//
+// FAKTORY_ENCRYPTION_KEYS overrides the config.  It holds the keys or,
+// if it starts with a /, the path to the file holding them.  Keys are
+// given one per line (or separated by commas) as an ID and 32 bytes of
+// base64, e.g. generated with `openssl rand -base64 32`:
+//
+//	2026-10:6rV0yq4pJr2pX1c2p2H3xPTWlUIMtQrSxKvmXnBvT9E=
+//	2026-04:Zr0ahxvMFIBO59vs7kyDUuX9lG+7v+dp3T8LR7aCXM0=
+//
+// New jobs are encrypted with the first key; the others decrypt jobs
+// pushed before the key was rotated.  Each job is encrypted with its own
+// random data key which is stored, encrypted with the key, alongside the
+// key's ID in the job's "faktory.encrypted" custom attribute.  The args
+// hold the ciphertext until the job is fetched.  Custom attributes which
+// the server acts upon, e.g. dead_ttl or traceparent, stay in plaintext.
+type encryption struct {
+	mu      sync.Mutex
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+const (
+	encryptedKey = "faktory.encrypted"
+)
+
+var (
+	keyID           = regexp.MustCompile(`\A[A-Za-z0-9_.-]+\z`)
+	plaintextCustom = []string{"dead_ttl", "wrapped", client.TraceParentKey, client.TraceStateKey}
+)
+
+// configureEncryption loads the encryption keys, keeping the current
+// keys if they can't be loaded.
+func (s *Server) configureEncryption() error {
+	data, source, err := s.encryptionKeys()
+	if err != nil {
+		return err
+	}
+	current, keys, err := parseEncryptionKeys(data)
+	if err != nil {
+		return fmt.Errorf("invalid encryption keys in %s: %w", source, err)
+	}
+
+	e := &s.encryption
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.current = current
+	e.keys = keys
+	return nil
+}
+
+func (s *Server) encryptionKeys() (string, string, error) {
+	path := s.Options.String("encryption", "key_file", "")
+	source := path
+	if val, ok := os.LookupEnv("FAKTORY_ENCRYPTION_KEYS"); ok {
+		if !strings.HasPrefix(val, "/") {
+			return val, "FAKTORY_ENCRYPTION_KEYS", nil
+		}
+		path = val
+		source = val
+	}
+	if path == "" {
+		return "", "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot read encryption keys: %w", err)
+	}
+	return string(data), source, nil
+}
+
+// parseEncryptionKeys returns the ID of the first key and the ciphers
+// for all of the keys by ID.
+func parseEncryptionKeys(data string) (string, map[string]cipher.AEAD, error) {
+	current := ""
+	keys := map[string]cipher.AEAD{}
+	lines := strings.FieldsFunc(data, func(r rune) bool {
+		return r == '\n' || r == ','
+	})
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok || !keyID.MatchString(id) {
+			return "", nil, errors.New("keys must be given as <id>:<base64 key>")
+		}
+		if _, ok := keys[id]; ok {
+			return "", nil, fmt.Errorf("duplicate key %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return "", nil, fmt.Errorf("key %q must be 32 bytes of base64", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return "", nil, err
+		}
+		keys[id] = aead
+		if current == "" {
+			current = id
+		}
+	}
+	return current, keys, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte, data []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, _ = rand.Read(nonce)
+	return aead.Seal(nonce, nonce, plaintext, data)
+}
+
+func open(aead cipher.AEAD, ciphertext []byte, data []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, data)
+}
+
+// Encrypted returns true if the job's args are encrypted.
+func (s *Server) Encrypted(job *client.Job) bool {
+	_, ok := job.GetCustom(encryptedKey)
+	return ok
+}
+
+// Encrypt encrypts the job's args and custom attributes with the current
+// key.  The job is unchanged if no keys are configured.
+func (s *Server) Encrypt(job *client.Job) error {
+	if s.Encrypted(job) {
+		// only the server may encrypt jobs
+		delete(job.Custom, encryptedKey)
+	}
+
+	e := &s.encryption
+	e.mu.Lock()
+	id, aead := e.current, e.keys[e.current]
+	e.mu.Unlock()
+	if aead == nil {
+		return nil
+	}
+
+	payload := map[string]any{"args": job.Args}
+	custom := map[string]any{}
+	plain := map[string]any{}
+	for k, v := range job.Custom {
+		if slices.Contains(plaintextCustom, k) {
+			plain[k] = v
+		} else {
+			custom[k] = v
+		}
+	}
+	if len(custom) > 0 {
+		payload["custom"] = custom
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal job payload: %w", err)
+	}
+
+	dataKey := make([]byte, 32)
+	_, _ = rand.Read(dataKey)
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+	job.Args = []any{base64.StdEncoding.EncodeToString(seal(dataAEAD, data, []byte(job.Jid)))}
+	plain[encryptedKey] = map[string]any{
+		"kid": id,
+		"key": base64.StdEncoding.EncodeToString(seal(aead, dataKey, []byte(id))),
+	}
+	job.Custom = plain
+	return nil
+}
+
+// Deliver returns a fetched job as it is given to a worker.  A job which
+// can't be decrypted, e.g. because its key was removed, is failed rather
+// than handed over as ciphertext so it's retried once the key is back.
+func (s *Server) Deliver(ctx context.Context, mgr manager.Manager, job *client.Job) (*client.Job, error) {
+	plain, err := s.Decrypt(job)
+	if err == nil {
+		return plain, nil
+	}
+	err = fmt.Errorf("unable to decrypt %s: %w", job.Jid, err)
+	failure := &manager.FailPayload{Jid: job.Jid, ErrorType: "DecryptError", ErrorMessage: err.Error()}
+	if ferr := mgr.Fail(ctx, failure); ferr != nil {
+		util.Warnw("Unable to fail job", util.Fields{"jid": job.Jid, "error": ferr})
+	}
+	return nil, err
+}
+
+// Decrypt returns a copy of the job as it is given to a worker, with its
+// offloaded args restored and decrypted.
+func (s *Server) Decrypt(job *client.Job) (*client.Job, error) {
+	job = s.Rehydrate(job)
+	val, ok := job.GetCustom(encryptedKey)
+	if !ok {
+		return job, nil
+	}
+	env, _ := val.(map[string]any)
+	id, _ := env["kid"].(string)
+	wrapped, _ := env["key"].(string)
+
+	e := &s.encryption
+	e.mu.Lock()
+	aead := e.keys[id]
+	e.mu.Unlock()
+	if aead == nil {
+		return nil, fmt.Errorf("unknown encryption key %q", id)
+	}
+
+	encoded, ok := "", len(job.Args) == 1
+	if ok {
+		encoded, ok = job.Args[0].(string)
+	}
+	if !ok {
+		return nil, errors.New("invalid encrypted args")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted args: %w", err)
+	}
+	keyData, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	dataKey, err := open(aead, keyData, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	data, err := open(dataAEAD, ciphertext, []byte(job.Jid))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt args: %w", err)
+	}
+
+	var payload struct {
+		Args   []any          `json:"args"`
+		Custom map[string]any `json:"custom"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid decrypted payload: %w", err)
+	}
+	dup := *job
+	dup.Args = payload.Args
+	dup.Custom = payload.Custom
+	for k, v := range job.Custom {
+		if k != encryptedKey {
+			if dup.Custom == nil {
+				dup.Custom = map[string]any{}
+			}
+			dup.Custom[k] = v
+		}
+	}
+	return &dup, nil
+}
+
+// sealJob is the manager's Offloader: pushed jobs are encrypted and then
+// their args are offloaded if they are still too large.
+func (s *Server) sealJob(ctx context.Context, job *client.Job) error {
+	if err := s.Encrypt(job); err != nil {
+		return err
+	}
+	return s.offload(ctx, job)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomKey(id string) string {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return id + ":" + base64.StdEncoding.EncodeToString(key)
+}
+
+func TestParseEncryptionKeys(t *testing.T) {
+	current, keys, err := parseEncryptionKeys("# rotated in October\n" + randomKey("new") + "\n\n" + randomKey("old") + "," + randomKey("older"))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", current)
+	assert.Len(t, keys, 3)
+
+	current, keys, err = parseEncryptionKeys("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", current)
+	assert.Len(t, keys, 0)
+
+	cases := map[string]string{
+		"abc":                                 "keys must be given as <id>:<base64 key>",
+		"a b:" + randomKey("x")[2:]:           "keys must be given as <id>:<base64 key>",
+		"short:YWJj":                          `key "short" must be 32 bytes of base64`,
+		"bad:!!!":                             `key "bad" must be 32 bytes of base64`,
+		randomKey("a") + "," + randomKey("a"): `duplicate key "a"`,
+	}
+	for data, msg := range cases {
+		_, _, err := parseEncryptionKeys(data)
+		assert.EqualError(t, err, msg, data)
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "keys")
+	oldKey := randomKey("old")
+	assert.NoError(t, os.WriteFile(keyFile, []byte(oldKey+"\n"), 0o600))
+
+	config := map[string]any{
+		"encryption": map[string]any{
+			"key_file": keyFile,
+		},
+		"payloads": map[string]any{
+			"offload_size": int64(200),
+		},
+	}
+	runMemoryServer(t, config, func(s *Server, cl *client.Client) {
+		ctx := context.Background()
+
+		stored := func(t *testing.T) *client.Job {
+			q, _ := s.Store().ExistingQueue(ctx, "default")
+			var job client.Job
+			assert.NoError(t, q.Each(ctx, func(_ int, data []byte) error {
+				assert.NotContains(t, string(data), "mike@example.com")
+				assert.NotContains(t, string(data), "acme")
+				return util.JsonUnmarshal(data, &job)
+			}))
+			return &job
+		}
+
+		job := client.NewJob("SendEmail", "mike@example.com", 123)
+		job.SetCustom("tenant", "acme")
+		job.SetCustom("dead_ttl", 60)
+		assert.NoError(t, cl.Push(job))
+
+		sj := stored(t)
+		assert.True(t, s.Encrypted(sj))
+		assert.Len(t, sj.Args, 1)
+		assert.EqualValues(t, 60, sj.Custom["dead_ttl"])
+		assert.Equal(t, "old", sj.Custom[encryptedKey].(map[string]any)["kid"])
+
+		// the ciphertext is bound to the jid
+		forged := *sj
+		forged.Jid = client.RandomJid()
+		_, err := s.Decrypt(&forged)
+		assert.ErrorContains(t, err, "cannot decrypt args")
+
+		t.Run("Rotation", func(t *testing.T) {
+			assert.NoError(t, os.WriteFile(keyFile, []byte(randomKey("new")+"\n"+oldKey+"\n"), 0o600))
+			s.Reload()
+
+			fetched, err := cl.Fetch("default")
+			assert.NoError(t, err)
+			assert.Equal(t, []any{"mike@example.com", float64(123)}, fetched.Args)
+			assert.Equal(t, map[string]any{"tenant": "acme", "dead_ttl": float64(60)}, fetched.Custom)
+			assert.NoError(t, cl.Ack(fetched.Jid))
+
+			job := client.NewJob("SendEmail", "mike@example.com", strings.Repeat("x", 300))
+			assert.NoError(t, cl.Push(job))
+			sj := stored(t)
+			assert.Equal(t, "new", sj.Custom[encryptedKey].(map[string]any)["kid"])
+			// the ciphertext is offloaded
+			_, offloaded := sj.GetCustom(offloadKey)
+			assert.True(t, offloaded)
+
+			fetched, err = cl.Fetch("default")
+			assert.NoError(t, err)
+			assert.Equal(t, []any{"mike@example.com", strings.Repeat("x", 300)}, fetched.Args)
+			assert.Nil(t, fetched.Custom)
+			assert.NoError(t, cl.Ack(fetched.Jid))
+		})
+
+		t.Run("InvalidKeys", func(t *testing.T) {
+			assert.NoError(t, os.WriteFile(keyFile, []byte("nope"), 0o600))
+			s.Reload()
+			assert.NoError(t, cl.Push(client.NewJob("SendEmail", "mike@example.com")))
+			assert.Equal(t, "new", stored(t).Custom[encryptedKey].(map[string]any)["kid"])
+
+			// jobs encrypted with an unknown key fail rather than
+			// being fetched as ciphertext
+			assert.NoError(t, os.WriteFile(keyFile, []byte(randomKey("newest")), 0o600))
+			s.Reload()
+			fetched, err := cl.Fetch("default")
+			assert.ErrorContains(t, err, `unknown encryption key "new"`)
+			assert.Nil(t, fetched)
+			assert.EqualValues(t, 1, s.Store().Retries().Size(ctx))
+			assert.NoError(t, s.Store().Retries().Each(ctx, func(_ int, entry storage.SortedEntry) error {
+				job, err := entry.Job()
+				assert.NoError(t, err)
+				assert.Equal(t, "DecryptError", job.Failure.ErrorType)
+				return nil
+			}))
+			assert.NoError(t, s.Store().Retries().Clear(ctx))
+		})
+
+		t.Run("Environment", func(t *testing.T) {
+			t.Setenv("FAKTORY_ENCRYPTION_KEYS", randomKey("env")+","+oldKey)
+			assert.NoError(t, s.configureEncryption())
+			assert.Equal(t, "env", s.encryption.current)
+			assert.Len(t, s.encryption.keys, 2)
+
+			t.Setenv("FAKTORY_ENCRYPTION_KEYS", filepath.Join(dir, "missing"))
+			assert.ErrorContains(t, s.configureEncryption(), "cannot read encryption keys")
+		})
+	})
+}
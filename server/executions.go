@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+)
+
+// INFO summarizes the execution metrics for this recent window.
+var MetricsSnapshotWindow = 5 * time.Minute
+
+func (ns *Namespace) metricsSnapshot(ctx context.Context, window time.Duration) (*client.MetricsSnapshot, error) {
+	minutes := []*storage.MinuteMetrics{}
+	err := ns.store.ExecutionHistory(ctx, time.Now().Add(-window), func(mm *storage.MinuteMetrics) {
+		minutes = append(minutes, mm)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	queues, jobtypes := storage.SumExecutions(minutes)
+	return &client.MetricsSnapshot{
+		Window:   uint64(window.Seconds()),
+		Queues:   ExecutionStats(queues),
+		Jobtypes: ExecutionStats(jobtypes),
+	}, nil
+}
+
+func ExecutionStats(metrics map[string]*storage.ExecutionMetrics) map[string]client.ExecutionStats {
+	stats := make(map[string]client.ExecutionStats, len(metrics))
+	for name, em := range metrics {
+		stats[name] = client.ExecutionStats{
+			Processed: em.Processed,
+			Failed:    em.Failed,
+			P50:       em.Percentile(0.50).Seconds(),
+			P95:       em.Percentile(0.95).Seconds(),
+			P99:       em.Percentile(0.99).Seconds(),
+		}
+	}
+	return stats
+}
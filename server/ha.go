@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// High availability runs two Faktory servers against the same external
+// Redis, set with REDIS_URL, with one active and the other a warm
+// standby:
+//
+//	[ha]
+//	enabled = true
+//	lease = 15 # seconds
+//
+// The active server holds a lease in Redis and renews it every third of
+// the lease.  The standby boots but doesn't listen until the lease is
+// released or expires, then it takes over: it loads the working set,
+// starts its task runner and accepts connections.  A server which
+// can't renew its lease for two thirds of the lease stops accepting
+// commands and shuts down, a third of the lease before the standby can
+// see the lease expire.  Two servers are only active at once if the
+// old one is paused for longer than that, e.g. by a frozen VM.
+// Clients list both servers to fail over between them:
+//
+//	FAKTORY_URL=tcp://:password@faktory1:7419,faktory2:7419
+//
+// Offloaded args are only readable by the server which wrote them so
+// with offloading enabled payloads/offload_dir must be set to storage
+// shared by both servers, e.g. an NFS mount; the default in the
+// storage directory is rejected.  Changes require a restart.
+type ha struct {
+	node   string
+	ttl    time.Duration
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// when the last successful renewal began, the lease expires ttl
+	// after this at the earliest
+	renewed time.Time
+}
+
+const (
+	leaseName = "leader"
+)
+
+var (
+	// ErrStandbyStopped is returned by Boot when the server is shut
+	// down while waiting on standby.
+	ErrStandbyStopped = errors.New("server stopped while on standby")
+)
+
+func (s *Server) leaseTTL() time.Duration {
+	if !s.Options.Bool("ha", "enabled", false) {
+		return 0
+	}
+	secs := s.Options.Int("ha", "lease", 15)
+	if secs < 1 {
+		util.Warnf("Config error: ha/lease must be at least 1 second")
+		secs = 15
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// awaitLease blocks until the server holds the leader lease if high
+// availability is enabled.
+func (s *Server) awaitLease(store storage.Store) error {
+	ttl := s.leaseTTL()
+	if ttl == 0 {
+		return nil
+	}
+	if store.Redis() != nil && s.Options.RedisURL == "" {
+		return fmt.Errorf("high availability requires an external Redis, set REDIS_URL")
+	}
+
+	hostname, _ := os.Hostname()
+	s.ha.node = fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), util.RandomJid())
+	s.ha.ttl = ttl
+
+	standby := false
+	for {
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), ttl/3)
+		ok, err := store.AcquireLease(ctx, leaseName, s.ha.node, ttl)
+		cancel()
+		if err != nil {
+			util.Warnw("Unable to acquire HA lease", util.Fields{"error": err})
+		} else if ok {
+			s.ha.renewed = start
+			break
+		} else if !standby {
+			util.Info("Another server is active, waiting on standby")
+			standby = true
+		}
+
+		select {
+		case <-s.stopper:
+			return ErrStandbyStopped
+		case <-time.After(ttl / 3):
+		}
+	}
+	if standby {
+		util.Info("Taking over as the active server")
+	}
+	return nil
+}
+
+// holdLease renews the lease in the background until the server
+// stops.  The server shuts down if it loses the lease or can't renew it
+// for two thirds of the lease, so it has stopped accepting commands
+// before the lease expires and the standby polls again.
+func (s *Server) holdLease(store storage.Store) {
+	if s.ha.node == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ha.cancel = cancel
+	s.ha.wg.Add(1)
+
+	go func() {
+		defer s.ha.wg.Done()
+		ttl := s.ha.ttl
+		deadline := s.ha.renewed.Add(ttl - ttl/3)
+		giveUp := time.NewTimer(time.Until(deadline))
+		defer giveUp.Stop()
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-giveUp.C:
+				util.Warn("Unable to renew the HA lease before it expires, shutting down")
+				s.loseLease()
+				return
+			case <-ticker.C:
+			}
+
+			start := time.Now()
+			c, done := context.WithDeadline(ctx, deadline)
+			ok, err := store.AcquireLease(c, leaseName, s.ha.node, ttl)
+			done()
+			if ctx.Err() != nil {
+				return
+			}
+			if ok {
+				deadline = start.Add(ttl - ttl/3)
+				giveUp.Reset(time.Until(deadline))
+				continue
+			}
+			if err != nil {
+				util.Warnw("Unable to renew HA lease", util.Fields{"error": err})
+				continue
+			}
+			util.Warn("Lost the HA lease, shutting down")
+			s.loseLease()
+			return
+		}
+	}()
+}
+
+// loseLease stops the server without releasing the lease, which may
+// belong to the other server by now.
+func (s *Server) loseLease() {
+	s.ha.node = ""
+	s.Shutdown()
+}
+
+// checkHAOffload rejects offloading to a directory which the standby
+// can't read, see ha.
+func (s *Server) checkHAOffload() error {
+	if s.leaseTTL() == 0 || s.Options.Int("payloads", "offload_size", 0) <= 0 {
+		return nil
+	}
+	if s.Options.Config("payloads", "offload_dir", nil) == nil {
+		return fmt.Errorf("high availability with offloading requires payloads/offload_dir on storage shared by both servers")
+	}
+	return nil
+}
+
+// releaseLease lets the standby take over immediately.
+func (s *Server) releaseLease(store storage.Store) {
+	if s.ha.cancel != nil {
+		s.ha.cancel()
+		s.ha.wg.Wait()
+		s.ha.cancel = nil
+	}
+	if s.ha.node == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := store.ReleaseLease(ctx, leaseName, s.ha.node); err != nil {
+		util.Warnw("Unable to release HA lease", util.Fields{"error": err})
+	}
+	s.ha.node = ""
+}
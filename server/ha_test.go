@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighAvailability(t *testing.T) {
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+
+	newServer := func() *Server {
+		s, err := NewServer(&ServerOptions{
+			GlobalConfig: map[string]any{
+				"ha": map[string]any{"enabled": true, "lease": int64(1)},
+			},
+			Binding:          "localhost:0",
+			StorageDirectory: t.TempDir(),
+			ConfigDirectory:  t.TempDir(),
+			PoolSize:         DefaultMaxPoolSize,
+		})
+		assert.NoError(t, err)
+		return s
+	}
+	dial := func(s *Server) *client.Client {
+		srv := client.DefaultServer()
+		srv.Address = s.Addr().String()
+		cl, err := client.Dial(srv, "")
+		assert.NoError(t, err)
+		return cl
+	}
+	boot := func(s *Server) chan error {
+		booted := make(chan error, 1)
+		go func() {
+			booted <- s.BootWithStore(store)
+		}()
+		return booted
+	}
+
+	active := newServer()
+	assert.NoError(t, active.BootWithStore(store))
+	go func() {
+		_ = active.Run()
+	}()
+	cl := dial(active)
+	assert.NoError(t, cl.Push(client.NewJob("Invoice", 1)))
+	job, err := cl.Fetch("default")
+	assert.NoError(t, err)
+	assert.NotNil(t, job)
+	_ = cl.Close()
+
+	standby := newServer()
+	booted := boot(standby)
+	// a FLUSH doesn't cause a failover
+	assert.NoError(t, store.Flush(ctx))
+	select {
+	case <-booted:
+		assert.Fail(t, "standby booted while the lease is held")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	active.Shutdown()
+	active.Stop(nil)
+
+	select {
+	case err := <-booted:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "standby didn't take over")
+	}
+	go func() {
+		_ = standby.Run()
+	}()
+	cl = dial(standby)
+	assert.NoError(t, cl.Push(client.NewJob("Invoice", 2)))
+	job, err = cl.Fetch("default")
+	assert.NoError(t, err)
+	assert.NotNil(t, job)
+	_ = cl.Close()
+
+	t.Run("StandbyStopped", func(t *testing.T) {
+		s := newServer()
+		booted := boot(s)
+		s.Shutdown()
+		select {
+		case err := <-booted:
+			assert.Equal(t, ErrStandbyStopped, err)
+		case <-time.After(2 * time.Second):
+			assert.Fail(t, "standby didn't stop")
+		}
+	})
+
+	t.Run("LostLease", func(t *testing.T) {
+		assert.NoError(t, store.ReleaseLease(ctx, leaseName, standby.ha.node))
+		ok, err := store.AcquireLease(ctx, leaseName, "intruder", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		select {
+		case <-standby.Stopper():
+		case <-time.After(2 * time.Second):
+			assert.Fail(t, "server kept running without the lease")
+		}
+		// e.g. SIGTERM while shutting down
+		standby.Shutdown()
+		standby.Stop(nil)
+	})
+}
+
+// unrenewable fails to renew leases once broken, like a lost connection
+// to Redis.
+type unrenewable struct {
+	storage.Store
+	broken atomic.Bool
+}
+
+func (u *unrenewable) AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	if u.broken.Load() {
+		return false, errors.New("connection refused")
+	}
+	return u.Store.AcquireLease(ctx, name, owner, ttl)
+}
+
+func TestHighAvailabilityStopsBeforeExpiry(t *testing.T) {
+	store := &unrenewable{Store: storage.NewMemoryStore()}
+	s, err := NewServer(&ServerOptions{
+		GlobalConfig: map[string]any{
+			"ha": map[string]any{"enabled": true, "lease": int64(3)},
+		},
+		Binding:          "localhost:0",
+		StorageDirectory: t.TempDir(),
+		ConfigDirectory:  t.TempDir(),
+		PoolSize:         DefaultMaxPoolSize,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.BootWithStore(store))
+	acquired := time.Now()
+	defer s.Stop(nil)
+	store.broken.Store(true)
+
+	select {
+	case <-s.Stopper():
+	case <-time.After(3 * time.Second):
+		assert.FailNow(t, "server kept running without its lease")
+	}
+	// a renewal interval before the lease expires
+	assert.Less(t, time.Since(acquired), 2500*time.Millisecond)
+	assert.Greater(t, time.Since(acquired), 1500*time.Millisecond)
+}
+
+func TestHighAvailabilityOffloadDir(t *testing.T) {
+	boot := func(payloads map[string]any) error {
+		s, err := NewServer(&ServerOptions{
+			GlobalConfig: map[string]any{
+				"ha":       map[string]any{"enabled": true},
+				"payloads": payloads,
+			},
+			Binding:          "localhost:0",
+			StorageDirectory: t.TempDir(),
+			ConfigDirectory:  t.TempDir(),
+			PoolSize:         DefaultMaxPoolSize,
+		})
+		assert.NoError(t, err)
+		err = s.BootWithStore(storage.NewMemoryStore())
+		if err == nil {
+			s.Stop(nil)
+		}
+		return err
+	}
+
+	assert.Error(t, boot(map[string]any{"offload_size": int64(1024)}))
+	assert.NoError(t, boot(map[string]any{"offload_size": int64(1024), "offload_dir": t.TempDir()}))
+	assert.NoError(t, boot(map[string]any{}))
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/util"
+)
+
+// Admission control rejects pushes with an OVERLOAD error when a queue
+// is full or a producer pushes too quickly, so a runaway producer can't
+// fill Redis.  Clients should back off and retry later.  It is disabled
+// by default:
+//
+//	[limits]
+//	enabled = true
+//	max_size = 1000000    # jobs in any one queue, 0 for no limit
+//	push_rate = 500       # pushes per second per connection
+//	push_burst = 1000     # defaults to push_rate
+//	user_push_rate = 2000 # pushes per second per username
+//	user_push_burst = 4000
+//
+// Pushes through the HTTP API are limited per remote address instead of
+// per connection, and by their Basic Auth username.
+//
+//	[limits.queues.bulk]
+//	max_size = 5000000
+type admission struct {
+	mu       sync.Mutex
+	limits   pushLimits
+	users    map[string]*tokenBucket
+	remotes  map[string]*tokenBucket
+	rejected map[string]map[string]uint64 // by namespace and queue
+}
+
+type pushLimits struct {
+	enabled   bool
+	maxSize   int64
+	queues    map[string]int64
+	rate      float64
+	burst     float64
+	userRate  float64
+	userBurst float64
+}
+
+func (pl pushLimits) maxSizeFor(queue string) int64 {
+	if size, ok := pl.queues[queue]; ok {
+		return size
+	}
+	return pl.maxSize
+}
+
+type connectionKey struct{}
+
+type pusherKey struct{}
+
+type pusher struct {
+	remote   string
+	username string
+}
+
+// WithPusher returns a context for pushing jobs outside of a connection,
+// e.g. from the HTTP API, so admission control limits them by the remote
+// address and username like a connection.
+func WithPusher(ctx context.Context, remote string, username string) context.Context {
+	return context.WithValue(ctx, pusherKey{}, pusher{remote: remote, username: username})
+}
+
+func (s *Server) configureAdmission() {
+	pl := pushLimits{
+		enabled:  s.Options.Bool("limits", "enabled", false),
+		maxSize:  int64(s.Options.Int("limits", "max_size", 0)),
+		queues:   queueLimits(s.Options.Config("limits", "queues", nil)),
+		rate:     float64(s.Options.Int("limits", "push_rate", 0)),
+		userRate: float64(s.Options.Int("limits", "user_push_rate", 0)),
+	}
+	pl.burst = float64(s.Options.Int("limits", "push_burst", int(pl.rate)))
+	pl.userBurst = float64(s.Options.Int("limits", "user_push_burst", int(pl.userRate)))
+
+	ad := &s.admission
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	ad.limits = pl
+	ad.users = map[string]*tokenBucket{}
+	ad.remotes = map[string]*tokenBucket{}
+}
+
+func queueLimits(val any) map[string]int64 {
+	limits := map[string]int64{}
+	if val == nil {
+		return limits
+	}
+	tables, ok := val.(map[string]any)
+	if !ok {
+		util.Warnf("Config error: limits/queues must be a table of tables, e.g. [limits.queues.name]")
+		return limits
+	}
+	for name, table := range tables {
+		values, ok := table.(map[string]any)
+		if !ok {
+			util.Warnf("Config error: limits/queues/%s is not a table", name)
+			continue
+		}
+		if size, ok := values["max_size"].(int64); ok {
+			limits[name] = size
+		} else if values["max_size"] != nil {
+			util.Warnf("Config error: limits/queues/%s/max_size is not an Integer", name)
+		}
+	}
+	return limits
+}
+
+// admit is the push middleware which enforces the limits.
+func (s *Server) admit(ctx context.Context, next func() error) error {
+	ad := &s.admission
+	ad.mu.Lock()
+	pl := ad.limits
+	ad.mu.Unlock()
+	if !pl.enabled {
+		return next()
+	}
+
+	mh := ctx.Value(manager.MiddlewareHelperKey).(manager.Context)
+	job := mh.Job()
+	ns := s.namespaceOf(mh.Manager())
+	now := time.Now()
+
+	username := ""
+	conn, _ := ctx.Value(connectionKey{}).(*Connection)
+	if conn != nil {
+		if pl.rate > 0 && !conn.pushes.allow(now, pl.rate, pl.burst) {
+			return s.reject(ns, job.Queue, fmt.Sprintf("Connection is pushing more than %v jobs per second", pl.rate))
+		}
+		if conn.client != nil {
+			username = conn.client.Username
+		}
+	} else if p, ok := ctx.Value(pusherKey{}).(pusher); ok {
+		if pl.rate > 0 && !ad.remoteBucket(p.remote).allow(now, pl.rate, pl.burst) {
+			return s.reject(ns, job.Queue, fmt.Sprintf("%s is pushing more than %v jobs per second", p.remote, pl.rate))
+		}
+		username = p.username
+	}
+	if pl.userRate > 0 && username != "" {
+		if !ad.userBucket(username).allow(now, pl.userRate, pl.userBurst) {
+			return s.reject(ns, job.Queue, fmt.Sprintf("User %s is pushing more than %v jobs per second", username, pl.userRate))
+		}
+	}
+
+	if limit := pl.maxSizeFor(job.Queue); limit > 0 {
+		if q, ok := ns.store.ExistingQueue(ctx, job.Queue); ok && q.Size(ctx) >= uint64(limit) {
+			return s.reject(ns, job.Queue, fmt.Sprintf("Queue %s is full with %d jobs", job.Queue, limit))
+		}
+	}
+	return next()
+}
+
+func (s *Server) reject(ns *Namespace, queue string, msg string) error {
+	ad := &s.admission
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	if ad.rejected == nil {
+		ad.rejected = map[string]map[string]uint64{}
+	}
+	if ad.rejected[ns.Name] == nil {
+		ad.rejected[ns.Name] = map[string]uint64{}
+	}
+	ad.rejected[ns.Name][queue]++
+	return manager.Halt("OVERLOAD", msg)
+}
+
+func (ad *admission) userBucket(username string) *tokenBucket {
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	return bucketFor(ad.users, username)
+}
+
+func (ad *admission) remoteBucket(remote string) *tokenBucket {
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	return bucketFor(ad.remotes, remote)
+}
+
+func bucketFor(buckets map[string]*tokenBucket, key string) *tokenBucket {
+	tb, ok := buckets[key]
+	if !ok {
+		tb = &tokenBucket{}
+		buckets[key] = tb
+	}
+	return tb
+}
+
+// RejectedPushes returns the number of pushes to the namespace rejected
+// by admission control for each queue since Faktory started.
+func (ns *Namespace) RejectedPushes() map[string]uint64 {
+	ad := &ns.server.admission
+	ad.mu.Lock()
+	defer ad.mu.Unlock()
+	return maps.Clone(ad.rejected[ns.Name])
+}
+
+// tokenBucket allows bursts of up to burst pushes, refilling at rate
+// per second.  The rate is passed in so a reload applies immediately.
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (tb *tokenBucket) allow(now time.Time, rate float64, burst float64) bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if burst < 1 {
+		burst = 1
+	}
+
+	if tb.last.IsZero() {
+		tb.tokens = burst
+	} else {
+		tb.tokens += now.Sub(tb.last).Seconds() * rate
+		if tb.tokens > burst {
+			tb.tokens = burst
+		}
+	}
+	tb.last = now
+
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
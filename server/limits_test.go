@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	tb := &tokenBucket{}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.True(t, tb.allow(now, 2, 3))
+	}
+	assert.False(t, tb.allow(now, 2, 3))
+
+	// refills at the rate, up to the burst
+	assert.True(t, tb.allow(now.Add(500*time.Millisecond), 2, 3))
+	assert.False(t, tb.allow(now.Add(500*time.Millisecond), 2, 3))
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, tb.allow(now, 2, 3))
+	}
+	assert.False(t, tb.allow(now, 2, 3))
+}
+
+func TestAdmission(t *testing.T) {
+	runServer("localhost:7425", func(s *Server) {
+		s.Options.GlobalConfig = map[string]any{
+			"limits": map[string]any{
+				"enabled":    true,
+				"max_size":   int64(3),
+				"push_rate":  int64(1),
+				"push_burst": int64(5),
+				"queues": map[string]any{
+					"bulk": map[string]any{"max_size": int64(0)},
+				},
+			},
+		}
+		s.Reload()
+		defer func() {
+			s.Options.GlobalConfig = map[string]any{}
+			s.Reload()
+		}()
+
+		cl, err := client.Dial(&client.Server{Network: "tcp", Address: "localhost:7425", Timeout: time.Second}, "")
+		assert.NoError(t, err)
+		defer cl.Close()
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, cl.Push(client.NewJob("LimitedJob", i)))
+		}
+		err = cl.Push(client.NewJob("LimitedJob", 4))
+		assert.Error(t, err)
+		assert.True(t, strings.HasPrefix(err.Error(), "OVERLOAD"), err.Error())
+
+		// the bulk queue has no size limit but the connection is
+		// still limited to a burst of 5 pushes
+		bulk := client.NewJob("BulkJob", 1)
+		bulk.Queue = "bulk"
+		assert.NoError(t, cl.Push(bulk))
+		bulk = client.NewJob("BulkJob", 2)
+		bulk.Queue = "bulk"
+		err = cl.Push(bulk)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "per second")
+
+		state, err := cl.CurrentState()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, state.Data.Rejected["default"])
+		assert.EqualValues(t, 1, state.Data.Rejected["bulk"])
+	})
+}
+
+func TestAdmissionWithoutConnection(t *testing.T) {
+	config := map[string]any{
+		"limits": map[string]any{
+			"enabled":         true,
+			"push_rate":       int64(1),
+			"push_burst":      int64(2),
+			"user_push_rate":  int64(1),
+			"user_push_burst": int64(3),
+		},
+	}
+	runMemoryServer(t, config, func(s *Server, _ *client.Client) {
+		push := func(remote, username string) error {
+			ctx := WithPusher(context.Background(), remote, username)
+			return s.Manager().Push(ctx, client.NewJob("LimitedJob", 1))
+		}
+
+		assert.NoError(t, push("10.0.0.1", ""))
+		assert.NoError(t, push("10.0.0.1", ""))
+		err := push("10.0.0.1", "")
+		assert.ErrorContains(t, err, "10.0.0.1 is pushing more than 1 jobs per second")
+		assert.NoError(t, push("10.0.0.2", ""))
+
+		assert.NoError(t, push("10.0.0.3", "mike"))
+		assert.NoError(t, push("10.0.0.4", "mike"))
+		assert.NoError(t, push("10.0.0.5", "mike"))
+		err = push("10.0.0.6", "mike")
+		assert.ErrorContains(t, err, "User mike is pushing more than 1 jobs per second")
+	})
+}
+
+func TestAdmissionNamespaces(t *testing.T) {
+	config := map[string]any{
+		"limits": map[string]any{"enabled": true, "max_size": int64(1)},
+		"namespaces": map[string]any{
+			"billing": map[string]any{"password": "billpass", "database": int64(1)},
+		},
+	}
+	runMemoryServer(t, config, func(s *Server, cl *client.Client) {
+		ctx := context.Background()
+		billing := s.Namespace("billing")
+		assert.NoError(t, cl.Push(client.NewJob("LimitedJob", 1)))
+		// the default namespace's queue is full, not billing's
+		assert.NoError(t, billing.Manager().Push(ctx, client.NewJob("LimitedJob", 2)))
+		err := billing.Manager().Push(ctx, client.NewJob("LimitedJob", 3))
+		assert.ErrorContains(t, err, "Queue default is full")
+
+		assert.Equal(t, map[string]uint64{"default": 1}, billing.RejectedPushes())
+		assert.Nil(t, s.Namespace("").RejectedPushes())
+	})
+}
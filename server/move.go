@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// MoveQueue moves the jobs in the src queue matching the filter, or all
+// jobs if the filter is nil, to the dst queue and returns the number of
+// jobs moved.  Each job's queue attribute is rewritten to dst, the rest
+// of the payload is left as it was.  Jobs are moved in atomic batches
+// so if ctx is done part way through, the count of jobs already moved
+// is returned with the error and the rest stay in src.
+func (s *Server) MoveQueue(ctx context.Context, src string, dst string, filter *client.JobFilter) (uint64, error) {
+	return s.namespaces[0].MoveQueue(ctx, src, dst, filter)
+}
+
+// MoveQueue moves jobs between the namespace's queues, see
+// Server.MoveQueue.
+func (ns *Namespace) MoveQueue(ctx context.Context, src string, dst string, filter *client.JobFilter) (uint64, error) {
+	if src == dst {
+		return 0, fmt.Errorf("cannot move queue %s to itself", src)
+	}
+	from, ok := ns.store.ExistingQueue(ctx, src)
+	if !ok {
+		return 0, fmt.Errorf("no such queue: %s", src)
+	}
+	to, err := ns.store.GetQueue(ctx, dst)
+	if err != nil {
+		return 0, err
+	}
+
+	match, err := queueMatcher(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return from.MoveTo(ctx, to, func(data []byte) ([]byte, bool) {
+		if !match(string(data)) {
+			return nil, false
+		}
+		payload, err := RewriteJob(data, map[string]any{"queue": dst})
+		if err != nil {
+			util.Warnw("Unable to move invalid job payload", util.Fields{"payload": string(data)})
+			return nil, false
+		}
+		return payload, true
+	})
+}
+
+// RewriteJob returns the job payload with the given top-level
+// attributes replaced.  Everything else is kept as is, including
+// attributes this server doesn't know about.
+func RewriteJob(data []byte, attrs map[string]any) ([]byte, error) {
+	var job map[string]json.RawMessage
+	err := util.JsonUnmarshal(data, &job)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job payload must be an object")
+	}
+	for key, value := range attrs {
+		job[key], err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(job)
+}
+
+// queueMatcher builds a matcher for queued job payloads with the same
+// semantics as MUTATE, where Redis applies the pattern for sorted sets.
+func queueMatcher(filter *client.JobFilter) (func(value string) bool, error) {
+	pattern, fn := matchForFilter(filter)
+	if pattern == "*" {
+		return fn, nil
+	}
+	re, err := storage.GlobPattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+	}
+	return func(value string) bool {
+		return re.MatchString(value) && fn(value)
+	}, nil
+}
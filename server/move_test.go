@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueMatcher(t *testing.T) {
+	cases := []struct {
+		filter *client.JobFilter
+		value  string
+		match  bool
+	}{
+		{nil, `{"jid":"abc"}`, true},
+		{&client.JobFilter{Regexp: `*"user_?2"*`}, `{"args":["user_12"]}`, true},
+		{&client.JobFilter{Regexp: `*"user_?2"*`}, `{"args":["user_123"]}`, false},
+		{&client.JobFilter{Regexp: "*[*"}, `{"args":["["]}`, true},
+		{&client.JobFilter{Regexp: "*user*", Jobtype: "Foo"}, `{"jid":"abc","jobtype":"Foo","args":["user"]}`, true},
+		{&client.JobFilter{Regexp: "*user*", Jobtype: "Foo"}, `{"jid":"abc","jobtype":"Bar","args":["user"]}`, false},
+		{&client.JobFilter{Jobtype: "Foo"}, `{"jid":"abc","jobtype":"Foo"}`, true},
+		{&client.JobFilter{Jids: []string{"abc"}}, `{"jid":"abc"}`, true},
+		{&client.JobFilter{Jids: []string{"abc"}}, `{"jid":"def"}`, false},
+	}
+	for _, tc := range cases {
+		match, err := queueMatcher(tc.filter)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.match, match(tc.value), "%+v =~ %s", tc.filter, tc.value)
+	}
+}
+
+func TestQueueMove(t *testing.T) {
+	runServer("localhost:7424", func(s *Server) {
+		bg := context.Background()
+		cl, err := client.Dial(&client.Server{Network: "tcp", Address: "localhost:7424", Timeout: time.Second}, "")
+		assert.NoError(t, err)
+		defer cl.Close()
+
+		for i := 0; i < 3; i++ {
+			job := client.NewJob("SendInvoice", i)
+			job.Queue = "invoices-typo"
+			assert.NoError(t, cl.Push(job))
+		}
+		other := client.NewJob("Other", 1)
+		other.Queue = "invoices-typo"
+		assert.NoError(t, cl.Push(other))
+
+		_, err = cl.MoveQueue("nope", "invoices", nil)
+		assert.Error(t, err)
+		_, err = cl.MoveQueue("invoices-typo", "invoices-typo", nil)
+		assert.Error(t, err)
+		_, err = cl.MoveQueue("invoices-typo", "bad name!", nil)
+		assert.Error(t, err)
+
+		filter := client.OfType("SendInvoice")
+		count, err := cl.MoveQueue("invoices-typo", "invoices", &filter)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, count)
+
+		q, err := s.Store().GetQueue(bg, "invoices")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, q.Size(bg))
+		err = q.Each(bg, func(_ int, data []byte) error {
+			var job client.Job
+			assert.NoError(t, json.Unmarshal(data, &job))
+			assert.Equal(t, "invoices", job.Queue)
+			assert.Equal(t, "SendInvoice", job.Type)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		count, err = cl.MoveQueue("invoices-typo", "invoices", nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+		assert.EqualValues(t, 4, q.Size(bg))
+
+		// the client connection is still usable
+		_, err = cl.QueueSizes()
+		assert.NoError(t, err)
+	})
+}
+
+func TestQueueMoveKeepsPayload(t *testing.T) {
+	runMemoryServer(t, nil, func(s *Server, cl *client.Client) {
+		bg := context.Background()
+		q, err := s.Store().GetQueue(bg, "old")
+		assert.NoError(t, err)
+		assert.NoError(t, q.Push(bg, []byte(`{"jid":"abc","jobtype":"Foo","queue":"old","args":[1],"priority":9,"future_attr":{"a":1}}`)))
+
+		ctx, cancel := context.WithCancel(bg)
+		cancel()
+		count, err := s.MoveQueue(ctx, "old", "new", nil)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.EqualValues(t, 0, count)
+		_, err = cl.MoveQueueContext(ctx, "old", "new", nil)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		count, err = cl.MoveQueueContext(bg, "old", "new", nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+
+		dst, err := s.Store().GetQueue(bg, "new")
+		assert.NoError(t, err)
+		err = dst.Each(bg, func(_ int, data []byte) error {
+			var job map[string]any
+			assert.NoError(t, json.Unmarshal(data, &job))
+			assert.Equal(t, "new", job["queue"])
+			assert.Equal(t, map[string]any{"a": float64(1)}, job["future_attr"])
+			assert.EqualValues(t, 9, job["priority"])
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+}
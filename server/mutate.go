@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/contribsys/faktory/client"
 	"github.com/contribsys/faktory/manager"
@@ -18,27 +17,35 @@ var (
 	}
 )
 
-func mutateKill(ctx context.Context, store storage.Store, op client.Operation) error {
+func mutateKill(ctx context.Context, m manager.Manager, store storage.Store, op client.Operation) (uint64, error) {
 	ss := setForTarget(store, string(op.Target))
 	if ss == nil {
-		return fmt.Errorf("invalid target for mutation command")
+		return 0, fmt.Errorf("invalid target for mutation command")
 	}
+	count := uint64(0)
 	match, matchfn := matchForFilter(op.Filter)
-	return ss.Find(ctx, match, func(idx int, ent storage.SortedEntry) error {
+	err := ss.Find(ctx, match, func(idx int, ent storage.SortedEntry) error {
 		if matchfn(string(ent.Value())) {
-			return ss.MoveTo(ctx, store.Dead(), ent, time.Now().Add(manager.DeadTTL))
+			job, err := ent.Job()
+			if err != nil {
+				return err
+			}
+			count++
+			return ss.MoveTo(ctx, store.Dead(), ent, m.DeadExpiry(job))
 		}
 		return nil
 	})
+	return count, err
 }
 
-func mutateRequeue(ctx context.Context, store storage.Store, op client.Operation) error {
+func mutateRequeue(ctx context.Context, store storage.Store, op client.Operation) (uint64, error) {
 	ss := setForTarget(store, string(op.Target))
 	if ss == nil {
-		return fmt.Errorf("invalid target for mutation command")
+		return 0, fmt.Errorf("invalid target for mutation command")
 	}
+	count := uint64(0)
 	match, matchfn := matchForFilter(op.Filter)
-	return ss.Find(ctx, match, func(idx int, ent storage.SortedEntry) error {
+	err := ss.Find(ctx, match, func(idx int, ent storage.SortedEntry) error {
 		if matchfn(string(ent.Value())) {
 			j, err := ent.Job()
 			if err != nil {
@@ -52,27 +59,33 @@ func mutateRequeue(ctx context.Context, store storage.Store, op client.Operation
 			if err != nil {
 				return err
 			}
+			count++
 			return ss.RemoveEntry(ctx, ent)
 		}
 		return nil
 	})
+	return count, err
 }
 
-func mutateDiscard(ctx context.Context, store storage.Store, op client.Operation) error {
+func mutateDiscard(ctx context.Context, store storage.Store, op client.Operation) (uint64, error) {
 	ss := setForTarget(store, string(op.Target))
 	if ss == nil {
-		return fmt.Errorf("invalid target for mutation command")
+		return 0, fmt.Errorf("invalid target for mutation command")
 	}
 	if op.Filter == nil {
-		return ss.Clear(ctx)
+		count := ss.Size(ctx)
+		return count, ss.Clear(ctx)
 	}
+	count := uint64(0)
 	match, matchfn := matchForFilter(op.Filter)
-	return ss.Find(ctx, match, func(idx int, ent storage.SortedEntry) error {
+	err := ss.Find(ctx, match, func(idx int, ent storage.SortedEntry) error {
 		if matchfn(string(ent.Value())) {
+			count++
 			return ss.RemoveEntry(ctx, ent)
 		}
 		return nil
 	})
+	return count, err
 }
 
 func matchForFilter(filter *client.JobFilter) (string, func(value string) bool) {
@@ -83,14 +96,14 @@ func matchForFilter(filter *client.JobFilter) (string, func(value string) bool)
 	if len(filter.Jids) > 0 {
 		// `Jid` is a unique identifier of a job, so if they have specified
 		// `Jid`s _and_ `Regexp` and/or `Jobtype`, we are only taking `Jid`s
-		// into account and returning early:
+		// into account and returning early.  The Web UI's error groups can
+		// hold thousands of jids so look them up in a set.
+		jids := make(map[string]bool, len(filter.Jids))
+		for idx := range filter.Jids {
+			jids[filter.Jids[idx]] = true
+		}
 		return "*", func(value string) bool {
-			for idx := range filter.Jids {
-				if strings.Index(value, fmt.Sprintf(`"jid":%q`, filter.Jids[idx])) > 0 {
-					return true
-				}
-			}
-			return false
+			return hasJid(value, jids)
 		}
 	}
 
@@ -114,6 +127,25 @@ func matchForFilter(filter *client.JobFilter) (string, func(value string) bool)
 	return "*", AlwaysMatch
 }
 
+// hasJid returns true if any "jid" attribute in the job payload
+// is in the given set.
+func hasJid(value string, jids map[string]bool) bool {
+	for {
+		_, rest, ok := strings.Cut(value, `"jid":"`)
+		if !ok {
+			return false
+		}
+		jid, after, ok := strings.Cut(rest, `"`)
+		if !ok {
+			return false
+		}
+		if jids[jid] {
+			return true
+		}
+		value = after
+	}
+}
+
 func mutate(c *Connection, s *Server, cmd string) {
 	parts := strings.Split(cmd, " ")
 	if len(parts) != 2 {
@@ -121,27 +153,19 @@ func mutate(c *Connection, s *Server, cmd string) {
 		return
 	}
 
-	var err error
 	var op client.Operation
-	err = util.JsonUnmarshal([]byte(parts[1]), &op)
+	err := util.JsonUnmarshal([]byte(parts[1]), &op)
 	if err != nil {
 		_ = c.Error(cmd, err)
 		return
 	}
 
-	ctx := c.Context
+	count, err := s.namespaceFor(c).Mutate(c.Context, op)
 
-	switch op.Cmd {
-	case "clear":
-		err = mutateClear(ctx, s.Store(), string(op.Target))
-	case "kill":
-		err = mutateKill(ctx, s.Store(), op)
-	case "discard":
-		err = mutateDiscard(ctx, s.Store(), op)
-	case "requeue":
-		err = mutateRequeue(ctx, s.Store(), op)
-	default:
-		err = fmt.Errorf("unknown mutate operation")
+	// requeue is not destructive; record partial progress even if
+	// the mutation failed midway
+	if op.Cmd != "requeue" && (err == nil || count > 0) {
+		c.audit(s, "MUTATE "+op.Cmd, string(op.Target), count)
 	}
 
 	if err != nil {
@@ -152,12 +176,36 @@ func mutate(c *Connection, s *Server, cmd string) {
 	_ = c.Ok()
 }
 
-func mutateClear(ctx context.Context, store storage.Store, target string) error {
+// Mutate applies the operation to the Retries, Scheduled or Dead set
+// and returns the number of jobs affected.
+func (s *Server) Mutate(ctx context.Context, op client.Operation) (uint64, error) {
+	return s.namespaces[0].Mutate(ctx, op)
+}
+
+// Mutate applies the operation to the namespace's sets, see
+// Server.Mutate.
+func (ns *Namespace) Mutate(ctx context.Context, op client.Operation) (uint64, error) {
+	switch op.Cmd {
+	case "clear":
+		return mutateClear(ctx, ns.store, string(op.Target))
+	case "kill":
+		return mutateKill(ctx, ns.manager, ns.store, op)
+	case "discard":
+		return mutateDiscard(ctx, ns.store, op)
+	case "requeue":
+		return mutateRequeue(ctx, ns.store, op)
+	default:
+		return 0, fmt.Errorf("unknown mutate operation")
+	}
+}
+
+func mutateClear(ctx context.Context, store storage.Store, target string) (uint64, error) {
 	ss := setForTarget(store, target)
 	if ss == nil {
-		return fmt.Errorf("invalid target for mutation command")
+		return 0, fmt.Errorf("invalid target for mutation command")
 	}
-	return ss.Clear(ctx)
+	count := ss.Size(ctx)
+	return count, ss.Clear(ctx)
 }
 
 func setForTarget(store storage.Store, name string) storage.SortedSet {
@@ -211,3 +211,12 @@ func TestMutateCommands(t *testing.T) {
 
 	})
 }
+
+func TestHasJid(t *testing.T) {
+	jids := map[string]bool{"abc123": true, "def456": true}
+	assert.True(t, hasJid(`{"retry":25,"jid":"abc123","queue":"default"}`, jids))
+	assert.True(t, hasJid(`{"custom":{"jid":"other"},"jid":"def456"}`, jids))
+	assert.False(t, hasJid(`{"jid":"xyz789","queue":"default"}`, jids))
+	assert.False(t, hasJid(`{"queue":"default"}`, jids))
+	assert.False(t, hasJid(`{"jid":"abc123`, jids))
+}
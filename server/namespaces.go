@@ -0,0 +1,172 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// Namespaces let several apps share one server without seeing each
+// other's jobs:
+//
+//	[namespaces.billing]
+//	password = "..."
+//	database = 1
+//
+// A client selects a namespace in HELLO, e.g. with
+// FAKTORY_URL=tcp://:password@faktory:7419?namespace=billing, and must
+// authenticate with the namespace's password.  Each namespace is stored
+// in its own Redis database, so its queues, scheduled, retry and dead
+// jobs, history counters and KV are separate from those of the default
+// namespace and of each other.  PUSH, FETCH, INFO, FLUSH, MUTATE and
+// QUEUE only see the connection's namespace.  Database 0 holds the
+// default namespace.  Namespaces are created when the server boots so
+// changes require a restart.
+//
+// Databases rather than key prefixes keep every store operation,
+// including FLUSHDB and the multi-key Lua scripts, unchanged and make
+// it impossible for one namespace to read or flush another's keys.
+// The cost is a limit on the number of namespaces: the Redis which
+// Faktory runs itself has 256 databases but an external Redis has 16
+// unless its databases setting is raised.  Redis Cluster only has
+// database 0, but Faktory doesn't support Cluster in any case because
+// of those same scripts.
+type Namespace struct {
+	// Name is empty for the default namespace.
+	Name     string
+	Database int
+
+	server   *Server
+	store    storage.Store
+	manager  manager.Manager
+	password string
+}
+
+var (
+	namespaceName = regexp.MustCompile(`\A[a-zA-Z0-9_-]+\z`)
+)
+
+func (ns *Namespace) Store() storage.Store {
+	return ns.store
+}
+
+func (ns *Namespace) Manager() manager.Manager {
+	return ns.manager
+}
+
+// Authenticate returns true if password is the namespace's password.
+func (ns *Namespace) Authenticate(password string) bool {
+	return ns.password != "" && subtle.ConstantTimeCompare([]byte(password), []byte(ns.password)) == 1
+}
+
+// bootNamespaces opens the store and creates the manager of each
+// configured namespace.
+func (s *Server) bootNamespaces() error {
+	namespaces := []*Namespace{{server: s, store: s.store, manager: s.manager}}
+	databases := map[int]string{}
+
+	config, _ := s.Options.GlobalConfig["namespaces"].(map[string]any)
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		opts, ok := config[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("namespace %s must be a table", name)
+		}
+		if !namespaceName.MatchString(name) {
+			return fmt.Errorf("namespace names must match %v", namespaceName)
+		}
+		password, _ := opts["password"].(string)
+		if password == "" {
+			return fmt.Errorf("namespace %s requires a password", name)
+		}
+		var db int
+		switch num := opts["database"].(type) {
+		case int:
+			db = num
+		case int64:
+			db = int(num)
+		}
+		if db <= 0 {
+			return fmt.Errorf("namespace %s requires a database number above 0", name)
+		}
+		if other, ok := databases[db]; ok {
+			return fmt.Errorf("namespace %s cannot use database %d of %s", name, db, other)
+		}
+		databases[db] = "namespace " + name
+		namespaces = append(namespaces, &Namespace{
+			Name:     name,
+			Database: db,
+			server:   s,
+			password: password,
+		})
+	}
+
+	for idx, ns := range namespaces[1:] {
+		store, err := storage.OpenDatabase(s.store, ns.Database)
+		if err != nil {
+			closeNamespaces(namespaces[1 : idx+1])
+			return fmt.Errorf("namespace %s: %w", ns.Name, err)
+		}
+		ns.store = store
+		ns.manager = manager.NewSharedManager(s.manager, store)
+	}
+	if len(names) > 0 {
+		util.Infow("Serving namespaces", util.Fields{"count": len(names)})
+	}
+	s.namespaces = namespaces
+	return nil
+}
+
+func closeNamespaces(namespaces []*Namespace) {
+	for _, ns := range namespaces {
+		if ns.Name != "" {
+			_ = ns.store.Close()
+		}
+	}
+}
+
+// Namespace returns the namespace with the given name, the default
+// namespace for "" or nil if there's no such namespace.
+func (s *Server) Namespace(name string) *Namespace {
+	for _, ns := range s.namespaces {
+		if ns.Name == name {
+			return ns
+		}
+	}
+	return nil
+}
+
+// Namespaces returns the default namespace followed by the configured
+// namespaces ordered by name.
+func (s *Server) Namespaces() []*Namespace {
+	return s.namespaces
+}
+
+// namespaceOf returns the namespace served by the manager, e.g. the one
+// given to middleware.
+func (s *Server) namespaceOf(mgr manager.Manager) *Namespace {
+	for _, ns := range s.namespaces {
+		if ns.manager == mgr {
+			return ns
+		}
+	}
+	return s.namespaces[0]
+}
+
+// namespaceFor returns the namespace selected by the connection's HELLO.
+func (s *Server) namespaceFor(c *Connection) *Namespace {
+	if ns := s.Namespace(c.client.Namespace); ns != nil {
+		return ns
+	}
+	return s.namespaces[0]
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaces(t *testing.T) {
+	config := map[string]any{
+		"namespaces": map[string]any{
+			"billing": map[string]any{
+				"password": "billpass",
+				"database": int64(1),
+			},
+			"search": map[string]any{
+				"password": "searchpass",
+				"database": int64(2),
+			},
+		},
+	}
+	runMemoryServer(t, config, func(s *Server, cl *client.Client) {
+		ctx := context.Background()
+		assert.Len(t, s.Namespaces(), 3)
+		assert.Equal(t, "", s.Namespaces()[0].Name)
+		assert.Equal(t, "billing", s.Namespaces()[1].Name)
+		assert.Nil(t, s.Namespace("missing"))
+
+		dial := func(namespace, password string) (*client.Client, error) {
+			srv := client.DefaultServer()
+			srv.Address = s.Addr().String()
+			srv.Namespace = namespace
+			return client.Dial(srv, password)
+		}
+
+		billing, err := dial("billing", "billpass")
+		assert.NoError(t, err)
+		defer billing.Close()
+
+		assert.NoError(t, billing.Push(client.NewJob("Invoice", 1)))
+		assert.NoError(t, cl.Push(client.NewJob("Index", 2)))
+
+		ns := s.Namespace("billing")
+		q, ok := ns.Store().ExistingQueue(ctx, "default")
+		assert.True(t, ok)
+		assert.EqualValues(t, 1, q.Size(ctx))
+		q, ok = s.Store().ExistingQueue(ctx, "default")
+		assert.True(t, ok)
+		assert.EqualValues(t, 1, q.Size(ctx))
+		_, ok = s.Namespace("search").Store().ExistingQueue(ctx, "default")
+		assert.False(t, ok)
+
+		job, err := billing.Fetch("default")
+		assert.NoError(t, err)
+		assert.Equal(t, "Invoice", job.Type)
+		assert.NoError(t, billing.Ack(job.Jid))
+		job, err = billing.Fetch("default")
+		assert.NoError(t, err)
+		assert.Nil(t, job)
+
+		info, err := billing.Info()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, info["faktory"].(map[string]any)["total_processed"])
+		info, err = cl.Info()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, info["faktory"].(map[string]any)["total_processed"])
+		assert.EqualValues(t, 1, info["faktory"].(map[string]any)["total_enqueued"])
+
+		assert.NoError(t, billing.Push(client.NewJob("Invoice", 3)))
+		assert.NoError(t, billing.Flush())
+		assert.EqualValues(t, 0, ns.Store().TotalProcessed(ctx))
+		q, ok = s.Store().ExistingQueue(ctx, "default")
+		assert.True(t, ok)
+		assert.EqualValues(t, 1, q.Size(ctx))
+
+		t.Run("Rejected", func(t *testing.T) {
+			_, err := dial("billing", "searchpass")
+			assert.ErrorContains(t, err, "Invalid password")
+			_, err = dial("billing", "")
+			assert.ErrorContains(t, err, "Invalid password")
+			_, err = dial("missing", "billpass")
+			assert.ErrorContains(t, err, "Unknown namespace")
+		})
+	})
+}
+
+func TestNamespaceConfig(t *testing.T) {
+	cases := map[string]map[string]any{
+		"namespace bad must be a table": {
+			"bad": "nope",
+		},
+		"namespace names must match": {
+			"a.b": map[string]any{"password": "x", "database": int64(1)},
+		},
+		"namespace billing requires a password": {
+			"billing": map[string]any{"database": int64(1)},
+		},
+		"namespace billing requires a database number above 0": {
+			"billing": map[string]any{"password": "x"},
+		},
+		"namespace search cannot use database 1 of namespace billing": {
+			"billing": map[string]any{"password": "x", "database": int64(1)},
+			"search":  map[string]any{"password": "y", "database": int64(1)},
+		},
+	}
+	for msg, namespaces := range cases {
+		s, err := NewServer(&ServerOptions{
+			GlobalConfig:     map[string]any{"namespaces": namespaces},
+			Binding:          "localhost:0",
+			StorageDirectory: t.TempDir(),
+			ConfigDirectory:  t.TempDir(),
+			PoolSize:         DefaultMaxPoolSize,
+		})
+		assert.NoError(t, err)
+		err = s.BootWithStore(storage.NewMemoryStore())
+		assert.ErrorContains(t, err, msg)
+	}
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// Payload limits protect the server's memory from huge commands and
+// keep large jobs out of Redis and the Web UI:
+//
+//	[payloads]
+//	max_size = 16777216   # bytes in one command, e.g. a PUSH
+//	offload_size = 65536  # args larger than this many bytes are stored on disk, 0 disables
+//	offload_dir = "/var/lib/faktory/blobs" # defaults to "blobs" in the storage directory
+//	offload_ttl = 1       # days before deleting a blob which no job refers to
+//
+// An offloaded job is stored with empty args and the name of the blob
+// holding them in its "faktory.args" custom attribute.  The args are
+// restored when the job is fetched and the blob is deleted when the job
+// is acknowledged.  Blobs of jobs which are deleted or expire without
+// an ACK are reaped once no queue or set refers to them.
+type payloads struct {
+	mu          sync.Mutex
+	maxSize     int
+	offloadSize int
+	dir         string
+	ttl         time.Duration
+}
+
+const (
+	DefaultMaxCommandSize = 16 * 1024 * 1024
+
+	offloadKey = "faktory.args"
+)
+
+var (
+	blobName = regexp.MustCompile(`\A[A-Za-z0-9_-]{16}\z`)
+	blobRef  = regexp.MustCompile(`"faktory\.args":"([A-Za-z0-9_-]{16})"`)
+)
+
+// A commandTooLarge error is returned for a command longer than the
+// maximum size.  The rest of the command is discarded so the connection
+// remains usable.
+type commandTooLarge struct {
+	limit int
+}
+
+func (e *commandTooLarge) Error() string {
+	return fmt.Sprintf("TOOLARGE Command is larger than %d bytes", e.limit)
+}
+
+func (e *commandTooLarge) Code() string {
+	return "TOOLARGE"
+}
+
+func (s *Server) configurePayloads() {
+	p := &s.payloads
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.maxSize = s.Options.Int("payloads", "max_size", DefaultMaxCommandSize)
+	p.offloadSize = s.Options.Int("payloads", "offload_size", 0)
+	p.dir = s.Options.String("payloads", "offload_dir", filepath.Join(s.Options.StorageDirectory, "blobs"))
+	p.ttl = time.Duration(s.Options.Int("payloads", "offload_ttl", 1)) * 24 * time.Hour
+	if err := s.checkHAOffload(); err != nil {
+		// a reload can't refuse to boot, so don't offload
+		util.Warnf("Config error: %v", err)
+		p.offloadSize = 0
+	}
+}
+
+func (s *Server) payloadLimits() (maxSize int, offloadSize int, dir string) {
+	p := &s.payloads
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxSize, p.offloadSize, p.dir
+}
+
+// readCommand reads the next line from the client, buffering no more
+// than limit bytes.
+func readCommand(buf *bufio.Reader, limit int) (string, error) {
+	var line []byte
+	for {
+		chunk, err := buf.ReadSlice('\n')
+		if limit > 0 && len(line)+len(chunk) > limit {
+			for errors.Is(err, bufio.ErrBufferFull) {
+				_, err = buf.ReadSlice('\n')
+			}
+			if err != nil {
+				return "", err
+			}
+			return "", &commandTooLarge{limit: limit}
+		}
+		line = append(line, chunk...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(line), nil
+	}
+}
+
+// offload is the manager's Offloader, writing args larger than the
+// configured size to a blob.
+func (s *Server) offload(ctx context.Context, job *client.Job) error {
+	if _, ok := job.GetCustom(offloadKey); ok {
+		// only the server may reference blobs
+		delete(job.Custom, offloadKey)
+	}
+
+	_, offloadSize, dir := s.payloadLimits()
+	if offloadSize <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(job.Args)
+	if err != nil {
+		return fmt.Errorf("cannot marshal job args: %w", err)
+	}
+	if len(data) <= offloadSize {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("cannot create blob directory: %w", err)
+	}
+	name := client.RandomJid()
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("cannot write blob: %w", err)
+	}
+	job.Args = []any{}
+	job.SetCustom(offloadKey, name)
+	return nil
+}
+
+// blobFor returns the path of the job's offloaded args, if any.
+func (s *Server) blobFor(job *client.Job) (string, bool) {
+	val, ok := job.GetCustom(offloadKey)
+	if !ok {
+		return "", false
+	}
+	name, ok := val.(string)
+	if !ok || !blobName.MatchString(name) {
+		return "", false
+	}
+	_, _, dir := s.payloadLimits()
+	return filepath.Join(dir, name), true
+}
+
+// Offloaded returns true if the job's args are stored in a blob.
+func (s *Server) Offloaded(job *client.Job) bool {
+	_, ok := job.GetCustom(offloadKey)
+	return ok
+}
+
+// Rehydrate returns a copy of the job with its offloaded args restored
+// for the worker or the Web UI.  The stored job is unchanged.
+func (s *Server) Rehydrate(job *client.Job) *client.Job {
+	path, ok := s.blobFor(job)
+	if !ok {
+		return job
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		util.Warnw("Unable to read offloaded args", util.Fields{"jid": job.Jid, "error": err})
+		return job
+	}
+
+	var args []any
+	if err := util.JsonUnmarshal(data, &args); err != nil {
+		util.Warnw("Unable to parse offloaded args", util.Fields{"jid": job.Jid, "error": err})
+		return job
+	}
+	dup := *job
+	dup.Args = args
+	dup.Custom = maps.Clone(job.Custom)
+	delete(dup.Custom, offloadKey)
+	if len(dup.Custom) == 0 {
+		dup.Custom = nil
+	}
+	return &dup
+}
+
+// releaseBlob is the ACK middleware which deletes the job's offloaded
+// args once it has succeeded.
+func (s *Server) releaseBlob(ctx context.Context, next func() error) error {
+	mh := ctx.Value(manager.MiddlewareHelperKey).(manager.Context)
+	if path, ok := s.blobFor(mh.Job()); ok {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			util.Warnw("Unable to delete offloaded args", util.Fields{"jid": mh.Job().Jid, "error": err})
+		}
+	}
+	return next()
+}
+
+/*
+ * Deletes blobs which no job refers to, e.g. those of jobs which died
+ * and were purged.  A blob is only deleted if it's older than the
+ * configured TTL and was unreferenced in the previous run too, so a job
+ * moving between sets while we scan doesn't lose its args.
+ */
+type blobReaper struct {
+	s     *Server
+	count int64
+	// blobs which were unreferenced in the previous run
+	unreferenced map[string]bool
+}
+
+func (r *blobReaper) Name() string {
+	return "Blobs"
+}
+
+func (r *blobReaper) Execute(ctx context.Context) error {
+	p := &r.s.payloads
+	p.mu.Lock()
+	dir, ttl := p.dir, p.ttl
+	p.mu.Unlock()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	refs, err := r.s.referencedBlobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-ttl)
+	unreferenced := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !blobName.MatchString(name) || refs[name] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if !r.unreferenced[name] {
+			unreferenced[name] = true
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err == nil {
+			atomic.AddInt64(&r.count, 1)
+		}
+	}
+	r.unreferenced = unreferenced
+	return nil
+}
+
+// referencedBlobs returns the names of the blobs referred to by the
+// jobs in every namespace's queues and sets.
+func (s *Server) referencedBlobs(ctx context.Context) (map[string]bool, error) {
+	refs := map[string]bool{}
+	mark := func(data []byte) {
+		for _, m := range blobRef.FindAllSubmatch(data, -1) {
+			refs[string(m[1])] = true
+		}
+	}
+
+	for _, ns := range s.Namespaces() {
+		store := ns.Store()
+		var err error
+		store.EachQueue(ctx, func(q storage.Queue) {
+			if err != nil {
+				return
+			}
+			err = q.Each(ctx, func(_ int, data []byte) error {
+				mark(data)
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// working holds the reservations of jobs being processed
+		for _, set := range []storage.SortedSet{store.Scheduled(), store.Retries(), store.Dead(), store.Working()} {
+			err := set.Each(ctx, func(_ int, entry storage.SortedEntry) error {
+				mark(entry.Value())
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return refs, nil
+}
+
+func (r *blobReaper) Stats(context.Context) map[string]any {
+	return map[string]any{
+		"reaped": atomic.LoadInt64(&r.count),
+	}
+}
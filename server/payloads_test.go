@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// runMemoryServer runs a server backed by the in-memory store with the
+// given global config.
+func runMemoryServer(t *testing.T, config map[string]any, runner func(*Server, *client.Client)) {
+	opts := &ServerOptions{
+		GlobalConfig:     config,
+		Binding:          "localhost:0",
+		StorageDirectory: t.TempDir(),
+		ConfigDirectory:  t.TempDir(),
+		PoolSize:         DefaultMaxPoolSize,
+	}
+	s, err := NewServer(opts)
+	assert.NoError(t, err)
+	assert.NoError(t, s.BootWithStore(storage.NewMemoryStore()))
+	go func() {
+		_ = s.Run()
+	}()
+	defer func() {
+		s.Shutdown()
+		s.Stop(nil)
+	}()
+
+	srv := client.DefaultServer()
+	srv.Address = s.Addr().String()
+	cl, err := client.Dial(srv, "")
+	assert.NoError(t, err)
+	defer cl.Close()
+
+	runner(s, cl)
+}
+
+func TestReadCommand(t *testing.T) {
+	input := "short\r\n" + strings.Repeat("x", 100) + "\r\nnext\r\nlast"
+	buf := bufio.NewReaderSize(strings.NewReader(input), 16)
+
+	line, err := readCommand(buf, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, "short\r\n", line)
+
+	_, err = readCommand(buf, 50)
+	assert.EqualError(t, err, "TOOLARGE Command is larger than 50 bytes")
+
+	line, err = readCommand(buf, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, "next\r\n", line)
+
+	_, err = readCommand(buf, 50)
+	assert.Error(t, err)
+
+	// no limit
+	buf = bufio.NewReaderSize(strings.NewReader(strings.Repeat("y", 100)+"\n"), 16)
+	line, err = readCommand(buf, 0)
+	assert.NoError(t, err)
+	assert.Len(t, line, 101)
+}
+
+func TestPayloads(t *testing.T) {
+	config := map[string]any{
+		"payloads": map[string]any{
+			"max_size":     int64(1024),
+			"offload_size": int64(100),
+		},
+	}
+	runMemoryServer(t, config, func(s *Server, cl *client.Client) {
+		ctx := context.Background()
+		_, _, dir := s.payloadLimits()
+
+		t.Run("MaxSize", func(t *testing.T) {
+			err := cl.Push(client.NewJob("HugeJob", strings.Repeat("x", 2000)))
+			assert.EqualError(t, err, "TOOLARGE Command is larger than 1024 bytes")
+
+			// the connection is still usable
+			assert.NoError(t, cl.Push(client.NewJob("SmallJob", 1)))
+			job, err := cl.Fetch("default")
+			assert.NoError(t, err)
+			assert.Equal(t, "SmallJob", job.Type)
+			assert.Equal(t, []any{float64(1)}, job.Args)
+			assert.NoError(t, cl.Ack(job.Jid))
+		})
+
+		t.Run("Offload", func(t *testing.T) {
+			big := strings.Repeat("y", 500)
+			job := client.NewJob("BigJob", big, 2)
+			job.SetCustom("tenant", "acme")
+			assert.NoError(t, cl.Push(job))
+
+			q, _ := s.Store().ExistingQueue(ctx, "default")
+			var stored client.Job
+			assert.NoError(t, q.Each(ctx, func(_ int, data []byte) error {
+				return util.JsonUnmarshal(data, &stored)
+			}))
+			assert.Equal(t, []any{}, stored.Args)
+			name, ok := stored.GetCustom(offloadKey)
+			assert.True(t, ok)
+			blob := filepath.Join(dir, name.(string))
+			assert.FileExists(t, blob)
+
+			fetched, err := cl.Fetch("default")
+			assert.NoError(t, err)
+			assert.Equal(t, []any{big, float64(2)}, fetched.Args)
+			assert.Equal(t, map[string]any{"tenant": "acme"}, fetched.Custom)
+
+			// failures keep the blob for the retry
+			assert.NoError(t, cl.Fail(fetched.Jid, os.ErrInvalid, nil))
+			assert.FileExists(t, blob)
+			count, err := s.Manager().RetryJobs(ctx, time.Now().Add(time.Hour))
+			assert.NoError(t, err)
+			assert.EqualValues(t, 1, count)
+
+			fetched, err = cl.Fetch("default")
+			assert.NoError(t, err)
+			assert.Equal(t, []any{big, float64(2)}, fetched.Args)
+			assert.NoError(t, cl.Ack(fetched.Jid))
+			assert.NoFileExists(t, blob)
+		})
+
+		t.Run("ForgedBlob", func(t *testing.T) {
+			job := client.NewJob("SmallJob", 1)
+			job.SetCustom(offloadKey, "../../etc/passwd")
+			assert.NoError(t, cl.Push(job))
+
+			fetched, err := cl.Fetch("default")
+			assert.NoError(t, err)
+			assert.Equal(t, []any{float64(1)}, fetched.Args)
+			_, ok := fetched.GetCustom(offloadKey)
+			assert.False(t, ok)
+			assert.NoError(t, cl.Ack(fetched.Jid))
+		})
+
+		t.Run("Reaper", func(t *testing.T) {
+			assert.NoError(t, os.MkdirAll(dir, 0o700))
+			old := filepath.Join(dir, client.RandomJid())
+			assert.NoError(t, os.WriteFile(old, []byte("[]"), 0o600))
+			assert.NoError(t, os.Chtimes(old, time.Now(), time.Now().Add(-200*24*time.Hour)))
+			recent := filepath.Join(dir, client.RandomJid())
+			assert.NoError(t, os.WriteFile(recent, []byte("[]"), 0o600))
+
+			// a dead job keeps its blob however old
+			dead := client.NewJob("BigJob", 1)
+			dead.Args = []any{}
+			dead.At = util.Thens(time.Now())
+			dead.SetCustom(offloadKey, client.RandomJid())
+			kept := filepath.Join(dir, dead.Custom[offloadKey].(string))
+			assert.NoError(t, os.WriteFile(kept, []byte("[1]"), 0o600))
+			assert.NoError(t, os.Chtimes(kept, time.Now(), time.Now().Add(-200*24*time.Hour)))
+			assert.NoError(t, s.Store().Dead().Add(ctx, dead))
+
+			r := &blobReaper{s: s}
+			assert.NoError(t, r.Execute(ctx))
+			// unreferenced blobs survive one run
+			assert.FileExists(t, old)
+			assert.NoError(t, r.Execute(ctx))
+			assert.NoFileExists(t, old)
+			assert.FileExists(t, recent)
+			assert.FileExists(t, kept)
+			assert.EqualValues(t, 1, r.Stats(ctx)["reaped"])
+		})
+	})
+}
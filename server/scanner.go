@@ -32,7 +32,7 @@ func (s *scanner) Execute(ctx context.Context) error {
 	}
 
 	if count > 0 {
-		util.Infof("%s processed %d jobs", s.name, count)
+		util.Infow("Processed jobs", util.Fields{"task": s.name, "count": count})
 	}
 
 	end := time.Now()
@@ -43,14 +43,21 @@ type Server struct {
 	workers    *workers
 	taskRunner *taskRunner
 	stopper    chan bool
+	audit      auditLog
+	admission  admission
+	payloads   payloads
+	encryption encryption
+	namespaces []*Namespace
+	ha         ha
 
 	TLSPublicCert string
 	TLSPrivateKey string
 
 	Subsystems []Subsystem
 
-	mu     sync.Mutex
-	closed bool
+	mu       sync.Mutex
+	closed   atomic.Bool
+	stopOnce sync.Once
 }
 
 func (s *Server) useTLS() error {
@@ -77,7 +84,7 @@ func (s *Server) useTLS() error {
 	if err != nil {
 		return fmt.Errorf("unable to initialize TLS certificate: %w", err)
 	}
-	util.Infof("TLS activated with %s", publicCert)
+	util.Infow("TLS activated", util.Fields{"cert": publicCert})
 
 	s.TLSPublicCert = publicCert
 	s.TLSPrivateKey = privateKey
@@ -102,7 +109,6 @@ func NewServer(opts *ServerOptions) (*Server, error) {
 		Subsystems: []Subsystem{},
 
 		stopper: make(chan bool),
-		closed:  false,
 	}
 
 	return s, nil
@@ -121,12 +127,112 @@ func (s *Server) Manager() manager.Manager {
 }
 
 func (s *Server) Reload() {
+	s.configureManager()
+	s.configureAudit()
+	s.configureAdmission()
+	s.configurePayloads()
+	if err := s.configureEncryption(); err != nil {
+		util.Warnw("Unable to reload encryption keys", util.Fields{"error": err})
+	}
+
 	for idx := range s.Subsystems {
 		subsystem := s.Subsystems[idx]
 		if err := subsystem.Reload(s); err != nil {
-			util.Warnf("Subsystem %s returned reload error: %v", subsystem.Name(), err)
+			util.Warnw("Subsystem returned reload error", util.Fields{"subsystem": subsystem.Name(), "error": err})
+		}
+	}
+}
+
+func (s *Server) configureManager() {
+	for _, ns := range s.namespaces {
+		ns.manager.SetCompletedRetention(s.completedRetention())
+		ns.manager.SetMetricsRetention(s.metricsRetention())
+		ns.manager.SetDeadRetention(s.deadRetention())
+	}
+}
+
+// Completed job history is disabled by default:
+//
+//	[completed]
+//	enabled = true
+//	max_size = 1000 # jobs per queue
+//	max_age = 24    # hours
+func (s *Server) completedRetention() manager.CompletedRetention {
+	if !s.Options.Bool("completed", "enabled", false) {
+		return manager.CompletedRetention{}
+	}
+	return manager.CompletedRetention{
+		MaxSize: int64(s.Options.Int("completed", "max_size", 1000)),
+		MaxAge:  time.Duration(s.Options.Int("completed", "max_age", 24)) * time.Hour,
+	}
+}
+
+// Per-minute execution metrics are disabled by default:
+//
+//	[job_metrics]
+//	enabled = true
+//	retention = 60 # minutes
+func (s *Server) metricsRetention() time.Duration {
+	if !s.Options.Bool("job_metrics", "enabled", false) {
+		return 0
+	}
+	return time.Duration(s.Options.Int("job_metrics", "retention", 60)) * time.Minute
+}
+
+// Dead jobs are kept for 180 days by default with no size limit.
+// Limits can be set globally and per queue or jobtype:
+//
+//	[dead]
+//	ttl = 30          # days
+//	max_size = 100000 # jobs
+//
+//	[dead.queues.critical]
+//	ttl = 90
+//
+//	[dead.jobtypes.NoisyJob]
+//	ttl = 1
+//	max_size = 1000
+func (s *Server) deadRetention() manager.DeadRetention {
+	return manager.DeadRetention{
+		DeadLimit: manager.DeadLimit{
+			TTL:     time.Duration(s.Options.Int("dead", "ttl", 180)) * 24 * time.Hour,
+			MaxSize: int64(s.Options.Int("dead", "max_size", 0)),
+		},
+		Queues:   deadLimits(s.Options.Config("dead", "queues", nil), "queues"),
+		Jobtypes: deadLimits(s.Options.Config("dead", "jobtypes", nil), "jobtypes"),
+	}
+}
+
+func deadLimits(val any, kind string) map[string]manager.DeadLimit {
+	limits := map[string]manager.DeadLimit{}
+	if val == nil {
+		return limits
+	}
+	tables, ok := val.(map[string]any)
+	if !ok {
+		util.Warnf("Config error: dead/%s must be a table of tables, e.g. [dead.%s.name]", kind, kind)
+		return limits
+	}
+	for name, table := range tables {
+		values, ok := table.(map[string]any)
+		if !ok {
+			util.Warnf("Config error: dead/%s/%s is not a table", kind, name)
+			continue
 		}
+		dl := manager.DeadLimit{}
+		if ttl, ok := values["ttl"].(int64); ok {
+			dl.TTL = time.Duration(ttl) * 24 * time.Hour
+		} else if values["ttl"] != nil {
+			util.Warnf("Config error: dead/%s/%s/ttl is not an Integer", kind, name)
+		}
+		if size, ok := values["max_size"].(int64); ok {
+			dl.MaxSize = size
+		} else if values["max_size"] != nil {
+			util.Warnf("Config error: dead/%s/%s/max_size is not an Integer", kind, name)
+		}
+		limits[name] = dl
 	}
+	return limits
 }
 
 func (s *Server) AddTask(everySec int64, task Taskable) {
@@ -134,13 +240,42 @@ func (s *Server) AddTask(everySec int64, task Taskable) {
 }
 
 func (s *Server) Boot() error {
-	store, err := storage.Open(s.Options.RedisSock, s.Options.PoolSize)
+	var store storage.Store
+	var err error
+	if s.Options.RedisURL != "" {
+		store, err = storage.OpenURL(s.Options.RedisURL, s.Options.PoolSize)
+	} else {
+		store, err = storage.Open(s.Options.RedisSock, s.Options.PoolSize)
+	}
 	if err != nil {
 		return fmt.Errorf("cannot open redis database: %w", err)
 	}
+	return s.BootWithStore(store)
+}
 
-	err = s.useTLS()
+// BootWithStore boots the server with the given Store rather than
+// opening Redis, e.g. storage.NewMemoryStore() for tests.  The server
+// closes the store when it stops.
+func (s *Server) BootWithStore(store storage.Store) error {
+	err := s.useTLS()
 	if err != nil {
+		_ = store.Close()
+		return err
+	}
+	err = s.configureEncryption()
+	if err != nil {
+		_ = store.Close()
+		return err
+	}
+	err = s.checkHAOffload()
+	if err != nil {
+		_ = store.Close()
+		return err
+	}
+	// the standby waits here until the active server goes away
+	err = s.awaitLease(store)
+	if err != nil {
+		_ = store.Close()
 		return err
 	}
 
@@ -151,6 +286,7 @@ func (s *Server) Boot() error {
 		listener, err = net.Listen("tcp", s.Options.Binding)
 	}
 	if err != nil {
+		s.releaseLease(store)
 		_ = store.Close()
 		return fmt.Errorf("cannot listen on %s: %w", s.Options.Binding, err)
 	}
@@ -159,9 +295,22 @@ func (s *Server) Boot() error {
 	s.store = store
 	s.workers = newWorkers()
 	s.manager = manager.NewManager(store)
+	s.manager.AddMiddleware("ack", s.releaseBlob)
+	s.manager.SetOffloader(s.sealJob)
+	if err := s.bootNamespaces(); err != nil {
+		s.mu.Unlock()
+		s.releaseLease(store)
+		_ = listener.Close()
+		_ = store.Close()
+		return err
+	}
+	s.configureManager()
+	s.configureAudit()
+	s.configureAdmission()
+	s.configurePayloads()
 	s.listener = listener
-	s.stopper = make(chan bool)
 	s.startTasks()
+	s.holdLease(store)
 	s.mu.Unlock()
 
 	return nil
@@ -175,12 +324,16 @@ func (s *Server) Run() error {
 	for idx := range s.Subsystems {
 		subsystem := s.Subsystems[idx]
 		if err := subsystem.Start(s); err != nil {
-			close(s.Stopper())
+			s.Shutdown()
 			return fmt.Errorf("cannot start server subsystem %s: %w", subsystem.Name(), err)
 		}
 	}
+	// Admission control runs after the subsystems' middleware so pushes
+	// they reject, e.g. jobs failing schema validation, don't use up a
+	// producer's rate limit.
+	s.manager.AddMiddleware("push", s.admit)
 
-	util.Infof("PID %d listening at %s, press Ctrl-C to stop", os.Getpid(), s.Options.Binding)
+	util.Infow("Listening, press Ctrl-C to stop", util.Fields{"pid": os.Getpid(), "binding": s.Options.Binding})
 
 	// this is the runtime loop for the command server
 	for {
@@ -204,14 +357,32 @@ func (s *Server) Run() error {
 	}
 }
 
+// Addr returns the address the server is listening on, useful when
+// binding to port 0.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listener.Addr()
+}
+
+// Stopper is closed when the server should shut down, see Shutdown.
 func (s *Server) Stopper() chan bool {
 	return s.stopper
 }
 
+// Shutdown closes the Stopper to signal the server to stop.  It may be
+// called more than once, e.g. by a signal after the server lost its HA
+// lease.
+func (s *Server) Shutdown() {
+	s.stopOnce.Do(func() {
+		close(s.stopper)
+	})
+}
+
 func (s *Server) Stop(onStop func()) {
 	// Don't allow new network connections
 	s.mu.Lock()
-	s.closed = true
+	s.closed.Store(true)
 	if s.listener != nil {
 		_ = s.listener.Close()
 	}
@@ -223,6 +394,9 @@ func (s *Server) Stop(onStop func()) {
 		onStop()
 	}
 
+	s.audit.close()
+	s.releaseLease(s.store)
+	closeNamespaces(s.namespaces)
 	_ = s.store.Close()
 }
 
@@ -258,7 +432,7 @@ func startConnection(conn net.Conn, s *Server) *Connection {
 
 	var salt string
 	_, _ = conn.Write([]byte(`+HI {"v":2`))
-	if s.Options.Password != "" {
+	if s.Options.Password != "" || len(s.namespaces) > 1 {
 		_, _ = conn.Write([]byte(`,"i":`))
 		iters := strconv.FormatInt(int64(iter), 10)
 		_, _ = conn.Write([]byte(iters))
@@ -273,7 +447,8 @@ func startConnection(conn net.Conn, s *Server) *Connection {
 
 	buf := bufio.NewReader(conn)
 
-	line, err := buf.ReadString('\n')
+	maxSize, _, _ := s.payloadLimits()
+	line, err := readCommand(buf, maxSize)
 	if err != nil {
 		defer conn.Close()
 		// TCP probes on the socket will close connection
@@ -293,31 +468,41 @@ func startConnection(conn net.Conn, s *Server) *Connection {
 			return nil
 
 		}
-		util.Infof("Bad connection %T: %v", err, err)
+		util.Infow("Bad connection", util.Fields{"remote": conn.RemoteAddr().String(), "type": fmt.Sprintf("%T", err), "error": err})
 		return nil
 	}
 
 	valid := strings.HasPrefix(line, "HELLO {")
 	if !valid {
-		util.Debugf("Invalid preamble: %s", line)
-		util.Debug("Need a valid HELLO, is client using TLS?")
+		util.Debugw("Invalid preamble, need a valid HELLO, is client using TLS?", util.Fields{"remote": conn.RemoteAddr().String(), "line": line})
 		_ = conn.Close()
 		return nil
 	}
 
 	cl, err := clientDataFromHello(line[5:])
 	if err != nil {
-		util.Error("Invalid client data in HELLO", err)
+		util.Errorw("Invalid client data in HELLO", err, util.Fields{"remote": conn.RemoteAddr().String()})
 		_ = conn.Close()
 		return nil
 	}
 
-	if s.Options.Password != "" {
+	password := s.Options.Password
+	if cl.Namespace != "" {
+		ns := s.Namespace(cl.Namespace)
+		if ns == nil {
+			_, _ = conn.Write([]byte("-ERR Unknown namespace\r\n"))
+			_ = conn.Close()
+			return nil
+		}
+		password = ns.password
+	}
+
+	if password != "" {
 		if cl.Version < 2 {
 			iter = 1
 		}
 
-		if subtle.ConstantTimeCompare([]byte(cl.PasswordHash), []byte(hash(s.Options.Password, salt, iter))) != 1 {
+		if subtle.ConstantTimeCompare([]byte(cl.PasswordHash), []byte(hash(password, salt, iter))) != 1 {
 			_, _ = conn.Write([]byte("-ERR Invalid password\r\n"))
 			_ = conn.Close()
 			return nil
@@ -325,9 +510,10 @@ func startConnection(conn net.Conn, s *Server) *Connection {
 	}
 
 	cn := &Connection{
-		client: cl,
-		conn:   conn,
-		buf:    buf,
+		client:     cl,
+		conn:       conn,
+		buf:        buf,
+		remoteAddr: conn.RemoteAddr().String(),
 	}
 
 	if cl.Wid == "" {
@@ -338,7 +524,7 @@ func startConnection(conn net.Conn, s *Server) *Connection {
 
 	_, err = conn.Write([]byte("+OK\r\n"))
 	if err != nil {
-		util.Error("Closing connection", err)
+		util.Errorw("Closing connection", err, util.Fields{"remote": cn.remoteAddr, "wid": cl.Wid})
 		_ = conn.Close()
 		return nil
 	}
@@ -361,24 +547,29 @@ func (s *Server) processLines(conn *Connection) {
 	if s.Stats.Connections > s.Options.PoolSize {
 		if client.Name == "Faktory" {
 			// This will trigger in Faktory OSS if over the default max pool size.
-			util.Warnf("%s has over %d active client connections and may exhibit poor performance. Ensure your worker processes are using a connection pool and closing unused connections.", client.Name, s.Options.PoolSize)
+			util.Warnw(client.Name+" has too many active client connections and may exhibit poor performance. Ensure your worker processes are using a connection pool and closing unused connections.", util.Fields{"limit": s.Options.PoolSize})
 		} else {
 			// This will trigger in Faktory Enterprise if over the licensed connection count.
-			util.Warnf("%s has over %d active client connections and may exhibit poor performance. Ensure your worker processes are using no more than your licensed connection count.", client.Name, s.Options.PoolSize)
+			util.Warnw(client.Name+" has too many active client connections and may exhibit poor performance. Ensure your worker processes are using no more than your licensed connection count.", util.Fields{"limit": s.Options.PoolSize})
 		}
 		_ = conn.Error("Overloaded", fmt.Errorf("too many connections: %d", s.Stats.Connections))
 		return
 	}
 
 	for {
-		cmd, e := conn.buf.ReadString('\n')
+		maxSize, _, _ := s.payloadLimits()
+		cmd, e := readCommand(conn.buf, maxSize)
+		if tooLarge, ok := e.(*commandTooLarge); ok {
+			_ = conn.Error("", tooLarge)
+			continue
+		}
 		if e != nil {
 			if e != io.EOF {
-				util.Error("Unexpected socket error", e)
+				util.Errorw("Unexpected socket error", e, util.Fields{"remote": conn.remoteAddr})
 			}
 			return
 		}
-		if s.closed {
+		if s.closed.Load() {
 			_ = conn.Error("Closing connection", fmt.Errorf("shutdown in progress"))
 			return
 		}
@@ -397,7 +588,7 @@ func (s *Server) processLines(conn *Connection) {
 		} else {
 			atomic.AddUint64(&s.Stats.Commands, 1)
 			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5*time.Second))
-			conn.Context = ctx
+			conn.Context = context.WithValue(ctx, connectionKey{}, conn)
 			safeDispatch(proc, conn, s, cmd)
 			cancel()
 		}
@@ -410,7 +601,11 @@ func (s *Server) processLines(conn *Connection) {
 func safeDispatch(proc command, conn *Connection, s *Server, cmd string) {
 	defer func() {
 		if r := recover(); r != nil {
-			util.Error("panic handling command", fmt.Errorf("%v: `%s`", r, cmd))
+			fields := util.Fields{"command": cmd}
+			if conn.client != nil {
+				fields["wid"] = conn.client.Wid
+			}
+			util.Errorw("panic handling command", fmt.Errorf("%v", r), fields)
 			_ = conn.Error(cmd, fmt.Errorf("internal error"))
 		}
 	}()
@@ -421,52 +616,43 @@ func (s *Server) uptimeInSeconds() uint64 {
 	return uint64(time.Since(s.Stats.StartedAt).Seconds())
 }
 
+// CurrentState returns the state of the default namespace.
 func (s *Server) CurrentState() (*client.FaktoryState, error) {
+	return s.namespaces[0].CurrentState()
+}
+
+// CurrentState returns the server's state with the jobs and metrics of
+// the namespace.
+func (ns *Namespace) CurrentState() (*client.FaktoryState, error) {
+	s := ns.server
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	queueCmd := map[string]*redis.IntCmd{}
-	setCmd := map[string]*redis.IntCmd{}
-	_, err := s.store.Redis().Pipelined(ctx, func(pipe redis.Pipeliner) error {
-		s.store.EachQueue(ctx, func(q storage.Queue) {
-			queueCmd[q.Name()] = pipe.LLen(ctx, "q:"+q.Name())
-		})
-		setCmd["scheduled"] = pipe.ZCard(ctx, "scheduled")
-		setCmd["retries"] = pipe.ZCard(ctx, "retries")
-		setCmd["dead"] = pipe.ZCard(ctx, "dead")
-		setCmd["working"] = pipe.ZCard(ctx, "working")
-		setCmd["failures"] = pipe.IncrBy(ctx, "failures", 0)
-		setCmd["processed"] = pipe.IncrBy(ctx, "processed", 0)
-		return nil
-	})
+	queues, sets, err := ns.counts(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	queues := map[string]uint64{}
 	totalQueued := uint64(0)
-	totalQueues := len(queueCmd)
-	for name, cmd := range queueCmd {
-		qsize, _ := cmd.Uint64()
+	for _, qsize := range queues {
 		totalQueued += qsize
-		queues[name] = qsize
 	}
 
 	snap := &client.FaktoryState{
 		Now:           util.Nows(),
 		ServerUtcTime: time.Now().UTC().Format("15:04:05 UTC"),
 		Data: client.DataSnapshot{
-			TotalFailures:  size(setCmd["failures"]),
-			TotalProcessed: size(setCmd["processed"]),
+			TotalFailures:  sets["failures"],
+			TotalProcessed: sets["processed"],
 			TotalEnqueued:  totalQueued,
-			TotalQueues:    uint64(totalQueues),
+			TotalQueues:    uint64(len(queues)),
 			Queues:         queues,
 			Tasks:          s.taskRunner.Stats(),
 			Sets: map[string]uint64{
-				"scheduled": size(setCmd["scheduled"]),
-				"retries":   size(setCmd["retries"]),
-				"dead":      size(setCmd["dead"]),
-				"working":   size(setCmd["working"]),
+				"scheduled": sets["scheduled"],
+				"retries":   sets["retries"],
+				"dead":      sets["dead"],
+				"working":   sets["working"],
 			},
 		},
 		Server: client.ServerSnapshot{
@@ -478,9 +664,65 @@ func (s *Server) CurrentState() (*client.FaktoryState, error) {
 			UsedMemoryMB: util.MemoryUsageMB(),
 		},
 	}
+
+	if ns.manager.MetricsRetention() > 0 {
+		metrics, err := ns.metricsSnapshot(ctx, MetricsSnapshotWindow)
+		if err != nil {
+			return nil, err
+		}
+		snap.Data.Metrics = metrics
+	}
+	if rejected := ns.RejectedPushes(); len(rejected) > 0 {
+		snap.Data.Rejected = rejected
+	}
 	return snap, nil
 }
 
+// counts returns the size of each queue along with the sizes of the
+// sorted sets and the processed and failure totals.
+func (ns *Namespace) counts(ctx context.Context) (map[string]uint64, map[string]uint64, error) {
+	store := ns.store
+	queues := map[string]uint64{}
+	sets := map[string]uint64{}
+	if store.Redis() == nil {
+		store.EachQueue(ctx, func(q storage.Queue) {
+			queues[q.Name()] = q.Size(ctx)
+		})
+		sets["scheduled"] = store.Scheduled().Size(ctx)
+		sets["retries"] = store.Retries().Size(ctx)
+		sets["dead"] = store.Dead().Size(ctx)
+		sets["working"] = store.Working().Size(ctx)
+		sets["failures"] = store.TotalFailures(ctx)
+		sets["processed"] = store.TotalProcessed(ctx)
+		return queues, sets, nil
+	}
+
+	queueCmd := map[string]*redis.IntCmd{}
+	setCmd := map[string]*redis.IntCmd{}
+	_, err := store.Redis().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		store.EachQueue(ctx, func(q storage.Queue) {
+			queueCmd[q.Name()] = pipe.LLen(ctx, "q:"+q.Name())
+		})
+		setCmd["scheduled"] = pipe.ZCard(ctx, "scheduled")
+		setCmd["retries"] = pipe.ZCard(ctx, "retries")
+		setCmd["dead"] = pipe.ZCard(ctx, "dead")
+		setCmd["working"] = pipe.ZCard(ctx, "working")
+		setCmd["failures"] = pipe.IncrBy(ctx, "failures", 0)
+		setCmd["processed"] = pipe.IncrBy(ctx, "processed", 0)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, cmd := range queueCmd {
+		queues[name] = size(cmd)
+	}
+	for name, cmd := range setCmd {
+		sets[name] = size(cmd)
+	}
+	return queues, sets, nil
+}
+
 func size(cmd *redis.IntCmd) uint64 {
 	s, _ := cmd.Uint64()
 	return s
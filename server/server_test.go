@@ -172,6 +172,33 @@ func TestPasswordHashing(t *testing.T) {
 	assert.Equal(t, "6d877f8e5544b1f2598768f817413ab8a357afffa924dedae99eb91472d4ec30", result)
 }
 
+func TestDeadRetentionConfig(t *testing.T) {
+	s := &Server{Options: &ServerOptions{GlobalConfig: map[string]any{}}}
+	dr := s.deadRetention()
+	assert.Equal(t, 180*24*time.Hour, dr.TTL)
+	assert.EqualValues(t, 0, dr.MaxSize)
+	assert.Empty(t, dr.Queues)
+
+	s.Options.GlobalConfig["dead"] = map[string]any{
+		"ttl":      int64(30),
+		"max_size": int64(10000),
+		"queues": map[string]any{
+			"critical": map[string]any{"ttl": int64(90)},
+		},
+		"jobtypes": map[string]any{
+			"NoisyJob": map[string]any{"ttl": int64(1), "max_size": int64(100)},
+			"BadJob":   map[string]any{"ttl": "forever"},
+		},
+	}
+	dr = s.deadRetention()
+	assert.Equal(t, 30*24*time.Hour, dr.TTL)
+	assert.EqualValues(t, 10000, dr.MaxSize)
+	assert.Equal(t, 90*24*time.Hour, dr.Queues["critical"].TTL)
+	assert.Equal(t, 24*time.Hour, dr.Jobtypes["NoisyJob"].TTL)
+	assert.EqualValues(t, 100, dr.Jobtypes["NoisyJob"].MaxSize)
+	assert.EqualValues(t, 0, dr.Jobtypes["BadJob"].TTL)
+}
+
 func BenchmarkHash(b *testing.B) {
 	for b.Loop() {
 		// 1550 µs per call with 5545 iterations
@@ -101,7 +101,7 @@ func (ts *taskRunner) cycle() {
 		err := t.runner.Execute(context.Background())
 		tend := time.Now()
 		if err != nil {
-			util.Warnf("Error running task %s: %v", t.runner.Name(), err)
+			util.Warnw("Error running task", util.Fields{"task": t.runner.Name(), "error": err})
 		}
 		atomic.AddInt64(&t.runs, 1)
 		atomic.AddInt64(&t.walltimeNs, tend.Sub(tstart).Nanoseconds())
@@ -115,15 +115,28 @@ func (ts *taskRunner) cycle() {
 
 func (s *Server) startTasks() {
 	ts := newTaskRunner()
-	// scan the various sets, looking for things to do
-	ts.AddTask(5, &scanner{name: "Scheduled", set: s.store.Scheduled(), task: s.manager.EnqueueScheduledJobs})
-	ts.AddTask(5, &scanner{name: "Retries", set: s.store.Retries(), task: s.manager.RetryJobs})
-	ts.AddTask(60, &scanner{name: "Dead", set: s.store.Dead(), task: s.manager.Purge})
-
-	// reaps job reservations which have expired
-	ts.AddTask(15, &reservationReaper{s.manager, 0})
+	for _, ns := range s.namespaces {
+		add := ts.AddTask
+		if ns.Name != "" {
+			add = func(sec int64, thing Taskable) {
+				ts.AddTask(sec, &namespacedTask{ns.Name, thing})
+			}
+		}
+		store, m := ns.store, ns.manager
+		// scan the various sets, looking for things to do
+		add(5, &scanner{name: "Scheduled", set: store.Scheduled(), task: m.EnqueueScheduledJobs})
+		add(5, &scanner{name: "Retries", set: store.Retries(), task: m.RetryJobs})
+		add(60, &scanner{name: "Dead", set: store.Dead(), task: m.Purge})
+		// trims the completed job history
+		add(60, &completedReaper{m, 0})
+
+		// reaps job reservations which have expired
+		add(15, &reservationReaper{m, 0})
+	}
 	// reaps workers who have not heartbeated
 	ts.AddTask(15, &beatReaper{s.workers, 0})
+	// deletes offloaded args which were never acknowledged
+	ts.AddTask(3600, &blobReaper{s: s})
 
 	ts.Run(s.Stopper())
 	s.taskRunner = ts
@@ -58,3 +58,40 @@ func (r *beatReaper) Stats(context.Context) map[string]any {
 		"reaped": atomic.LoadInt64(&r.count),
 	}
 }
+
+/*
+ * Trims the completed job history to the configured retention.
+ */
+type completedReaper struct {
+	m     manager.Manager
+	count int64
+}
+
+func (r *completedReaper) Name() string {
+	return "Completed"
+}
+
+func (r *completedReaper) Execute(ctx context.Context) error {
+	count, err := r.m.PurgeCompleted(ctx, time.Now())
+	atomic.AddInt64(&r.count, count)
+	return err
+}
+
+func (r *completedReaper) Stats(context.Context) map[string]any {
+	return map[string]any{
+		"enabled": r.m.CompletedRetention().Enabled(),
+		"reaped":  atomic.LoadInt64(&r.count),
+	}
+}
+
+/*
+ * Runs a task for a namespace, naming it after the namespace.
+ */
+type namespacedTask struct {
+	namespace string
+	Taskable
+}
+
+func (t *namespacedTask) Name() string {
+	return t.namespace + "/" + t.Taskable.Name()
+}
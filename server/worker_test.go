@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientManager(t *testing.T) {
+	runServer("localhost:7426", func(s *Server) {
+		bg := context.Background()
+		srv := &client.Server{Network: "tcp", Address: "localhost:7426", Timeout: time.Second}
+		cl, err := client.Dial(srv, "")
+		assert.NoError(t, err)
+		defer cl.Close()
+
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, cl.Push(client.NewJob("Count", i)))
+		}
+		assert.NoError(t, cl.Push(client.NewJob("Fails")))
+
+		var count atomic.Int64
+		mgr := client.NewManager()
+		mgr.Concurrency = 3
+		mgr.BeatInterval = 50 * time.Millisecond
+		mgr.Pool, err = client.NewPoolWithClientConstructor(5, srv.Open)
+		assert.NoError(t, err)
+		mgr.Register("Count", func(ctx context.Context, args ...any) error {
+			count.Add(1)
+			return nil
+		})
+		mgr.Register("Fails", func(ctx context.Context, args ...any) error {
+			panic("oops")
+		})
+
+		done := make(chan error)
+		go func() { done <- mgr.Run(bg) }()
+
+		assert.Eventually(t, func() bool {
+			return s.Store().TotalProcessed(bg) == 11
+		}, 5*time.Second, 20*time.Millisecond)
+		assert.EqualValues(t, 10, count.Load())
+		assert.EqualValues(t, 1, s.Store().TotalFailures(bg))
+		assert.EqualValues(t, 1, s.Store().Retries().Size(bg))
+
+		// terminate the process from the server, like the Busy page
+		s.workers.mu.Lock()
+		worker := s.workers.heartbeats[client.RandomProcessWid]
+		assert.NotNil(t, worker)
+		worker.Signal(Terminate)
+		s.workers.mu.Unlock()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Manager did not terminate")
+		}
+	})
+}
@@ -56,6 +56,7 @@ type ClientData struct {
 	Wid           string   `json:"wid"`
 	PasswordHash  string   `json:"pwdhash"`
 	Username      string   `json:"username"`
+	Namespace     string   `json:"namespace"`
 	Labels        []string `json:"labels"`
 	Pid           int      `json:"pid"`
 	RssKb         int64    `json:"rss_kb"`
@@ -243,9 +244,9 @@ func (w *workers) reapHeartbeats(t time.Time) int {
 			delete(w.heartbeats, toDelete[idx])
 		}
 
-		util.Debugf("Reaped %d worker heartbeats", count)
+		util.Debugw("Reaped worker heartbeats", util.Fields{"count": count})
 		if conns > 0 {
-			util.Warnf("Reaped %d lingering connections, this is a sign your workers are having problems", conns)
+			util.Warnw("Reaped lingering connections, this is a sign your workers are having problems", util.Fields{"count": conns})
 			util.Warn("All worker processes should send a heartbeat every 15 seconds")
 		}
 	}
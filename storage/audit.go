@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/contribsys/faktory/util"
+)
+
+const auditKey = "audit"
+
+// AuditEntry records a single administrative action.
+type AuditEntry struct {
+	At time.Time `json:"at"`
+	// Actor is the connection's username and hostname or the
+	// Web UI user who performed the action.
+	Actor   string `json:"actor"`
+	Remote  string `json:"remote,omitempty"`
+	Command string `json:"command"`
+	Target  string `json:"target,omitempty"`
+	// Count is the number of jobs affected by the action.
+	Count uint64 `json:"count"`
+}
+
+// The audit log is a Redis list with the newest entry first,
+// trimmed to maxSize entries.
+func (store *redisStore) RecordAudit(ctx context.Context, entry AuditEntry, maxSize int64) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	pipe := store.rclient.TxPipeline()
+	pipe.LPush(ctx, auditKey, data)
+	if maxSize > 0 {
+		pipe.LTrim(ctx, auditKey, 0, maxSize-1)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (store *redisStore) AuditSize(ctx context.Context) uint64 {
+	return uint64(store.rclient.LLen(ctx, auditKey).Val()) // nolint:gosec
+}
+
+func (store *redisStore) AuditLog(ctx context.Context, start int64, count int64) ([]AuditEntry, error) {
+	values, err := store.rclient.LRange(ctx, auditKey, start, start+count-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]AuditEntry, 0, len(values))
+	for idx := range values {
+		var entry AuditEntry
+		err := util.JsonUnmarshal([]byte(values[idx]), &entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GlobPattern converts a Redis glob-style pattern into the equivalent
+// regexp: `*` matches any text, `?` any character, `[...]` a class of
+// characters and `\` escapes the next character.
+func GlobPattern(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString(`(?s)\A`)
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; ch {
+		case '*':
+			sb.WriteString(`.*`)
+		case '?':
+			sb.WriteString(`.`)
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			sb.WriteString("[" + pattern[i+1:i+1+end] + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	sb.WriteString(`\z`)
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{"*", `{"jid":"abc"}`, true},
+		{`*"jobtype":"Foo"*`, `{"jid":"abc","jobtype":"Foo"}`, true},
+		{`*"jobtype":"Foo"*`, `{"jid":"abc","jobtype":"FooBar"}`, false},
+		{"*user_?2*", `{"args":["user_12"]}`, true},
+		{"*user_?2*", `{"args":["user_123"]}`, true},
+		{"*user_[0-1]3*", `{"args":["user_23"]}`, false},
+		{"*user_[^0-1]3*", `{"args":["user_23"]}`, true},
+		{`*a\*b*`, `{"args":["a*b"]}`, true},
+		{`*a\*b*`, `{"args":["axb"]}`, false},
+		{"*(.)*", `{"args":["(.)"]}`, true},
+		{"*[*", `{"args":["["]}`, true},
+		{"*line*", "{\"args\":[\"multi\nline\"]}", true},
+	}
+	for _, tc := range cases {
+		re, err := GlobPattern(tc.pattern)
+		assert.NoError(t, err, tc.pattern)
+		assert.Equal(t, tc.match, re.MatchString(tc.value), "%s =~ %s", tc.value, tc.pattern)
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 func TestStats(t *testing.T) {
-	withRedis(t, "history", func(t *testing.T, store Store) {
+	withStores(t, "history", func(t *testing.T, store Store) {
 		bg := context.Background()
 		_ = store.Flush(bg)
 		var err error
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	leasePrefix = "lease:"
+)
+
+var (
+	// Sets KEYS[1] to the owner ARGV[1] with a TTL of ARGV[2] milliseconds
+	// if it's unset or already held by the owner.
+	acquireLeaseScript = redis.NewScript(`
+local owner = redis.call("get", KEYS[1])
+if owner == false or owner == ARGV[1] then
+  redis.call("set", KEYS[1], ARGV[1], "px", ARGV[2])
+  return 1
+end
+return 0
+`)
+
+	// Deletes KEYS[1] only if it's held by the owner ARGV[1].
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+  return redis.call("del", KEYS[1])
+end
+return 0
+`)
+)
+
+// A lease is a Redis key holding its owner's name which expires unless
+// the owner renews it in time.  Leases survive Flush.
+func (store *redisStore) AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	ok, err := acquireLeaseScript.Run(ctx, store.rclient, []string{leasePrefix + name}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return ok == 1, nil
+}
+
+func (store *redisStore) ReleaseLease(ctx context.Context, name string, owner string) error {
+	return releaseLeaseScript.Run(ctx, store.rclient, []string{leasePrefix + name}, owner).Err()
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLease(t *testing.T) {
+	withStores(t, "lease", func(t *testing.T, store Store) {
+		bg := context.Background()
+		_ = store.ReleaseLease(bg, "leader", "a")
+		_ = store.ReleaseLease(bg, "leader", "b")
+
+		ok, err := store.AcquireLease(bg, "leader", "a", time.Second)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = store.AcquireLease(bg, "leader", "b", time.Second)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		// the owner renews
+		ok, err = store.AcquireLease(bg, "leader", "a", 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		// only the owner can release
+		assert.NoError(t, store.ReleaseLease(bg, "leader", "b"))
+		ok, err = store.AcquireLease(bg, "leader", "b", time.Second)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		// a FLUSH doesn't hand the lease to someone else
+		assert.NoError(t, store.Flush(bg))
+		ok, err = store.AcquireLease(bg, "leader", "b", time.Second)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		time.Sleep(100 * time.Millisecond)
+		ok, err = store.AcquireLease(bg, "leader", "b", time.Second)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.NoError(t, store.ReleaseLease(bg, "leader", "b"))
+		ok, err = store.AcquireLease(bg, "leader", "a", time.Second)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,409 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// memoryStore keeps everything in process memory.  It's meant for
+// tests which can't run redis-server, see the faktorytest package, and
+// mirrors the Redis store's semantics.  A single mutex guards all data;
+// callbacks are always invoked without holding it.
+type memoryStore struct {
+	mu        sync.Mutex
+	queues    map[string]*memoryQueue
+	paused    map[string]bool
+	scheduled *memorySorted
+	retries   *memorySorted
+	dead      *memorySorted
+	working   *memorySorted
+	completed map[string]*memorySorted
+	counters  map[string]int64
+	kv        map[string][]byte
+	audit     []AuditEntry
+	metrics   map[string]*memoryMinute
+	leases    map[string]memoryLease
+
+	// closed and replaced whenever a job is pushed to wake up BPop
+	pushed chan struct{}
+}
+
+type memoryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type memoryMinute struct {
+	metrics   *MinuteMetrics
+	expiresAt time.Time
+}
+
+func NewMemoryStore() Store {
+	store := &memoryStore{
+		queues:    map[string]*memoryQueue{},
+		paused:    map[string]bool{},
+		completed: map[string]*memorySorted{},
+		counters:  map[string]int64{},
+		kv:        map[string][]byte{},
+		metrics:   map[string]*memoryMinute{},
+		leases:    map[string]memoryLease{},
+		pushed:    make(chan struct{}),
+	}
+	store.scheduled = &memorySorted{name: "scheduled", store: store}
+	store.retries = &memorySorted{name: "retries", store: store}
+	store.dead = &memorySorted{name: "dead", store: store}
+	store.working = &memorySorted{name: "working", store: store}
+	return store
+}
+
+func (store *memoryStore) Close() error {
+	return nil
+}
+
+// Redis returns nil, callers must fall back to the Store interface.
+func (store *memoryStore) Redis() *redis.Client {
+	return nil
+}
+
+func (store *memoryStore) Stats(ctx context.Context) map[string]string {
+	return map[string]string{
+		"stats": "",
+		"name":  "memory",
+	}
+}
+
+func (store *memoryStore) Retries() SortedSet {
+	return store.retries
+}
+
+func (store *memoryStore) Scheduled() SortedSet {
+	return store.scheduled
+}
+
+func (store *memoryStore) Working() SortedSet {
+	return store.working
+}
+
+func (store *memoryStore) Dead() SortedSet {
+	return store.dead
+}
+
+func (store *memoryStore) Completed(queue string) SortedSet {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	sset, ok := store.completed[queue]
+	if !ok {
+		sset = &memorySorted{name: "completed:" + queue, store: store}
+		store.completed[queue] = sset
+	}
+	return sset
+}
+
+func (store *memoryStore) CompletedQueues(ctx context.Context) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	names := []string{}
+	for name, sset := range store.completed {
+		if len(sset.elements) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (store *memoryStore) ExistingQueue(_ context.Context, name string) (Queue, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	q, ok := store.queues[name]
+	return q, ok
+}
+
+func (store *memoryStore) GetQueue(ctx context.Context, name string) (Queue, error) {
+	if name == "" {
+		return nil, fmt.Errorf("queue name cannot be blank")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	q, ok := store.queues[name]
+	if ok {
+		return q, nil
+	}
+	if !ValidQueueName.MatchString(name) {
+		return nil, fmt.Errorf("queue names must match %v", ValidQueueName)
+	}
+
+	q = &memoryQueue{name: name, store: store}
+	store.queues[name] = q
+	return q, nil
+}
+
+func (store *memoryStore) EachQueue(ctx context.Context, fn func(Queue)) {
+	store.mu.Lock()
+	queues := make([]Queue, 0, len(store.queues))
+	for _, q := range store.queues {
+		queues = append(queues, q)
+	}
+	store.mu.Unlock()
+
+	for _, q := range queues {
+		fn(q)
+	}
+}
+
+func (store *memoryStore) PausedQueues(ctx context.Context) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	names := []string{}
+	for name := range store.paused {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (store *memoryStore) EnqueueAll(ctx context.Context, sset SortedSet) error {
+	return enqueueAll(ctx, store, sset)
+}
+
+func (store *memoryStore) EnqueueFrom(ctx context.Context, sset SortedSet, key []byte) error {
+	return enqueueFrom(ctx, store, sset, key)
+}
+
+// Flush clears all data, like FLUSHDB.  Known queues remain but are
+// emptied; leases and the audit log are kept, as with the Redis store.
+func (store *memoryStore) Flush(ctx context.Context) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, q := range store.queues {
+		q.items = nil
+	}
+	for _, sset := range []*memorySorted{store.scheduled, store.retries, store.dead, store.working} {
+		sset.clear()
+	}
+	for _, sset := range store.completed {
+		sset.clear()
+	}
+	store.paused = map[string]bool{}
+	store.counters = map[string]int64{}
+	store.kv = map[string][]byte{}
+	store.metrics = map[string]*memoryMinute{}
+	return nil
+}
+
+// There is nothing to migrate in memory so the data is always at the
+// latest version.
+func (store *memoryStore) DataVersion(context.Context) (int64, error) {
+	return int64(len(Migrations)), nil
+}
+
+func (store *memoryStore) ApplyMigrations(ctx context.Context) (int64, error) {
+	return store.DataVersion(ctx)
+}
+
+func (store *memoryStore) Raw() KV {
+	return &memoryKV{store}
+}
+
+type memoryKV struct {
+	store *memoryStore
+}
+
+func (kv *memoryKV) Get(ctx context.Context, key string) ([]byte, error) {
+	kv.store.mu.Lock()
+	defer kv.store.mu.Unlock()
+	return kv.store.kv[key], nil
+}
+
+func (kv *memoryKV) Set(ctx context.Context, key string, value []byte) error {
+	if value == nil {
+		return ErrNilValue
+	}
+	kv.store.mu.Lock()
+	defer kv.store.mu.Unlock()
+	kv.store.kv[key] = value
+	return nil
+}
+
+func (store *memoryStore) incr(keys ...string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, key := range keys {
+		store.counters[key]++
+	}
+}
+
+func (store *memoryStore) Success(ctx context.Context) error {
+	daystr := time.Now().Format("2006-01-02")
+	store.incr("processed", "processed:"+daystr)
+	return nil
+}
+
+func (store *memoryStore) Failure(ctx context.Context) error {
+	daystr := time.Now().Format("2006-01-02")
+	store.incr("processed", "failures", "processed:"+daystr, "failures:"+daystr)
+	return nil
+}
+
+func (store *memoryStore) TotalProcessed(ctx context.Context) uint64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return uint64(store.counters["processed"]) // nolint:gosec
+}
+
+func (store *memoryStore) TotalFailures(ctx context.Context) uint64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return uint64(store.counters["failures"]) // nolint:gosec
+}
+
+func (store *memoryStore) History(ctx context.Context, days int, fn func(day string, procCnt uint64, failCnt uint64)) error {
+	if days > 180 {
+		return errors.New("days value can't be greater than 180")
+	}
+	ts := time.Now()
+	for range days {
+		daystr := ts.Format("2006-01-02")
+		store.mu.Lock()
+		procd := store.counters["processed:"+daystr]
+		failed := store.counters["failures:"+daystr]
+		store.mu.Unlock()
+		fn(daystr, uint64(procd), uint64(failed)) // nolint:gosec
+		ts = ts.Add(-24 * time.Hour)
+	}
+	return nil
+}
+
+func (store *memoryStore) RecordExecution(ctx context.Context, sample ExecutionSample, ttl time.Duration) error {
+	key := metricsKey(sample.At)
+	bucket := bucketFor(sample.Duration)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	now := time.Now()
+	for k, minute := range store.metrics {
+		if now.After(minute.expiresAt) {
+			delete(store.metrics, k)
+		}
+	}
+
+	minute, ok := store.metrics[key]
+	if !ok {
+		minute = &memoryMinute{metrics: &MinuteMetrics{
+			Minute:   sample.At.UTC().Truncate(time.Minute),
+			Queues:   map[string]*ExecutionMetrics{},
+			Jobtypes: map[string]*ExecutionMetrics{},
+		}}
+		store.metrics[key] = minute
+	}
+	minute.expiresAt = now.Add(ttl)
+
+	mm := minute.metrics
+	for name, target := range map[string]map[string]*ExecutionMetrics{sample.Queue: mm.Queues, sample.Jobtype: mm.Jobtypes} {
+		em, ok := target[name]
+		if !ok {
+			em = NewExecutionMetrics()
+			target[name] = em
+		}
+		em.Processed++
+		if sample.Failed {
+			em.Failed++
+		}
+		em.Durations[bucket]++
+	}
+	return nil
+}
+
+func (store *memoryStore) ExecutionHistory(ctx context.Context, since time.Time, fn func(*MinuteMetrics)) error {
+	first := since.UTC().Truncate(time.Minute)
+	last := time.Now().UTC().Truncate(time.Minute)
+
+	for tm := first; !tm.After(last); tm = tm.Add(time.Minute) {
+		mm := &MinuteMetrics{
+			Minute:   tm,
+			Queues:   map[string]*ExecutionMetrics{},
+			Jobtypes: map[string]*ExecutionMetrics{},
+		}
+		store.mu.Lock()
+		if minute, ok := store.metrics[metricsKey(tm)]; ok && time.Now().Before(minute.expiresAt) {
+			sumInto(mm.Queues, minute.metrics.Queues)
+			sumInto(mm.Jobtypes, minute.metrics.Jobtypes)
+		}
+		store.mu.Unlock()
+		fn(mm)
+	}
+	return nil
+}
+
+func (store *memoryStore) RecordAudit(ctx context.Context, entry AuditEntry, maxSize int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.audit = append([]AuditEntry{entry}, store.audit...)
+	if maxSize > 0 && int64(len(store.audit)) > maxSize {
+		store.audit = store.audit[:maxSize]
+	}
+	return nil
+}
+
+func (store *memoryStore) AuditSize(ctx context.Context) uint64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return uint64(len(store.audit))
+}
+
+func (store *memoryStore) AuditLog(ctx context.Context, start int64, count int64) ([]AuditEntry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	from, to := listRange(len(store.audit), start, start+count-1)
+	return append([]AuditEntry{}, store.audit[from:to]...), nil
+}
+
+func (store *memoryStore) AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	lease, ok := store.leases[name]
+	if ok && lease.owner != owner && time.Now().Before(lease.expiresAt) {
+		return false, nil
+	}
+	store.leases[name] = memoryLease{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (store *memoryStore) ReleaseLease(ctx context.Context, name string, owner string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.leases[name].owner == owner {
+		delete(store.leases, name)
+	}
+	return nil
+}
+
+// listRange converts Redis-style inclusive start and stop indexes, which
+// may be negative to count from the end, into slice bounds for a list
+// of the given length.
+func listRange(length int, start int64, stop int64) (int, int) {
+	n := int64(length)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return 0, 0
+	}
+	return int(start), int(stop + 1)
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// Execution durations are counted in a histogram with these
+	// upper bounds, the last bucket holds anything slower.
+	DurationBuckets = []time.Duration{
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+		25 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		1 * time.Second,
+		2500 * time.Millisecond,
+		5 * time.Second,
+		10 * time.Second,
+		30 * time.Second,
+		1 * time.Minute,
+		5 * time.Minute,
+		15 * time.Minute,
+		30 * time.Minute,
+	}
+)
+
+// ExecutionSample is the outcome of a single job execution.
+type ExecutionSample struct {
+	At       time.Time
+	Queue    string
+	Jobtype  string
+	Duration time.Duration
+	Failed   bool
+}
+
+// ExecutionMetrics aggregates a set of job executions.
+// Processed counts all executions, including failures.
+type ExecutionMetrics struct {
+	Durations []uint64
+	Processed uint64
+	Failed    uint64
+}
+
+func NewExecutionMetrics() *ExecutionMetrics {
+	return &ExecutionMetrics{Durations: make([]uint64, len(DurationBuckets)+1)}
+}
+
+func (em *ExecutionMetrics) Merge(other *ExecutionMetrics) {
+	em.Processed += other.Processed
+	em.Failed += other.Failed
+	for idx := range other.Durations {
+		em.Durations[idx] += other.Durations[idx]
+	}
+}
+
+// Percentile returns the upper bound of the histogram bucket
+// holding the given percentile (0.0-1.0) of execution durations.
+// Durations slower than the largest bucket report that bucket.
+func (em *ExecutionMetrics) Percentile(p float64) time.Duration {
+	total := uint64(0)
+	for _, count := range em.Durations {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	// nearest rank: truncating would report the faster of two samples
+	// as their p99
+	rank := uint64(math.Ceil(p * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+	seen := uint64(0)
+	for idx, count := range em.Durations {
+		seen += count
+		if seen >= rank && idx < len(DurationBuckets) {
+			return DurationBuckets[idx]
+		}
+	}
+	return DurationBuckets[len(DurationBuckets)-1]
+}
+
+// MinuteMetrics holds all executions which finished during one minute.
+type MinuteMetrics struct {
+	Minute   time.Time
+	Queues   map[string]*ExecutionMetrics
+	Jobtypes map[string]*ExecutionMetrics
+}
+
+// SumExecutions merges the given minutes into a single set of
+// metrics per queue and jobtype.
+func SumExecutions(minutes []*MinuteMetrics) (queues map[string]*ExecutionMetrics, jobtypes map[string]*ExecutionMetrics) {
+	queues = map[string]*ExecutionMetrics{}
+	jobtypes = map[string]*ExecutionMetrics{}
+	for _, mm := range minutes {
+		sumInto(queues, mm.Queues)
+		sumInto(jobtypes, mm.Jobtypes)
+	}
+	return queues, jobtypes
+}
+
+func sumInto(totals map[string]*ExecutionMetrics, values map[string]*ExecutionMetrics) {
+	for name, em := range values {
+		total, ok := totals[name]
+		if !ok {
+			total = NewExecutionMetrics()
+			totals[name] = total
+		}
+		total.Merge(em)
+	}
+}
+
+func bucketFor(d time.Duration) int {
+	for idx := range DurationBuckets {
+		if d <= DurationBuckets[idx] {
+			return idx
+		}
+	}
+	return len(DurationBuckets)
+}
+
+func metricsKey(minute time.Time) string {
+	return "metrics:" + minute.UTC().Format("200601021504")
+}
+
+// Each minute is stored as a hash with fields like
+// "q:default:p" (processed), "j:SomeJob:f" (failed) and
+// "j:SomeJob:d3" (count of durations in bucket 3).
+func (store *redisStore) RecordExecution(ctx context.Context, sample ExecutionSample, ttl time.Duration) error {
+	key := metricsKey(sample.At)
+	bucket := "d" + strconv.Itoa(bucketFor(sample.Duration))
+
+	_, err := store.rclient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, prefix := range []string{"q:" + sample.Queue, "j:" + sample.Jobtype} {
+			pipe.HIncrBy(ctx, key, prefix+":p", 1)
+			if sample.Failed {
+				pipe.HIncrBy(ctx, key, prefix+":f", 1)
+			}
+			pipe.HIncrBy(ctx, key, prefix+":"+bucket, 1)
+		}
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (store *redisStore) ExecutionHistory(ctx context.Context, since time.Time, fn func(*MinuteMetrics)) error {
+	first := since.UTC().Truncate(time.Minute)
+	last := time.Now().UTC().Truncate(time.Minute)
+
+	minutes := []time.Time{}
+	for tm := first; !tm.After(last); tm = tm.Add(time.Minute) {
+		minutes = append(minutes, tm)
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(minutes))
+	_, err := store.rclient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for idx := range minutes {
+			cmds[idx] = pipe.HGetAll(ctx, metricsKey(minutes[idx]))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for idx := range minutes {
+		mm, err := parseMinute(minutes[idx], cmds[idx].Val())
+		if err != nil {
+			return err
+		}
+		fn(mm)
+	}
+	return nil
+}
+
+func parseMinute(minute time.Time, fields map[string]string) (*MinuteMetrics, error) {
+	mm := &MinuteMetrics{
+		Minute:   minute,
+		Queues:   map[string]*ExecutionMetrics{},
+		Jobtypes: map[string]*ExecutionMetrics{},
+	}
+
+	for field, value := range fields {
+		count, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric %s: %w", field, err)
+		}
+		// jobtypes can contain colons, e.g. "Foo::BarJob"
+		first := strings.Index(field, ":")
+		last := strings.LastIndex(field, ":")
+		if first < 0 || first == last {
+			continue
+		}
+		kind, name, stat := field[:first], field[first+1:last], field[last+1:]
+
+		var target map[string]*ExecutionMetrics
+		switch kind {
+		case "q":
+			target = mm.Queues
+		case "j":
+			target = mm.Jobtypes
+		default:
+			continue
+		}
+		em, ok := target[name]
+		if !ok {
+			em = NewExecutionMetrics()
+			target[name] = em
+		}
+
+		switch {
+		case stat == "p":
+			em.Processed = count
+		case stat == "f":
+			em.Failed = count
+		case strings.HasPrefix(stat, "d"):
+			bucket, err := strconv.Atoi(stat[1:])
+			if err == nil && bucket >= 0 && bucket < len(em.Durations) {
+				em.Durations[bucket] = count
+			}
+		}
+	}
+	return mm, nil
+}
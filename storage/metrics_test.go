@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionMetrics(t *testing.T) {
+	assert.Equal(t, 0, bucketFor(time.Millisecond))
+	assert.Equal(t, 0, bucketFor(5*time.Millisecond))
+	assert.Equal(t, 1, bucketFor(6*time.Millisecond))
+	assert.Equal(t, len(DurationBuckets), bucketFor(time.Hour))
+
+	em := NewExecutionMetrics()
+	assert.EqualValues(t, 0, em.Percentile(0.5))
+
+	em.Durations[bucketFor(3*time.Millisecond)] = 90
+	em.Durations[bucketFor(2*time.Second)] = 9
+	em.Durations[bucketFor(time.Hour)] = 1
+	assert.Equal(t, 5*time.Millisecond, em.Percentile(0.50))
+	assert.Equal(t, 2500*time.Millisecond, em.Percentile(0.95))
+	assert.Equal(t, 2500*time.Millisecond, em.Percentile(0.99))
+	assert.Equal(t, 30*time.Minute, em.Percentile(1.0))
+
+	// the p99 of two samples is the slower one
+	em = NewExecutionMetrics()
+	em.Durations[bucketFor(3*time.Millisecond)] = 1
+	em.Durations[bucketFor(2*time.Second)] = 1
+	assert.Equal(t, 5*time.Millisecond, em.Percentile(0.50))
+	assert.Equal(t, 2500*time.Millisecond, em.Percentile(0.99))
+
+	mm, err := parseMinute(time.Now(), map[string]string{
+		"q:default:p":         "3",
+		"q:default:f":         "1",
+		"q:default:d2":        "3",
+		"j:Foo::BarJob:p":     "3",
+		"j:Foo::BarJob:d2":    "3",
+		"x:ignored:p":         "1",
+		"malformed":           "1",
+		"j:Foo::BarJob:d9999": "1",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, mm.Queues, 1)
+	assert.Len(t, mm.Jobtypes, 1)
+	assert.EqualValues(t, 3, mm.Queues["default"].Processed)
+	assert.EqualValues(t, 1, mm.Queues["default"].Failed)
+	assert.EqualValues(t, 3, mm.Jobtypes["Foo::BarJob"].Durations[2])
+
+	_, err = parseMinute(time.Now(), map[string]string{"q:default:p": "abc"})
+	assert.Error(t, err)
+
+	queues, jobtypes := SumExecutions([]*MinuteMetrics{mm, mm})
+	assert.EqualValues(t, 6, queues["default"].Processed)
+	assert.EqualValues(t, 2, queues["default"].Failed)
+	assert.EqualValues(t, 6, jobtypes["Foo::BarJob"].Durations[2])
+	// summing must not modify the source minutes
+	assert.EqualValues(t, 3, mm.Queues["default"].Processed)
+}
+
+func TestRecordExecution(t *testing.T) {
+	withStores(t, "metrics", func(t *testing.T, store Store) {
+		bg := context.Background()
+		assert.NoError(t, store.Flush(bg))
+
+		now := time.Now()
+		samples := []ExecutionSample{
+			{At: now, Queue: "default", Jobtype: "SomeJob", Duration: 20 * time.Millisecond},
+			{At: now, Queue: "default", Jobtype: "SomeJob", Duration: 3 * time.Second, Failed: true},
+			{At: now, Queue: "critical", Jobtype: "OtherJob", Duration: time.Millisecond},
+		}
+		for _, sample := range samples {
+			assert.NoError(t, store.RecordExecution(bg, sample, time.Hour))
+		}
+
+		minutes := []*MinuteMetrics{}
+		err := store.ExecutionHistory(bg, now.Add(-5*time.Minute), func(mm *MinuteMetrics) {
+			minutes = append(minutes, mm)
+		})
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, len(minutes), 6)
+
+		queues, jobtypes := SumExecutions(minutes)
+		assert.EqualValues(t, 2, queues["default"].Processed)
+		assert.EqualValues(t, 1, queues["default"].Failed)
+		assert.EqualValues(t, 1, queues["critical"].Processed)
+		assert.EqualValues(t, 2, jobtypes["SomeJob"].Processed)
+		assert.Equal(t, 25*time.Millisecond, jobtypes["SomeJob"].Percentile(0.5))
+		assert.Equal(t, 5*time.Second, jobtypes["SomeJob"].Percentile(0.99))
+	})
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/util"
+)
+
+// memoryQueue is a list in the same order as the Redis list: jobs are
+// pushed onto the front and popped from the back.
+type memoryQueue struct {
+	store *memoryStore
+	name  string
+	items [][]byte
+}
+
+func (q *memoryQueue) Name() string {
+	return q.name
+}
+
+func (q *memoryQueue) Size(ctx context.Context) uint64 {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	return uint64(len(q.items))
+}
+
+func (q *memoryQueue) Pause(ctx context.Context) error {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	q.store.paused[q.name] = true
+	return nil
+}
+
+func (q *memoryQueue) Resume(ctx context.Context) error {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	delete(q.store.paused, q.name)
+	return nil
+}
+
+func (q *memoryQueue) IsPaused(ctx context.Context) bool {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	return q.store.paused[q.name]
+}
+
+func (q *memoryQueue) Add(ctx context.Context, job *client.Job) error {
+	job.EnqueuedAt = util.Nows()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return q.Push(ctx, data)
+}
+
+func (q *memoryQueue) Push(ctx context.Context, payload []byte) error {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	q.items = slices.Insert(q.items, 0, payload)
+	close(q.store.pushed)
+	q.store.pushed = make(chan struct{})
+	return nil
+}
+
+// non-blocking, returns immediately if there's nothing enqueued
+func (q *memoryQueue) Pop(ctx context.Context) ([]byte, error) {
+	data, _ := q.pop()
+	return data, nil
+}
+
+func (q *memoryQueue) pop() ([]byte, chan struct{}) {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil, q.store.pushed
+	}
+	last := len(q.items) - 1
+	data := q.items[last]
+	q.items = q.items[:last]
+	return data, nil
+}
+
+func (q *memoryQueue) BPop(ctx context.Context) ([]byte, error) {
+	timeout := time.NewTimer(2 * time.Second)
+	defer timeout.Stop()
+	for {
+		data, pushed := q.pop()
+		if data != nil {
+			return data, nil
+		}
+		select {
+		case <-pushed:
+		case <-timeout.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (q *memoryQueue) Clear(ctx context.Context) (uint64, error) {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	q.items = nil
+	delete(q.store.queues, q.name)
+	delete(q.store.paused, q.name)
+	return 0, nil
+}
+
+func (q *memoryQueue) Page(ctx context.Context, start int64, count int64, fn func(index int, data []byte) error) error {
+	q.store.mu.Lock()
+	from, to := listRange(len(q.items), start, start+count)
+	slice := slices.Clone(q.items[from:to])
+	q.store.mu.Unlock()
+
+	for idx := range slice {
+		if err := fn(idx, slice[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *memoryQueue) Each(ctx context.Context, fn func(index int, data []byte) error) error {
+	return q.Page(ctx, 0, -1, fn)
+}
+
+func (q *memoryQueue) Delete(ctx context.Context, vals [][]byte) error {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	for _, val := range vals {
+		q.remove(val)
+	}
+	return nil
+}
+
+func (q *memoryQueue) Remove(ctx context.Context, data []byte) (bool, error) {
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	return q.remove(data), nil
+}
+
+func (q *memoryQueue) Move(ctx context.Context, dst Queue, data []byte, payload []byte) (bool, error) {
+	target, ok := dst.(*memoryQueue)
+	if !ok {
+		return false, fmt.Errorf("cannot move jobs to %T", dst)
+	}
+	if target == q {
+		return false, fmt.Errorf("cannot move queue %s to itself", q.name)
+	}
+	q.store.mu.Lock()
+	defer q.store.mu.Unlock()
+	if !q.remove(data) {
+		return false, nil
+	}
+	target.items = append(target.items, payload)
+	return true, nil
+}
+
+// remove deletes one copy of data, the caller must hold the store's lock.
+func (q *memoryQueue) remove(data []byte) bool {
+	idx := slices.IndexFunc(q.items, func(item []byte) bool { return bytes.Equal(item, data) })
+	if idx < 0 {
+		return false
+	}
+	q.items = slices.Delete(q.items, idx, idx+1)
+	return true
+}
+
+// MoveTo walks the queue from newest to oldest and appends the moved
+// jobs to the end of dst, like the Redis store.
+func (q *memoryQueue) MoveTo(ctx context.Context, dst Queue, fn func(data []byte) ([]byte, bool)) (uint64, error) {
+	target, ok := dst.(*memoryQueue)
+	if !ok {
+		return 0, fmt.Errorf("cannot move jobs to %T", dst)
+	}
+	if target == q {
+		return 0, fmt.Errorf("cannot move queue %s to itself", q.name)
+	}
+
+	q.store.mu.Lock()
+	snapshot := slices.Clone(q.items)
+	q.store.mu.Unlock()
+
+	var moved uint64
+	for _, data := range snapshot {
+		if err := ctx.Err(); err != nil {
+			return moved, err
+		}
+		payload, ok := fn(data)
+		if !ok {
+			continue
+		}
+		q.store.mu.Lock()
+		// unless the job was fetched in the meantime
+		if q.remove(data) {
+			target.items = append(target.items, payload)
+			moved++
+		}
+		q.store.mu.Unlock()
+	}
+	return moved, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/contribsys/faktory/client"
@@ -10,6 +11,24 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// Move this many jobs per round trip in Queue.MoveTo
+	QueueMoveBatch = 100
+)
+
+var (
+	// Removes one job from KEYS[1] and pushes its new payload onto KEYS[2]
+	// only if it was still there, so a job is never lost or duplicated if
+	// a worker fetches it concurrently.
+	moveScript = redis.NewScript(`
+if redis.call("lrem", KEYS[1], 1, ARGV[1]) == 1 then
+  redis.call("rpush", KEYS[2], ARGV[2])
+  return 1
+end
+return 0
+`)
+)
+
 type redisQueue struct {
 	store *redisStore
 	name  string
@@ -150,3 +169,70 @@ func (q *redisQueue) Delete(ctx context.Context, vals [][]byte) error {
 
 	return nil
 }
+
+func (q *redisQueue) Remove(ctx context.Context, data []byte) (bool, error) {
+	count, err := q.store.rclient.LRem(ctx, q.rname, 1, data).Result()
+	return count == 1, err
+}
+
+func (q *redisQueue) Move(ctx context.Context, dst Queue, data []byte, payload []byte) (bool, error) {
+	target, ok := dst.(*redisQueue)
+	if !ok {
+		return false, fmt.Errorf("cannot move jobs to %T", dst)
+	}
+	if target.rname == q.rname {
+		return false, fmt.Errorf("cannot move queue %s to itself", q.name)
+	}
+	moved, err := moveScript.Run(ctx, q.store.rclient, []string{q.rname, target.rname}, data, payload).Int()
+	return moved == 1, err
+}
+
+// MoveTo walks the queue from newest to oldest in batches of
+// QueueMoveBatch.  Jobs are appended to the end of dst which is fetched
+// first, so the moved jobs keep their order and run before the jobs
+// already waiting in dst.
+func (q *redisQueue) MoveTo(ctx context.Context, dst Queue, fn func(data []byte) ([]byte, bool)) (uint64, error) {
+	target, ok := dst.(*redisQueue)
+	if !ok {
+		return 0, fmt.Errorf("cannot move jobs to %T", dst)
+	}
+	if target.rname == q.rname {
+		return 0, fmt.Errorf("cannot move queue %s to itself", q.name)
+	}
+
+	var moved uint64
+	start := int64(0)
+	for {
+		slice, err := q.store.rclient.LRange(ctx, q.rname, start, start+QueueMoveBatch-1).Result()
+		if err != nil {
+			return moved, err
+		}
+
+		cmds := make([]*redis.Cmd, 0, len(slice))
+		_, err = q.store.rclient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for idx := range slice {
+				payload, ok := fn([]byte(slice[idx]))
+				if !ok {
+					continue
+				}
+				cmds = append(cmds, moveScript.Eval(ctx, pipe, []string{q.rname, target.rname}, slice[idx], payload))
+			}
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+		for idx := range cmds {
+			if cmds[idx].Val() == int64(1) {
+				moved++
+			}
+		}
+
+		// matching jobs are gone, either moved or fetched by a worker;
+		// the jobs we skipped are still in front of the next batch
+		start += int64(len(slice) - len(cmds))
+		if len(slice) < QueueMoveBatch {
+			return moved, nil
+		}
+	}
+}
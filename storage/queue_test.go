@@ -12,7 +12,7 @@ import (
 )
 
 func TestBasicQueueOps(t *testing.T) {
-	withRedis(t, "queue", func(t *testing.T, store Store) {
+	withStores(t, "queue", func(t *testing.T, store Store) {
 		bg := context.Background()
 
 		t.Run("Push", func(t *testing.T) {
@@ -77,6 +77,75 @@ func TestBasicQueueOps(t *testing.T) {
 			assert.Error(t, err)
 		})
 
+		t.Run("Move", func(t *testing.T) {
+			_ = store.Flush(bg)
+			src, err := store.GetQueue(bg, "misrouted")
+			assert.NoError(t, err)
+			dst, err := store.GetQueue(bg, "correct")
+			assert.NoError(t, err)
+			assert.NoError(t, dst.Push(bg, []byte("waiting")))
+
+			// span several batches, moving every other value
+			count := QueueMoveBatch*2 + 10
+			for i := range count {
+				assert.NoError(t, src.Push(bg, fmt.Appendf(nil, "%d", i)))
+			}
+			moved, err := src.MoveTo(bg, dst, func(data []byte) ([]byte, bool) {
+				var i int
+				_, _ = fmt.Sscanf(string(data), "%d", &i)
+				if i%2 == 1 {
+					return nil, false
+				}
+				return append([]byte("moved:"), data...), true
+			})
+			assert.NoError(t, err)
+			assert.EqualValues(t, count/2, moved)
+			assert.EqualValues(t, count/2, src.Size(bg))
+			assert.EqualValues(t, count/2+1, dst.Size(bg))
+
+			// moved jobs keep their order and are fetched first
+			for i := 0; i < count; i += 2 {
+				data, err := dst.Pop(bg)
+				assert.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("moved:%d", i), string(data))
+			}
+			data, err := dst.Pop(bg)
+			assert.NoError(t, err)
+			assert.Equal(t, "waiting", string(data))
+
+			_, err = src.MoveTo(bg, src, func(data []byte) ([]byte, bool) { return data, true })
+			assert.Error(t, err)
+		})
+
+		t.Run("MoveOne", func(t *testing.T) {
+			_ = store.Flush(bg)
+			src, err := store.GetQueue(bg, "misrouted")
+			assert.NoError(t, err)
+			dst, err := store.GetQueue(bg, "correct")
+			assert.NoError(t, err)
+			assert.NoError(t, src.Push(bg, []byte("a")))
+			assert.NoError(t, src.Push(bg, []byte("b")))
+
+			ok, err := src.Move(bg, dst, []byte("a"), []byte("moved:a"))
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			// already gone, e.g. fetched by a worker
+			ok, err = src.Move(bg, dst, []byte("a"), []byte("moved:a"))
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			assert.EqualValues(t, 1, dst.Size(bg))
+			_, err = src.Move(bg, src, []byte("b"), []byte("b"))
+			assert.Error(t, err)
+
+			ok, err = src.Remove(bg, []byte("b"))
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			ok, err = src.Remove(bg, []byte("b"))
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			assert.EqualValues(t, 0, src.Size(bg))
+		})
+
 		t.Run("heavy", func(t *testing.T) {
 			_ = store.Flush(bg)
 			q, err := store.GetQueue(bg, "default")
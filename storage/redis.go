@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -227,6 +228,51 @@ func openRedis(sock string, poolSize uint64) (Store, error) {
 	return NewRedisStore(sock, rclient)
 }
 
+// OpenURL opens an external Redis server rather than the one Faktory
+// boots, e.g. "redis://:password@redis.example.com:6379/0".  Servers
+// running highly available must share an external Redis.
+func OpenURL(url string, poolSize uint64) (Store, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	opts.PoolSize = int(poolSize) // nolint:gosec
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	rclient := redis.NewClient(opts)
+	_, err = rclient.Ping(ctx).Result()
+	if err != nil {
+		_ = rclient.Close()
+		return nil, err
+	}
+	return NewRedisStore(fmt.Sprintf("%s/%d", opts.Addr, opts.DB), rclient)
+}
+
+// OpenDatabase opens another database of the store's Redis server, or a
+// new in-memory store if the store isn't backed by Redis.
+func OpenDatabase(store Store, db int) (Store, error) {
+	rs, ok := store.(*redisStore)
+	if !ok {
+		return NewMemoryStore(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	opts := *rs.rclient.Options()
+	opts.DB = db
+	rclient := redis.NewClient(&opts)
+	_, err := rclient.Ping(ctx).Result()
+	if err != nil {
+		_ = rclient.Close()
+		if strings.Contains(err.Error(), "DB index is out of range") {
+			return nil, fmt.Errorf("no Redis database %d, raise databases in redis.conf", db)
+		}
+		return nil, fmt.Errorf("cannot open Redis database %d: %w", db, err)
+	}
+	return NewRedisStore(fmt.Sprintf("%s/%d", rs.Name, db), rclient)
+}
+
 func (store *redisStore) Stats(ctx context.Context) map[string]string {
 	return map[string]string{
 		"stats": store.rclient.Info(ctx).String(),
@@ -245,8 +291,31 @@ func (store *redisStore) EachQueue(ctx context.Context, x func(Queue)) {
 	}
 }
 
+var (
+	// FLUSHDB but restores the keys matching any pattern in ARGV with
+	// their TTLs.
+	flushScript = redis.NewScript(`
+local saved = {}
+for _, pattern in ipairs(ARGV) do
+  for _, key in ipairs(redis.call("keys", pattern)) do
+    local ttl = redis.call("pttl", key)
+    if ttl < 0 then ttl = 0 end
+    table.insert(saved, {key, ttl, redis.call("dump", key)})
+  end
+end
+redis.call("flushdb")
+for _, entry in ipairs(saved) do
+  redis.call("restore", entry[1], entry[2], entry[3])
+end
+return #saved
+`)
+)
+
+// Flush keeps leases so flushing the active server's database doesn't
+// hand its lease to the standby, and keeps the audit log so it records
+// the FLUSH rather than being erased by it.
 func (store *redisStore) Flush(ctx context.Context) error {
-	return store.rclient.FlushDB(ctx).Err()
+	return flushScript.Run(ctx, store.rclient, nil, leasePrefix+"*", auditKey).Err()
 }
 
 var (
@@ -358,7 +427,35 @@ func (store *redisStore) Dead() SortedSet {
 	return store.dead
 }
 
+// Completed job history is stored in one sorted set per queue,
+// scored by the time the job was acknowledged.
+func (store *redisStore) Completed(queue string) SortedSet {
+	return &redisSorted{name: "completed:" + queue, store: store}
+}
+
+func (store *redisStore) CompletedQueues(ctx context.Context) ([]string, error) {
+	names := []string{}
+	it := store.rclient.Scan(ctx, 0, "completed:*", 100).Iterator()
+	for it.Next(ctx) {
+		names = append(names, strings.TrimPrefix(it.Val(), "completed:"))
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 func (store *redisStore) EnqueueAll(ctx context.Context, sset SortedSet) error {
+	return enqueueAll(ctx, store, sset)
+}
+
+func (store *redisStore) EnqueueFrom(ctx context.Context, sset SortedSet, key []byte) error {
+	return enqueueFrom(ctx, store, sset, key)
+}
+
+// enqueueAll moves every job in the set to its queue.
+func enqueueAll(ctx context.Context, store Store, sset SortedSet) error {
 	return sset.Each(ctx, func(_ int, entry SortedEntry) error {
 		j, err := entry.Job()
 		if err != nil {
@@ -387,7 +484,8 @@ func (store *redisStore) EnqueueAll(ctx context.Context, sset SortedSet) error {
 	})
 }
 
-func (store *redisStore) EnqueueFrom(ctx context.Context, sset SortedSet, key []byte) error {
+// enqueueFrom moves the job with the given key from the set to its queue.
+func enqueueFrom(ctx context.Context, store Store, sset SortedSet, key []byte) error {
 	entry, err := sset.Get(ctx, key)
 	if err != nil {
 		return err
@@ -441,6 +539,9 @@ timeout 0
 daemonize no
 maxmemory-policy noeviction
 
+# each Faktory namespace is stored in its own database
+databases 256
+
 # Specify the server verbosity level.
 # This can be one of:
 # debug (a lot of information, useful for development/testing)
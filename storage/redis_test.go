@@ -10,7 +10,7 @@ import (
 )
 
 func TestRedisKV(t *testing.T) {
-	withRedis(t, "default", func(t *testing.T, store Store) {
+	withStores(t, "default", func(t *testing.T, store Store) {
 		ctx := context.Background()
 		assert.NoError(t, store.Flush(ctx))
 		kv := store.Raw()
@@ -56,3 +56,15 @@ func withRedis(t *testing.T, name string, fn func(*testing.T, Store)) {
 
 	fn(t, store)
 }
+
+// withStores runs fn against each Store implementation.
+func withStores(t *testing.T, name string, fn func(*testing.T, Store)) {
+	t.Run("memory", func(t *testing.T) {
+		store := NewMemoryStore()
+		defer store.Close()
+		fn(t, store)
+	})
+	t.Run("redis", func(t *testing.T) {
+		withRedis(t, name, fn)
+	})
+}
@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/util"
+)
+
+// memorySorted orders its elements like a Redis sorted set: by score,
+// then by member.  Members are unique.
+type memorySorted struct {
+	store    *memoryStore
+	name     string
+	elements []memoryElement
+}
+
+type memoryElement struct {
+	score  float64
+	member []byte
+}
+
+func compareElements(a, b memoryElement) int {
+	if c := cmp.Compare(a.score, b.score); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.member, b.member)
+}
+
+func (ms *memorySorted) Name() string {
+	return ms.name
+}
+
+func (ms *memorySorted) Size(ctx context.Context) uint64 {
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	return uint64(len(ms.elements))
+}
+
+func (ms *memorySorted) Clear(ctx context.Context) error {
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	ms.clear()
+	return nil
+}
+
+func (ms *memorySorted) clear() {
+	ms.elements = nil
+}
+
+func (ms *memorySorted) Add(ctx context.Context, job *client.Job) error {
+	if job.At == "" {
+		return errors.New("Job does not have an At timestamp")
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return ms.AddElement(ctx, job.At, job.Jid, data)
+}
+
+func (ms *memorySorted) RemoveEntry(ctx context.Context, ent SortedEntry) error {
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	ms.remove(ent.Value())
+	return nil
+}
+
+func (ms *memorySorted) AddElement(ctx context.Context, timestamp string, jid string, payload []byte) error {
+	tim, err := util.ParseTime(timestamp)
+	if err != nil {
+		return err
+	}
+	time_f := float64(tim.Unix()) + (float64(tim.Nanosecond()) / 1000000000)
+
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	// like ZADD, re-adding a member updates its score
+	ms.remove(payload)
+	elm := memoryElement{score: time_f, member: slices.Clone(payload)}
+	idx, _ := slices.BinarySearchFunc(ms.elements, elm, compareElements)
+	ms.elements = slices.Insert(ms.elements, idx, elm)
+	return nil
+}
+
+// remove deletes the member, returning whether it was present.  The
+// caller must hold the store's lock.
+func (ms *memorySorted) remove(member []byte) bool {
+	idx := slices.IndexFunc(ms.elements, func(elm memoryElement) bool {
+		return bytes.Equal(elm.member, member)
+	})
+	if idx < 0 {
+		return false
+	}
+	ms.elements = slices.Delete(ms.elements, idx, idx+1)
+	return true
+}
+
+// find returns the element with the given score, preferring the one
+// containing the jid if there are several, as the Redis store does.
+// The caller must hold the store's lock.
+func (ms *memorySorted) find(score float64, jid string) (memoryElement, bool) {
+	var matches []memoryElement
+	for _, elm := range ms.elements {
+		if elm.score == score {
+			matches = append(matches, elm)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+	for _, elm := range matches {
+		if strings.Index(string(elm.member), jid) > 0 {
+			return elm, true
+		}
+	}
+	return memoryElement{}, false
+}
+
+// key is "timestamp|jid"
+func (ms *memorySorted) Get(ctx context.Context, key []byte) (SortedEntry, error) {
+	time_f, jid, err := decompose(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	elm, ok := ms.find(time_f, jid)
+	if !ok {
+		return nil, nil
+	}
+	return NewEntry(elm.score, elm.member), nil
+}
+
+func (ms *memorySorted) Find(ctx context.Context, match string, fn func(index int, e SortedEntry) error) error {
+	rx, err := GlobPattern(match)
+	if err != nil {
+		return err
+	}
+
+	idx := 0
+	for _, elm := range ms.snapshot(0, -1) {
+		if !rx.Match(elm.member) {
+			continue
+		}
+		if err := fn(idx, NewEntry(elm.score, elm.member)); err != nil {
+			return err
+		}
+		idx += 1
+	}
+	return nil
+}
+
+// snapshot copies the elements with ranks start through stop, which
+// may be negative to count from the end like ZRANGE.
+func (ms *memorySorted) snapshot(start int64, stop int64) []memoryElement {
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	from, to := listRange(len(ms.elements), start, stop)
+	return slices.Clone(ms.elements[from:to])
+}
+
+func (ms *memorySorted) Page(ctx context.Context, start int, count int, fn func(index int, e SortedEntry) error) (int, error) {
+	elms := ms.snapshot(int64(start), int64(start+count-1))
+	for idx := range elms {
+		err := fn(idx, NewEntry(elms[idx].score, elms[idx].member))
+		if err != nil {
+			return idx, err
+		}
+	}
+	return len(elms), nil
+}
+
+func (ms *memorySorted) Each(ctx context.Context, fn func(idx int, e SortedEntry) error) error {
+	for idx, elm := range ms.snapshot(0, -1) {
+		if err := fn(idx, NewEntry(elm.score, elm.member)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ms *memorySorted) rem(time_f float64, jid string) bool {
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	elm, ok := ms.find(time_f, jid)
+	if !ok {
+		return false
+	}
+	return ms.remove(elm.member)
+}
+
+// bool = was it removed?
+// err = any error
+func (ms *memorySorted) Remove(ctx context.Context, key []byte) (bool, error) {
+	time_f, jid, err := decompose(key)
+	if err != nil {
+		return false, err
+	}
+	return ms.rem(time_f, jid), nil
+}
+
+func (ms *memorySorted) RemoveElement(ctx context.Context, timestamp string, jid string) (bool, error) {
+	tim, err := util.ParseTime(timestamp)
+	if err != nil {
+		return false, err
+	}
+	time_f := float64(tim.Unix()) + (float64(tim.Nanosecond()) / 1000000000)
+	return ms.rem(time_f, jid), nil
+}
+
+func (ms *memorySorted) RemoveBefore(ctx context.Context, timestamp string, maxCount int64, fn func(data []byte) error) (int64, error) {
+	tim, err := util.ParseTime(timestamp)
+	if err != nil {
+		return 0, err
+	}
+	time_f := float64(tim.Unix()) + (float64(tim.Nanosecond()) / 1000000000)
+
+	ms.store.mu.Lock()
+	var jobs [][]byte
+	for _, elm := range ms.elements {
+		if elm.score > time_f || int64(len(jobs)) == maxCount {
+			break
+		}
+		jobs = append(jobs, elm.member)
+	}
+	ms.store.mu.Unlock()
+
+	count := int64(0)
+	for _, j := range jobs {
+		ms.store.mu.Lock()
+		removed := ms.remove(j)
+		ms.store.mu.Unlock()
+		if removed {
+			err = fn(j)
+			if err != nil {
+				util.Warnf("Unable to process timed job: %v", err)
+				continue
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (ms *memorySorted) Trim(ctx context.Context, maxSize int64) (int64, error) {
+	if maxSize < 0 {
+		return 0, nil
+	}
+	ms.store.mu.Lock()
+	defer ms.store.mu.Unlock()
+	excess := int64(len(ms.elements)) - maxSize
+	if excess <= 0 {
+		return 0, nil
+	}
+	// elements are ordered by score so the first is the oldest
+	ms.elements = slices.Delete(ms.elements, 0, int(excess))
+	return excess, nil
+}
+
+func (ms *memorySorted) MoveTo(ctx context.Context, sset SortedSet, entry SortedEntry, newtime time.Time) error {
+	job, err := entry.Job()
+	if err != nil {
+		return err
+	}
+
+	ms.store.mu.Lock()
+	removed := ms.remove(entry.Value())
+	ms.store.mu.Unlock()
+	if !removed {
+		// race condition, element was removed or moved elsewhere
+		return nil
+	}
+
+	return sset.AddElement(ctx, util.Thens(newtime), job.Jid, entry.Value())
+}
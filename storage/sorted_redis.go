@@ -297,6 +297,14 @@ func (rs *redisSorted) RemoveBefore(ctx context.Context, timestamp string, maxCo
 	return count, nil
 }
 
+func (rs *redisSorted) Trim(ctx context.Context, maxSize int64) (int64, error) {
+	if maxSize < 0 {
+		return 0, nil
+	}
+	// ranks are ordered by score so rank 0 is the oldest element
+	return rs.store.rclient.ZRemRangeByRank(ctx, rs.name, 0, -(maxSize + 1)).Result()
+}
+
 func (rs *redisSorted) MoveTo(ctx context.Context, sset SortedSet, entry SortedEntry, newtime time.Time) error {
 	job, err := entry.Job()
 	if err != nil {
@@ -12,7 +12,7 @@ import (
 )
 
 func TestBasicSortedOps(t *testing.T) {
-	withRedis(t, "sorted", func(t *testing.T, store Store) {
+	withStores(t, "sorted", func(t *testing.T, store Store) {
 		bg := context.Background()
 
 		t.Run("large set", func(t *testing.T) {
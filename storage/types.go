@@ -21,6 +21,10 @@ type Store interface {
 	Scheduled() SortedSet
 	Working() SortedSet
 	Dead() SortedSet
+	// Completed holds the recently acknowledged jobs for the given queue.
+	Completed(queue string) SortedSet
+	// CompletedQueues lists the queues which have completed job history.
+	CompletedQueues(ctx context.Context) ([]string, error)
 	ExistingQueue(ctx context.Context, name string) (q Queue, ok bool)
 	GetQueue(ctx context.Context, name string) (Queue, error)
 	EachQueue(ctx context.Context, eachFn func(Queue))
@@ -35,8 +39,23 @@ type Store interface {
 	TotalProcessed(ctx context.Context) uint64
 	TotalFailures(ctx context.Context) uint64
 
+	// Per-minute execution metrics for each queue and jobtype
+	RecordExecution(ctx context.Context, sample ExecutionSample, ttl time.Duration) error
+	ExecutionHistory(ctx context.Context, since time.Time, fn func(*MinuteMetrics)) error
+
+	// Audit log of administrative actions, newest first
+	RecordAudit(ctx context.Context, entry AuditEntry, maxSize int64) error
+	AuditLog(ctx context.Context, start int64, count int64) ([]AuditEntry, error)
+	AuditSize(ctx context.Context) uint64
+
+	// AcquireLease takes or renews the named lease for the owner for
+	// ttl, returning false if another owner holds it.
+	AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up the named lease if the owner holds it.
+	ReleaseLease(ctx context.Context, name string, owner string) error
+
 	// Clear the database of all job data.
-	// Equivalent to Redis's FLUSHDB
+	// Equivalent to Redis's FLUSHDB but leases and the audit log are kept.
 	Flush(ctx context.Context) error
 
 	// data version for migration tracking
@@ -70,6 +89,17 @@ type Queue interface {
 	Page(ctx context.Context, start int64, count int64, fn func(index int, data []byte) error) error
 
 	Delete(ctx context.Context, keys [][]byte) error
+	// Remove one copy of the job, returning false if it's no longer in
+	// the queue, e.g. because a worker fetched it.
+	Remove(ctx context.Context, data []byte) (bool, error)
+	// Move one job to the given Queue atomically, pushing payload in
+	// its place.  Returns false if the job is no longer in the queue.
+	Move(ctx context.Context, dst Queue, data []byte, payload []byte) (bool, error)
+
+	// Move the jobs accepted by the given func to the given Queue
+	// atomically.  The func returns the payload to push, so it can
+	// rewrite the job.  Returns the number of jobs moved.
+	MoveTo(ctx context.Context, dst Queue, fn func(data []byte) ([]byte, bool)) (uint64, error)
 }
 
 type SortedEntry interface {
@@ -100,6 +130,10 @@ type SortedSet interface {
 	RemoveBefore(ctx context.Context, timestamp string, maxCount int64, fn func(data []byte) error) (int64, error)
 	RemoveEntry(ctx context.Context, ent SortedEntry) error
 
+	// Trim removes the oldest elements so the set holds no more than
+	// maxSize elements. Returns the number of elements removed.
+	Trim(ctx context.Context, maxSize int64) (int64, error)
+
 	// Move the given key from this SortedSet to the given
 	// SortedSet atomically.  The given func may mutate the payload and
 	// return a new tstamp.
@@ -0,0 +1,243 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+)
+
+// OTLP span kinds and status codes
+const (
+	kindInternal = 1
+	kindProducer = 4
+	kindConsumer = 5
+
+	statusUnset = 0
+	statusError = 2
+)
+
+var (
+	// Spans are dropped if the collector can't keep up.
+	MaxQueuedSpans = 8192
+	// Spans are sent in batches of this size.
+	MaxBatchSize = 512
+)
+
+type Span struct {
+	Parent     client.TraceContext
+	SpanID     [8]byte
+	Name       string
+	Kind       int
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Error      string
+}
+
+func newSpan(parent client.TraceContext, name string, kind int, start time.Time, end time.Time, job *client.Job) *Span {
+	span := &Span{
+		Parent: parent,
+		Name:   name,
+		Kind:   kind,
+		Start:  start,
+		End:    end,
+		Attributes: map[string]string{
+			"messaging.system":           "faktory",
+			"messaging.destination.name": job.Queue,
+			"messaging.message.id":       job.Jid,
+			"faktory.jobtype":            job.Type,
+		},
+	}
+	_, _ = cryptorand.Read(span.SpanID[:])
+	return span
+}
+
+// exporter sends spans to an OTLP/HTTP collector using the JSON
+// encoding, e.g. http://localhost:4318/v1/traces
+type exporter struct {
+	endpoint string
+	headers  map[string]string
+	service  string
+	client   *http.Client
+
+	mu      sync.Mutex
+	queue   []*Span
+	dropped int64
+	sent    int64
+}
+
+func newExporter(opts Options) *exporter {
+	return &exporter{
+		endpoint: opts.Endpoint,
+		headers:  opts.Headers,
+		service:  opts.ServiceName,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *exporter) add(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.queue) >= MaxQueuedSpans {
+		e.dropped++
+		return
+	}
+	e.queue = append(e.queue, span)
+}
+
+// flush sends all queued spans.  Spans are discarded if the export
+// fails, tracing is best effort.
+func (e *exporter) flush(ctx context.Context) error {
+	for {
+		e.mu.Lock()
+		count := min(len(e.queue), MaxBatchSize)
+		batch := e.queue[:count]
+		e.queue = e.queue[count:]
+		e.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+		err := e.export(ctx, batch)
+		if err != nil {
+			e.mu.Lock()
+			e.dropped += int64(len(batch))
+			e.mu.Unlock()
+			return err
+		}
+		e.mu.Lock()
+		e.sent += int64(len(batch))
+		e.mu.Unlock()
+	}
+}
+
+func (e *exporter) export(ctx context.Context, spans []*Span) error {
+	data, err := json.Marshal(e.encode(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", e.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("OTLP export to %s failed: %s", e.endpoint, resp.Status)
+	}
+	return nil
+}
+
+// The OTLP JSON encoding of ExportTraceServiceRequest, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId"`
+	TraceState        string          `json:"traceState,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func (e *exporter) encode(spans []*Span) *otlpRequest {
+	encoded := make([]otlpSpan, len(spans))
+	for idx, span := range spans {
+		out := otlpSpan{
+			TraceID:           hex.EncodeToString(span.Parent.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.SpanID[:]),
+			ParentSpanID:      hex.EncodeToString(span.Parent.SpanID[:]),
+			TraceState:        span.Parent.State,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        attributes(span.Attributes),
+			Status:            otlpStatus{Code: statusUnset},
+		}
+		if span.Error != "" {
+			out.Status = otlpStatus{Code: statusError, Message: span.Error}
+		}
+		encoded[idx] = out
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: attributes(map[string]string{"service.name": e.service})},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/contribsys/faktory", Version: client.Version},
+				Spans: encoded,
+			}},
+		}},
+	}
+}
+
+func attributes(attrs map[string]string) []otlpAttribute {
+	result := make([]otlpAttribute, 0, len(attrs))
+	for key, value := range attrs {
+		result = append(result, otlpAttribute{Key: key, Value: otlpValue{StringValue: value}})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
@@ -0,0 +1,222 @@
+// Package tracing records OpenTelemetry spans for jobs which carry a
+// W3C traceparent, see client.Job.InjectTrace, and exports them to an
+// OTLP/HTTP collector.
+//
+//	[tracing]
+//	enabled = true
+//	endpoint = "http://localhost:4318/v1/traces"
+//	service_name = "faktory"
+//	headers = { "x-api-key" = "secret" }
+//
+// Each sampled job produces three spans within the pushing trace:
+// faktory.push, faktory.queue_wait (enqueue until fetched) and
+// faktory.execute (fetched until ACK or FAIL).
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/util"
+)
+
+type Options struct {
+	Endpoint    string
+	ServiceName string
+	Headers     map[string]string
+}
+
+type Lifecycle struct {
+	opts     Options
+	exporter atomic.Pointer[exporter]
+}
+
+func Subsystem() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) Name() string {
+	return "Tracing"
+}
+
+func (l *Lifecycle) Start(s *server.Server) error {
+	opts, err := parseOptions(s.Options)
+	if err != nil {
+		return err
+	}
+	l.configure(opts)
+
+	// Middleware can't be removed so it is always installed and
+	// does nothing while tracing is disabled.
+	m := s.Manager()
+	m.AddMiddleware("push", l.pushed)
+	m.AddMiddleware("fetch", l.fetched)
+	m.AddMiddleware("ack", l.acked)
+	m.AddMiddleware("fail", l.failed)
+	s.AddTask(5, &flusher{l: l})
+	return nil
+}
+
+func (l *Lifecycle) Reload(s *server.Server) error {
+	opts, err := parseOptions(s.Options)
+	if err != nil {
+		return err
+	}
+	if fmt.Sprint(opts) != fmt.Sprint(l.opts) {
+		l.configure(opts)
+	}
+	return nil
+}
+
+func (l *Lifecycle) configure(opts Options) {
+	l.opts = opts
+	if opts.Endpoint == "" {
+		l.exporter.Store(nil)
+		return
+	}
+	util.Infof("Exporting job traces to %s", opts.Endpoint)
+	l.exporter.Store(newExporter(opts))
+}
+
+func parseOptions(so *server.ServerOptions) (Options, error) {
+	opts := Options{}
+	if !so.Bool("tracing", "enabled", false) {
+		return opts, nil
+	}
+
+	opts.Endpoint = so.String("tracing", "endpoint", "http://localhost:4318/v1/traces")
+	opts.ServiceName = so.String("tracing", "service_name", "faktory")
+	headers, ok := so.Config("tracing", "headers", map[string]any{}).(map[string]any)
+	if !ok {
+		return opts, fmt.Errorf("tracing: headers must be a table")
+	}
+	opts.Headers = map[string]string{}
+	for name, value := range headers {
+		str, ok := value.(string)
+		if !ok {
+			return opts, fmt.Errorf("tracing: header %s must be a string", name)
+		}
+		opts.Headers[name] = str
+	}
+	return opts, nil
+}
+
+// traced returns the exporter and the job's trace context if the job
+// is part of a sampled trace.
+func (l *Lifecycle) traced(ctx context.Context) (*exporter, manager.Context, client.TraceContext, bool) {
+	exp := l.exporter.Load()
+	if exp == nil {
+		return nil, nil, client.TraceContext{}, false
+	}
+	mh, ok := ctx.Value(manager.MiddlewareHelperKey).(manager.Context)
+	if !ok {
+		return nil, nil, client.TraceContext{}, false
+	}
+	tc, ok := mh.Job().TraceContext()
+	if !ok || !tc.IsSampled() {
+		return nil, nil, client.TraceContext{}, false
+	}
+	return exp, mh, tc, true
+}
+
+func (l *Lifecycle) pushed(ctx context.Context, next func() error) error {
+	start := time.Now()
+	err := next()
+	if exp, mh, tc, ok := l.traced(ctx); ok {
+		span := newSpan(tc, "faktory.push", kindProducer, start, time.Now(), mh.Job())
+		if err != nil {
+			span.Error = err.Error()
+		}
+		exp.add(span)
+	}
+	return err
+}
+
+func (l *Lifecycle) fetched(ctx context.Context, next func() error) error {
+	err := next()
+	if err != nil {
+		return err
+	}
+	if exp, mh, tc, ok := l.traced(ctx); ok {
+		job := mh.Job()
+		if tm, err := util.ParseTime(job.EnqueuedAt); err == nil {
+			exp.add(newSpan(tc, "faktory.queue_wait", kindInternal, tm, time.Now(), job))
+		}
+	}
+	return nil
+}
+
+func (l *Lifecycle) acked(ctx context.Context, next func() error) error {
+	l.executed(ctx, "")
+	return next()
+}
+
+func (l *Lifecycle) failed(ctx context.Context, next func() error) error {
+	msg := "failed"
+	if mh, ok := ctx.Value(manager.MiddlewareHelperKey).(manager.Context); ok {
+		if f := mh.Job().Failure; f != nil {
+			msg = f.ErrorType + ": " + f.ErrorMessage
+		}
+	}
+	l.executed(ctx, msg)
+	return next()
+}
+
+func (l *Lifecycle) executed(ctx context.Context, errmsg string) {
+	exp, mh, tc, ok := l.traced(ctx)
+	if !ok || mh.Reservation() == nil {
+		return
+	}
+	since := mh.Reservation().ReservedAt()
+	if since.IsZero() {
+		return
+	}
+	span := newSpan(tc, "faktory.execute", kindConsumer, since, time.Now(), mh.Job())
+	span.Error = errmsg
+	exp.add(span)
+}
+
+// flusher periodically exports the recorded spans.  Exports run in the
+// background so a slow collector can't delay the other server tasks.
+type flusher struct {
+	l        *Lifecycle
+	flushing atomic.Bool
+}
+
+func (f *flusher) Name() string {
+	return "Tracing"
+}
+
+func (f *flusher) Execute(ctx context.Context) error {
+	exp := f.l.exporter.Load()
+	if exp == nil || !f.flushing.CompareAndSwap(false, true) {
+		return nil
+	}
+	go func() {
+		defer f.flushing.Store(false)
+		if err := exp.flush(context.Background()); err != nil {
+			util.Warnf("Unable to export traces: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (f *flusher) Stats(context.Context) map[string]any {
+	exp := f.l.exporter.Load()
+	if exp == nil {
+		return map[string]any{"enabled": false}
+	}
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	return map[string]any{
+		"enabled": true,
+		"queued":  len(exp.queue),
+		"sent":    exp.sent,
+		"dropped": exp.dropped,
+	}
+}
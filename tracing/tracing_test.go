@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type testHelper struct {
+	job *client.Job
+	res *manager.Reservation
+}
+
+func (th testHelper) Job() *client.Job                  { return th.job }
+func (th testHelper) Reservation() *manager.Reservation { return th.res }
+func (th testHelper) Manager() manager.Manager          { return nil }
+
+func TestTracing(t *testing.T) {
+	requests := []otlpRequest{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		var req otlpRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+	}))
+	defer collector.Close()
+
+	l := Subsystem()
+	l.configure(Options{Endpoint: collector.URL + "/v1/traces", ServiceName: "faktory", Headers: map[string]string{"X-Api-Key": "secret"}})
+
+	tc, err := client.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+
+	job := client.NewJob("TracedJob", 1)
+	job.InjectTrace(client.ContextWithTrace(context.Background(), tc))
+	job.EnqueuedAt = util.Thens(time.Now().Add(-time.Second))
+	job.Failure = &client.Failure{ErrorType: "RuntimeError", ErrorMessage: "boom"}
+	res := &manager.Reservation{Job: job, Since: util.Thens(time.Now().Add(-500 * time.Millisecond))}
+	ctx := context.WithValue(context.Background(), manager.MiddlewareHelperKey, testHelper{job, res})
+	next := func() error { return nil }
+
+	assert.NoError(t, l.pushed(ctx, next))
+	assert.NoError(t, l.fetched(ctx, next))
+	assert.NoError(t, l.acked(ctx, next))
+	assert.NoError(t, l.failed(ctx, next))
+
+	// untraced and unsampled jobs are ignored
+	untraced := client.NewJob("UntracedJob")
+	assert.NoError(t, l.pushed(context.WithValue(context.Background(), manager.MiddlewareHelperKey, testHelper{untraced, nil}), next))
+	tc.Flags = 0
+	unsampled := client.NewJob("UnsampledJob").InjectTrace(client.ContextWithTrace(context.Background(), tc))
+	assert.NoError(t, l.pushed(context.WithValue(context.Background(), manager.MiddlewareHelperKey, testHelper{unsampled, nil}), next))
+
+	exp := l.exporter.Load()
+	assert.Len(t, exp.queue, 4)
+	assert.NoError(t, exp.flush(context.Background()))
+	assert.Len(t, exp.queue, 0)
+	assert.EqualValues(t, 4, exp.sent)
+
+	assert.Len(t, requests, 1)
+	rs := requests[0].ResourceSpans[0]
+	assert.Equal(t, []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: "faktory"}}}, rs.Resource.Attributes)
+	spans := rs.ScopeSpans[0].Spans
+	assert.Len(t, spans, 4)
+
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+		assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID)
+		assert.Len(t, span.SpanID, 16)
+		assert.NotEqual(t, span.StartTimeUnixNano, span.EndTimeUnixNano)
+	}
+	assert.Equal(t, []string{"faktory.push", "faktory.queue_wait", "faktory.execute", "faktory.execute"}, names)
+	assert.Equal(t, kindProducer, spans[0].Kind)
+	assert.Equal(t, statusUnset, spans[2].Status.Code)
+	assert.Equal(t, otlpStatus{Code: statusError, Message: "RuntimeError: boom"}, spans[3].Status)
+
+	// disabled
+	l.configure(Options{})
+	assert.Nil(t, l.exporter.Load())
+	assert.NoError(t, l.pushed(ctx, next))
+}
+
+func TestParseOptions(t *testing.T) {
+	so := &server.ServerOptions{GlobalConfig: map[string]any{}}
+	opts, err := parseOptions(so)
+	assert.NoError(t, err)
+	assert.Equal(t, "", opts.Endpoint)
+
+	so.GlobalConfig["tracing"] = map[string]any{"enabled": true, "headers": map[string]any{"x-api-key": "abc"}}
+	opts, err = parseOptions(so)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Endpoint: "http://localhost:4318/v1/traces", ServiceName: "faktory", Headers: map[string]string{"x-api-key": "abc"}}, opts)
+
+	so.GlobalConfig["tracing"] = map[string]any{"enabled": true, "headers": map[string]any{"x-api-key": 1}}
+	_, err = parseOptions(so)
+	assert.Error(t, err)
+}
+
+func TestEncryptedJob(t *testing.T) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	t.Setenv("FAKTORY_ENCRYPTION_KEYS", "test:"+base64.StdEncoding.EncodeToString(key))
+
+	s, err := server.NewServer(&server.ServerOptions{
+		GlobalConfig: map[string]any{
+			"tracing": map[string]any{"enabled": true},
+		},
+		Binding:          "localhost:0",
+		StorageDirectory: t.TempDir(),
+		ConfigDirectory:  t.TempDir(),
+		PoolSize:         server.DefaultMaxPoolSize,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.BootWithStore(storage.NewMemoryStore()))
+	defer s.Stop(nil)
+	l := Subsystem()
+	assert.NoError(t, l.Start(s))
+
+	tc, err := client.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+	job := client.NewJob("TracedJob", "secret")
+	job.InjectTrace(client.ContextWithTrace(context.Background(), tc))
+	ctx := context.Background()
+	assert.NoError(t, s.Manager().Push(ctx, job))
+	fetched, err := s.Manager().Fetch(ctx, "worker", "default")
+	assert.NoError(t, err)
+	assert.True(t, s.Encrypted(fetched))
+	assert.NoError(t, s.Manager().Fail(ctx, &manager.FailPayload{Jid: fetched.Jid, ErrorType: "RuntimeError"}))
+
+	// the trace context stays in plaintext
+	assert.Len(t, l.exporter.Load().queue, 3)
+}
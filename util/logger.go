@@ -1,8 +1,14 @@
 package util
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -41,27 +47,94 @@ var lvlPrefix = [...]string{
 	FatalLevel: "F",
 }
 
+var lvlName = [...]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+}
+
 var (
 	LogInfo  = false
 	LogDebug = false
-	logg     = os.Stdout
-	colorize = isTTY(logg.Fd())
+
+	logMutex sync.Mutex
+	logg     io.Writer = os.Stdout
+	logJSON            = false
+	colorize           = isTTY(os.Stdout.Fd())
 )
 
 const (
 	TimeFormat = "2006-01-02T15:04:05.000Z"
 )
 
+// Fields are structured data attached to a log message, e.g. the
+// JID and jobtype of the job being processed.
+type Fields map[string]any
+
 func llog(lvl Level, msg string) {
-	prefix := lvlPrefix[lvl]
+	llogw(lvl, msg, nil)
+}
+
+func llogw(lvl Level, msg string, fields Fields) {
 	ts := time.Now().UTC().Format(TimeFormat)
 
-	if colorize {
-		color := colors[lvl]
-		_, _ = fmt.Fprintf(logg, "\033[%dm%s\033[0m %s %s\n", color, prefix, ts, msg)
+	var line string
+	if logJSON {
+		line = jsonLine(lvl, ts, msg, fields)
+	} else if colorize {
+		line = fmt.Sprintf("\033[%dm%s\033[0m %s %s%s\n", colors[lvl], lvlPrefix[lvl], ts, msg, textFields(fields))
 	} else {
-		_, _ = fmt.Fprintf(logg, "%s %s %s\n", prefix, ts, msg)
+		line = fmt.Sprintf("%s %s %s%s\n", lvlPrefix[lvl], ts, msg, textFields(fields))
 	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	_, _ = io.WriteString(logg, line)
+}
+
+func jsonLine(lvl Level, ts string, msg string, fields Fields) string {
+	entry := make(map[string]any, len(fields)+3)
+	for key, value := range fields {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		entry[key] = value
+	}
+	entry["level"] = lvlName[lvl]
+	entry["ts"] = ts
+	entry["msg"] = msg
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"level": lvlName[lvl], "ts": ts, "msg": msg})
+	}
+	return string(data) + "\n"
+}
+
+func textFields(fields Fields) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value := fmt.Sprint(fields[key])
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(value)
+	}
+	return b.String()
 }
 
 //
@@ -79,10 +152,88 @@ func InitLogger(level string) {
 	}
 }
 
+// LogOptions control the log format and destination:
+//
+//	[log]
+//	format = "json"                   # or "text", the default
+//	file = "/var/log/faktory.log"     # default is stdout
+//	max_size = 100                    # MB, rotate the file at this size
+//	max_backups = 5                   # rotated files to keep
+type LogOptions struct {
+	Format     string
+	File       string
+	MaxSize    int64
+	MaxBackups int
+}
+
+func ConfigureLogger(opts LogOptions) error {
+	var jsonFormat bool
+	switch opts.Format {
+	case "", "text":
+	case "json":
+		jsonFormat = true
+	default:
+		return fmt.Errorf("invalid log format %q, must be text or json", opts.Format)
+	}
+
+	var out io.Writer = os.Stdout
+	color := isTTY(os.Stdout.Fd())
+	if opts.File != "" {
+		file, err := OpenRotatingFile(opts.File, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return err
+		}
+		out = file
+		color = false
+	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	if closer, ok := logg.(*RotatingFile); ok {
+		_ = closer.Close()
+	}
+	logg = out
+	logJSON = jsonFormat
+	colorize = color
+	return nil
+}
+
 func Error(msg string, err error) {
+	if logJSON {
+		llogw(ErrorLevel, msg, Fields{"error": err})
+		return
+	}
 	llog(ErrorLevel, fmt.Sprintf("%s: %v", msg, err))
 }
 
+// Errorw logs an error with structured fields.
+func Errorw(msg string, err error, fields Fields) {
+	all := Fields{"error": err}
+	for key, value := range fields {
+		all[key] = value
+	}
+	llogw(ErrorLevel, msg, all)
+}
+
+// Warnw logs a warning with structured fields.
+func Warnw(msg string, fields Fields) {
+	llogw(WarnLevel, msg, fields)
+}
+
+// Infow logs a message with structured fields.
+func Infow(msg string, fields Fields) {
+	if LogInfo {
+		llogw(InfoLevel, msg, fields)
+	}
+}
+
+// Debugw logs a debug message with structured fields.
+func Debugw(msg string, fields Fields) {
+	if LogDebug {
+		llogw(DebugLevel, msg, fields)
+	}
+}
+
 // Uh oh, not good but not worthy of process death
 func Warn(arg string) {
 	llog(WarnLevel, arg)
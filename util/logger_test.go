@@ -0,0 +1,105 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredLogging(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "faktory.log")
+	defer func() { assert.NoError(t, ConfigureLogger(LogOptions{})) }()
+
+	assert.Error(t, ConfigureLogger(LogOptions{Format: "xml"}))
+
+	assert.NoError(t, ConfigureLogger(LogOptions{Format: "text", File: path}))
+	Warnw("Job halted", Fields{"jid": "abc123", "queue": "default", "error": "not unique"})
+	Warn("plain")
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "W "), lines[0])
+	assert.True(t, strings.HasSuffix(lines[0], ` Job halted error="not unique" jid=abc123 queue=default`), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], " plain"), lines[1])
+
+	assert.NoError(t, os.Remove(path))
+	assert.NoError(t, ConfigureLogger(LogOptions{Format: "json", File: path}))
+	Warnw("Job halted", Fields{"jid": "abc123", "code": "NOTUNIQUE"})
+	Error("Unable to save", errors.New("disk full"))
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, "Job halted", entry["msg"])
+	assert.Equal(t, "abc123", entry["jid"])
+	assert.Equal(t, "NOTUNIQUE", entry["code"])
+	assert.NotEmpty(t, entry["ts"])
+
+	entry = nil
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "Unable to save", entry["msg"])
+	assert.Equal(t, "disk full", entry["error"])
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faktory.log")
+	rf, err := OpenRotatingFile(path, 10, 2)
+	assert.NoError(t, err)
+	defer rf.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := rf.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+
+	contents := func(name string) string {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	assert.Equal(t, "fourth\n", contents(path))
+	assert.Equal(t, "third\n", contents(path+".1"))
+	assert.Equal(t, "second\n", contents(path+".2"))
+	assert.Equal(t, "", contents(path+".3"))
+
+	// reopening appends to the existing file
+	assert.NoError(t, rf.Close())
+	rf, err = OpenRotatingFile(path, 100, 2)
+	assert.NoError(t, err)
+	defer rf.Close()
+	_, err = rf.Write([]byte("fifth\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "fourth\nfifth\n", contents(path))
+}
+
+func TestRotatingFileRenameFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faktory.log")
+	// a directory can't be replaced by the log file
+	assert.NoError(t, os.MkdirAll(filepath.Join(path+".1", "keep"), 0o755))
+
+	rf, err := OpenRotatingFile(path, 10, 1)
+	assert.NoError(t, err)
+	defer rf.Close()
+
+	for _, line := range []string{"first\n", "second\n"} {
+		_, err := rf.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(data))
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an append-only log file which is renamed to
+// "<path>.1" once it grows past maxSize bytes, shifting older
+// files to "<path>.2" and so on.  Only maxBackups old files are kept.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens the log file for appending.  A maxSize of
+// zero disables rotation.
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil && rf.file == nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *RotatingFile) rotate() error {
+	_ = rf.file.Close()
+	rf.file = nil
+
+	err := rf.shift()
+	// if the rename failed keep appending to the current file rather
+	// than losing every line after it; rotation is retried next write
+	if oerr := rf.open(); oerr != nil {
+		return errors.Join(err, oerr)
+	}
+	return err
+}
+
+func (rf *RotatingFile) shift() error {
+	if rf.maxBackups < 1 {
+		return os.Remove(rf.path)
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", rf.path, rf.maxBackups))
+	for idx := rf.maxBackups - 1; idx > 0; idx-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", rf.path, idx), fmt.Sprintf("%s.%d", rf.path, idx+1))
+	}
+	return os.Rename(rf.path, rf.path+".1")
+}
+
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/util"
+)
+
+// Options configures the server-side pool which executes webhook jobs:
+//
+//	[webhook_jobs]
+//	enabled = true
+//	concurrency = 10
+//	queues = ["webhooks"]
+//
+// The pool fetches from its queues like any other worker so those
+// queues should only hold webhook jobs, other jobtypes will fail.  Each
+// namespace gets its own workers.
+type Options struct {
+	Concurrency int
+	Queues      []string
+}
+
+type Lifecycle struct {
+	mu    sync.Mutex
+	opts  Options
+	pools []*pool
+}
+
+func Subsystem() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) Name() string {
+	return "Webhooks"
+}
+
+func (l *Lifecycle) Start(s *server.Server) error {
+	opts, err := parseOptions(s.Options)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.restart(s, opts)
+	go func() {
+		<-s.Stopper()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.stop()
+	}()
+	return nil
+}
+
+func (l *Lifecycle) Reload(s *server.Server) error {
+	opts, err := parseOptions(s.Options)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if opts.Concurrency == l.opts.Concurrency && strings.Join(opts.Queues, ",") == strings.Join(l.opts.Queues, ",") {
+		return nil
+	}
+	util.Infof("Reloading webhook workers")
+	l.restart(s, opts)
+	return nil
+}
+
+func (l *Lifecycle) restart(s *server.Server, opts Options) {
+	l.stop()
+	l.opts = opts
+	if opts.Concurrency > 0 {
+		for _, ns := range s.Namespaces() {
+			p := newPool(s, ns.Manager(), opts)
+			p.start()
+			l.pools = append(l.pools, p)
+		}
+		util.Infof("Executing %s jobs from %v with %d workers", JobType, opts.Queues, opts.Concurrency)
+	}
+}
+
+func (l *Lifecycle) stop() {
+	for _, p := range l.pools {
+		p.stop()
+	}
+	l.pools = nil
+}
+
+func parseOptions(so *server.ServerOptions) (Options, error) {
+	opts := Options{}
+	if !so.Bool("webhook_jobs", "enabled", false) {
+		return opts, nil
+	}
+
+	opts.Concurrency = so.Int("webhook_jobs", "concurrency", 10)
+	if opts.Concurrency < 1 {
+		return opts, fmt.Errorf("webhook_jobs: concurrency must be positive")
+	}
+	queues, ok := so.Config("webhook_jobs", "queues", []any{"webhooks"}).([]any)
+	if !ok || len(queues) == 0 {
+		return opts, fmt.Errorf("webhook_jobs: queues must be an array of queue names")
+	}
+	for _, q := range queues {
+		name, ok := q.(string)
+		if !ok || name == "" {
+			return opts, fmt.Errorf("webhook_jobs: invalid queue %v", q)
+		}
+		opts.Queues = append(opts.Queues, name)
+	}
+	return opts, nil
+}
+
+type pool struct {
+	server *server.Server
+	mgr    manager.Manager
+	opts   Options
+	client *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newPool(s *server.Server, mgr manager.Manager, opts Options) *pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pool{
+		server: s,
+		mgr:    mgr,
+		opts:   opts,
+		client: &http.Client{},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (p *pool) start() {
+	for idx := range p.opts.Concurrency {
+		wid := fmt.Sprintf("%s-%d", JobType, idx)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.process(wid)
+		}()
+	}
+}
+
+// stop waits for in-progress deliveries to finish.
+func (p *pool) stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+func (p *pool) process(wid string) {
+	for p.ctx.Err() == nil {
+		job, err := p.mgr.Fetch(p.ctx, wid, p.opts.Queues...)
+		if err != nil {
+			if p.ctx.Err() == nil {
+				util.Warnf("Unable to fetch %s jobs: %v", JobType, err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		if job == nil {
+			continue
+		}
+
+		// deliveries aren't interrupted by shutdown so we don't
+		// send a webhook twice unnecessarily
+		ctx := context.Background()
+		job, err = p.server.Deliver(ctx, p.mgr, job)
+		if err != nil {
+			util.Warnf("Unable to deliver %s: %v", JobType, err)
+			continue
+		}
+		failure := perform(ctx, p.client, job)
+		if failure == nil {
+			_, err = p.mgr.Acknowledge(ctx, job.Jid)
+		} else {
+			err = p.mgr.Fail(ctx, failure)
+		}
+		if err != nil {
+			util.Warnf("Unable to complete %s %s: %v", JobType, job.Jid, err)
+		}
+	}
+}
+
+// perform executes the job, returning the failure to report if unsuccessful.
+func perform(ctx context.Context, hc *http.Client, job *client.Job) *manager.FailPayload {
+	if job.Type != JobType {
+		return &manager.FailPayload{
+			Jid:          job.Jid,
+			ErrorType:    "UnknownJobType",
+			ErrorMessage: fmt.Sprintf("%s workers cannot execute %s jobs", JobType, job.Type),
+		}
+	}
+
+	req, err := ParseRequest(job)
+	if err != nil {
+		return &manager.FailPayload{
+			Jid:          job.Jid,
+			ErrorType:    "ArgumentError",
+			ErrorMessage: err.Error(),
+		}
+	}
+
+	err = Deliver(ctx, hc, req)
+	if err == nil {
+		return nil
+	}
+
+	failure := &manager.FailPayload{
+		Jid:          job.Jid,
+		ErrorType:    "WebhookError",
+		ErrorMessage: err.Error(),
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		failure.ErrorType = fmt.Sprintf("HTTP %d", se.Code)
+		if se.Body != "" {
+			failure.Backtrace = strings.Split(se.Body, "\n")
+		}
+	}
+	return failure
+}
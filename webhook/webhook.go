@@ -0,0 +1,167 @@
+// Package webhook executes the built-in "faktory.webhook" jobtype within
+// the server so applications can deliver HTTP requests without running
+// a worker of their own.  Push a job with a single argument:
+//
+//	{
+//	  "jobtype": "faktory.webhook",
+//	  "queue": "webhooks",
+//	  "args": [{
+//	    "url": "https://example.com/hook",
+//	    "method": "POST",
+//	    "headers": {"Authorization": "Bearer abc123"},
+//	    "body": {"event": "signup", "id": 123},
+//	    "expect": 202
+//	  }]
+//	}
+//
+// A body which is not a string is sent as JSON.  Without "expect" any
+// 2xx response is a success.  Any other response or error fails the job
+// so it follows the normal retry and dead set flow.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+)
+
+const (
+	JobType = "faktory.webhook"
+)
+
+var (
+	DefaultTimeout = 30 * time.Second
+	MaxTimeout     = 10 * time.Minute
+
+	// how much of the response body to keep when a request fails
+	maxBodySnippet = int64(1024)
+)
+
+type Request struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty"`
+	Expect  int               `json:"expect,omitempty"`
+	// Timeout in seconds
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// NewJob creates a webhook job for the given request.
+func NewJob(req *Request) *client.Job {
+	return client.NewJob(JobType, req)
+}
+
+// ParseRequest extracts and validates the Request in a webhook job's arguments.
+func ParseRequest(job *client.Job) (*Request, error) {
+	if len(job.Args) != 1 {
+		return nil, fmt.Errorf("%s expects a single argument, got %d", JobType, len(job.Args))
+	}
+	data, err := json.Marshal(job.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	var req Request
+	err = json.Unmarshal(data, &req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s argument: %w", JobType, err)
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q, must be absolute http or https", req.URL)
+	}
+	if req.Method == "" {
+		req.Method = "POST"
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.Expect != 0 && (req.Expect < 100 || req.Expect > 599) {
+		return nil, fmt.Errorf("invalid expected status %d", req.Expect)
+	}
+	if req.Timeout < 0 || time.Duration(req.Timeout)*time.Second > MaxTimeout {
+		return nil, fmt.Errorf("timeout must be between 0 and %d seconds", int(MaxTimeout.Seconds()))
+	}
+	return &req, nil
+}
+
+// A StatusError is returned when the endpoint responds with an
+// unexpected status code.
+type StatusError struct {
+	Method string
+	URL    string
+	Status string
+	Code   int
+	Body   string
+}
+
+func (se *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %s from %s %s", se.Status, se.Method, se.URL)
+}
+
+// Deliver performs the HTTP request and verifies the response status.
+func Deliver(ctx context.Context, hc *http.Client, req *Request) error {
+	timeout := DefaultTimeout
+	if req.Timeout > 0 {
+		timeout = time.Duration(req.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	contentType := ""
+	switch val := req.Body.(type) {
+	case nil:
+	case string:
+		body = strings.NewReader(val)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	hreq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("User-Agent", client.Name+"/"+client.Version)
+	if contentType != "" {
+		hreq.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range req.Headers {
+		hreq.Header.Set(name, value)
+	}
+
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if req.Expect == resp.StatusCode || (req.Expect == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySnippet))
+	return &StatusError{
+		Method: req.Method,
+		URL:    req.URL,
+		Status: resp.Status,
+		Code:   resp.StatusCode,
+		Body:   string(snippet),
+	}
+}
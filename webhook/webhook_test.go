@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequest(t *testing.T) {
+	job := NewJob(&Request{URL: "https://example.com/hook", Method: "put", Expect: 202})
+	assert.Equal(t, JobType, job.Type)
+	req, err := ParseRequest(job)
+	assert.NoError(t, err)
+	assert.Equal(t, "PUT", req.Method)
+	assert.Equal(t, 202, req.Expect)
+
+	req, err = ParseRequest(NewJob(&Request{URL: "http://example.com"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+
+	invalid := []*client.Job{
+		client.NewJob(JobType),
+		client.NewJob(JobType, "https://example.com"),
+		client.NewJob(JobType, 1, 2),
+		NewJob(&Request{URL: "/relative"}),
+		NewJob(&Request{URL: "ftp://example.com"}),
+		NewJob(&Request{URL: "https://example.com", Expect: 1000}),
+		NewJob(&Request{URL: "https://example.com", Timeout: -1}),
+	}
+	for _, job := range invalid {
+		_, err := ParseRequest(job)
+		assert.Error(t, err, job.Args)
+	}
+}
+
+func TestPerform(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/json":
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "secret", r.Header.Get("X-Token"))
+			assert.JSONEq(t, `{"event":"signup","id":123}`, string(body))
+		case "/text":
+			assert.Equal(t, "hello", string(body))
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("down for maintenance\ntry later"))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	hc := ts.Client()
+
+	job := NewJob(&Request{
+		URL:     ts.URL + "/json",
+		Headers: map[string]string{"X-Token": "secret"},
+		Body:    map[string]any{"event": "signup", "id": 123},
+	})
+	assert.Nil(t, perform(ctx, hc, job))
+
+	job = NewJob(&Request{URL: ts.URL + "/text", Body: "hello", Expect: 202})
+	assert.Nil(t, perform(ctx, hc, job))
+
+	// success status, but not the one expected
+	job = NewJob(&Request{URL: ts.URL + "/json", Body: map[string]any{"event": "signup", "id": 123}, Headers: map[string]string{"X-Token": "secret"}, Expect: 201})
+	failure := perform(ctx, hc, job)
+	assert.NotNil(t, failure)
+	assert.Equal(t, "HTTP 200", failure.ErrorType)
+
+	job = NewJob(&Request{URL: ts.URL + "/down"})
+	failure = perform(ctx, hc, job)
+	assert.NotNil(t, failure)
+	assert.Equal(t, job.Jid, failure.Jid)
+	assert.Equal(t, "HTTP 503", failure.ErrorType)
+	assert.Contains(t, failure.ErrorMessage, "503 Service Unavailable")
+	assert.Equal(t, []string{"down for maintenance", "try later"}, failure.Backtrace)
+
+	failure = perform(ctx, hc, client.NewJob("SomeJob", 1))
+	assert.Equal(t, "UnknownJobType", failure.ErrorType)
+
+	failure = perform(ctx, hc, client.NewJob(JobType, "nope"))
+	assert.Equal(t, "ArgumentError", failure.ErrorType)
+}
+
+func TestParseOptions(t *testing.T) {
+	so := &server.ServerOptions{GlobalConfig: map[string]any{}}
+	opts, err := parseOptions(so)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, opts.Concurrency)
+
+	so.GlobalConfig["webhook_jobs"] = map[string]any{"enabled": true}
+	opts, err = parseOptions(so)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Concurrency: 10, Queues: []string{"webhooks"}}, opts)
+
+	so.GlobalConfig["webhook_jobs"] = map[string]any{"enabled": true, "concurrency": int64(2), "queues": []any{"a", "b"}}
+	opts, err = parseOptions(so)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Concurrency: 2, Queues: []string{"a", "b"}}, opts)
+
+	so.GlobalConfig["webhook_jobs"] = map[string]any{"enabled": true, "queues": "a"}
+	_, err = parseOptions(so)
+	assert.Error(t, err)
+}
+
+func TestPool(t *testing.T) {
+	delivered := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		delivered <- string(body)
+	}))
+	defer ts.Close()
+
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	t.Setenv("FAKTORY_ENCRYPTION_KEYS", "test:"+base64.StdEncoding.EncodeToString(key))
+	s, err := server.NewServer(&server.ServerOptions{
+		GlobalConfig: map[string]any{
+			"payloads": map[string]any{"offload_size": int64(16)},
+		},
+		Binding:          "localhost:0",
+		StorageDirectory: t.TempDir(),
+		ConfigDirectory:  t.TempDir(),
+		PoolSize:         server.DefaultMaxPoolSize,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.BootWithStore(storage.NewMemoryStore()))
+	defer s.Stop(nil)
+
+	// encrypted and large enough to be offloaded
+	job := NewJob(&Request{URL: ts.URL, Body: "a webhook body which is stored on disk"})
+	job.Queue = "webhooks"
+	assert.NoError(t, s.Manager().Push(context.Background(), job))
+	assert.True(t, s.Encrypted(job))
+
+	p := newPool(s, s.Manager(), Options{Concurrency: 1, Queues: []string{"webhooks"}})
+	p.start()
+	defer p.stop()
+	select {
+	case body := <-delivered:
+		assert.Equal(t, "a webhook body which is stored on disk", body)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "webhook wasn't delivered")
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	delivered := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		delivered <- string(body)
+	}))
+	defer ts.Close()
+
+	s, err := server.NewServer(&server.ServerOptions{
+		GlobalConfig: map[string]any{
+			"webhook_jobs": map[string]any{"enabled": true, "concurrency": int64(1)},
+			"namespaces": map[string]any{
+				"billing": map[string]any{"password": "billpass", "database": int64(1)},
+			},
+		},
+		Binding:          "localhost:0",
+		StorageDirectory: t.TempDir(),
+		ConfigDirectory:  t.TempDir(),
+		PoolSize:         server.DefaultMaxPoolSize,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.BootWithStore(storage.NewMemoryStore()))
+	defer s.Stop(nil)
+	defer s.Shutdown()
+	l := Subsystem()
+	assert.NoError(t, l.Start(s))
+
+	for _, ns := range s.Namespaces() {
+		job := NewJob(&Request{URL: ts.URL, Body: "hello " + ns.Name})
+		job.Queue = "webhooks"
+		assert.NoError(t, ns.Manager().Push(context.Background(), job))
+	}
+	bodies := []string{}
+	for range s.Namespaces() {
+		select {
+		case body := <-delivered:
+			bodies = append(bodies, body)
+		case <-time.After(5 * time.Second):
+			assert.FailNow(t, "webhook wasn't delivered")
+		}
+	}
+	assert.ElementsMatch(t, []string{"hello ", "hello billing"}, bodies)
+}
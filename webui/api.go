@@ -0,0 +1,174 @@
+package webui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/util"
+)
+
+var (
+	// Limit request bodies to protect the server from huge uploads.
+	MaxAPIBodySize int64 = 16 * 1024 * 1024
+	// PUSHB allows any number of jobs but HTTP requests must be bounded.
+	MaxBulkJobs = 1000
+
+	// HTTP status for each KnownError code, any other known error
+	// is a 422.
+	apiErrorStatus = map[string]int{
+		"NOTUNIQUE": http.StatusConflict,
+		"OVERLOAD":  http.StatusTooManyRequests,
+	}
+)
+
+// API handlers are not protected from cross-origin requests like the
+// UI pages since they are called by non-browser clients.  Requiring a
+// JSON content type ensures browsers must make a CORS preflight, which
+// Faktory never approves.
+func API(ui *WebUI, pass http.HandlerFunc) http.HandlerFunc {
+	return setup(ui, func(w http.ResponseWriter, r *http.Request) {
+		ct, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || ct != "application/json" {
+			apiError(w, http.StatusUnsupportedMediaType, "", fmt.Errorf("Content-Type must be application/json"))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, MaxAPIBodySize)
+		pass(w, r)
+	}, false)
+}
+
+// POST /api/push
+//
+//	{"jobtype":"SomeJob","args":[1,2,3]}
+//	=> {"jid":"..."}
+func apiPushHandler(w http.ResponseWriter, r *http.Request) {
+	var job client.Job
+	if !apiDecode(w, r, &job) {
+		return
+	}
+
+	status, code, err := apiPush(r, &job)
+	if err != nil {
+		apiError(w, status, code, err)
+		return
+	}
+	apiResult(w, http.StatusOK, map[string]string{"jid": job.Jid})
+}
+
+// POST /api/push/bulk
+//
+//	[{"jobtype":"SomeJob","args":[1,2,3]}, ...]
+//	=> {"jids":["..."],"errors":{"jid":"message"}}
+//
+// Like PUSHB, a response lists the jobs which could not be pushed
+// rather than failing the entire request.
+func apiPushBulkHandler(w http.ResponseWriter, r *http.Request) {
+	jobs := []*client.Job{}
+	if !apiDecode(w, r, &jobs) {
+		return
+	}
+	if len(jobs) > MaxBulkJobs {
+		apiError(w, http.StatusRequestEntityTooLarge, "", fmt.Errorf("cannot push more than %d jobs at once", MaxBulkJobs))
+		return
+	}
+
+	jids := []string{}
+	errs := map[string]string{}
+	for idx, job := range jobs {
+		if job == nil {
+			errs[fmt.Sprintf("%d", idx)] = "job cannot be null"
+			continue
+		}
+		_, _, err := apiPush(r, job)
+		if err != nil {
+			errs[job.Jid] = err.Error()
+			continue
+		}
+		jids = append(jids, job.Jid)
+	}
+	apiResult(w, http.StatusOK, map[string]any{"jids": jids, "errors": errs})
+}
+
+// apiPush fills in the same defaults as PUSH and returns the HTTP
+// status and error code for any failure.
+func apiPush(r *http.Request, job *client.Job) (int, string, error) {
+	if job.Jid == "" {
+		job.Jid = client.RandomJid()
+	}
+	if job.Retry == nil {
+		job.Retry = &client.RetryPolicyDefault
+	}
+	if job.CreatedAt == "" {
+		job.CreatedAt = util.Nows()
+	}
+	if err := manager.ValidateJob(job); err != nil {
+		return http.StatusUnprocessableEntity, "", err
+	}
+
+	err := ctx(r).Manager().Push(pushContext(r), job)
+	if err != nil {
+		var known manager.KnownError
+		if errors.As(err, &known) {
+			status, ok := apiErrorStatus[known.Code()]
+			if !ok {
+				status = http.StatusUnprocessableEntity
+			}
+			return status, known.Code(), err
+		}
+		return http.StatusInternalServerError, "", err
+	}
+	return http.StatusOK, "", nil
+}
+
+// pushContext identifies the pusher to admission control by the remote
+// host and Basic Auth username.
+func pushContext(r *http.Request) context.Context {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	user, _, _ := r.BasicAuth()
+	return server.WithPusher(r.Context(), remote, user)
+}
+
+func apiDecode(w http.ResponseWriter, r *http.Request, value any) bool {
+	err := json.NewDecoder(r.Body).Decode(value)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		apiError(w, http.StatusRequestEntityTooLarge, "", fmt.Errorf("request body cannot be larger than %d bytes", tooLarge.Limit))
+	} else {
+		apiError(w, http.StatusBadRequest, "", fmt.Errorf("invalid JSON: %w", err))
+	}
+	return false
+}
+
+func apiError(w http.ResponseWriter, status int, code string, err error) {
+	payload := map[string]string{"error": err.Error()}
+	if code != "" {
+		payload["code"] = code
+	}
+	apiResult(w, status, payload)
+}
+
+func apiResult(w http.ResponseWriter, status int, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,118 @@
+package webui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI(t *testing.T) {
+	bootRuntime(t, "api", func(ui *WebUI, s *server.Server, t *testing.T) {
+		bg := context.Background()
+
+		call := func(path string, contentType string, body string) *httptest.ResponseRecorder {
+			req, err := ui.NewRequest("POST", "http://localhost:7420"+path, strings.NewReader(body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			handler := apiPushHandler
+			if strings.HasSuffix(path, "/bulk") {
+				handler = apiPushBulkHandler
+			}
+			API(ui, PostOnly(handler))(w, req)
+			return w
+		}
+
+		t.Run("Push", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(bg))
+
+			w := call("/api/push", "application/json", `{"jobtype":"ApiJob","args":[1,2],"queue":"api"}`)
+			assert.Equal(t, 200, w.Code, w.Body.String())
+			var result map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.NotEmpty(t, result["jid"])
+
+			q, ok := s.Store().ExistingQueue(bg, "api")
+			assert.True(t, ok)
+			assert.EqualValues(t, 1, q.Size(bg))
+
+			w = call("/api/push", "text/plain", `{"jobtype":"ApiJob","args":[]}`)
+			assert.Equal(t, 415, w.Code)
+
+			w = call("/api/push", "application/json", `{"jobtype":`)
+			assert.Equal(t, 400, w.Code)
+			assert.Contains(t, w.Body.String(), "invalid JSON")
+
+			w = call("/api/push", "application/json; charset=utf-8", `{"args":[]}`)
+			assert.Equal(t, 422, w.Code)
+			assert.Contains(t, w.Body.String(), "jobtype")
+		})
+
+		t.Run("PushBulk", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(bg))
+
+			w := call("/api/push/bulk", "application/json", `[{"jobtype":"ApiJob","args":[1]},{"jid":"invalid-1","args":[2]},null]`)
+			assert.Equal(t, 200, w.Code, w.Body.String())
+			var result struct {
+				Jids   []string          `json:"jids"`
+				Errors map[string]string `json:"errors"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Len(t, result.Jids, 1)
+			assert.Len(t, result.Errors, 2)
+			assert.Contains(t, result.Errors["invalid-1"], "jobtype")
+		})
+
+		t.Run("Overload", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(context.Background()))
+			s.Options.GlobalConfig = map[string]any{"limits": map[string]any{"enabled": true, "max_size": int64(1)}}
+			s.Reload()
+			defer func() {
+				s.Options.GlobalConfig = map[string]any{}
+				s.Reload()
+			}()
+
+			w := call("/api/push", "application/json", `{"jobtype":"ApiJob","args":[]}`)
+			assert.Equal(t, 200, w.Code, w.Body.String())
+			w = call("/api/push", "application/json", `{"jobtype":"ApiJob","args":[]}`)
+			assert.Equal(t, 429, w.Code, w.Body.String())
+			var result map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, "OVERLOAD", result["code"])
+		})
+
+		t.Run("RateLimit", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(context.Background()))
+			s.Options.GlobalConfig = map[string]any{"limits": map[string]any{"enabled": true, "push_rate": int64(1)}}
+			s.Reload()
+			defer func() {
+				s.Options.GlobalConfig = map[string]any{}
+				s.Reload()
+			}()
+
+			w := call("/api/push", "application/json", `{"jobtype":"ApiJob","args":[]}`)
+			assert.Equal(t, 200, w.Code, w.Body.String())
+			w = call("/api/push", "application/json", `{"jobtype":"ApiJob","args":[]}`)
+			assert.Equal(t, 429, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), "per second")
+		})
+
+		t.Run("KnownErrors", func(t *testing.T) {
+			s.Manager().AddMiddleware("push", func(ctx context.Context, next func() error) error {
+				return manager.Halt("NOTUNIQUE", "job is not unique")
+			})
+
+			w := call("/api/push", "application/json", `{"jobtype":"ApiJob","args":[]}`)
+			assert.Equal(t, 409, w.Code, w.Body.String())
+			var result map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, "NOTUNIQUE", result["code"])
+		})
+	})
+}
@@ -0,0 +1,138 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line audit.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+
+	"github.com/contribsys/faktory/storage"
+)
+
+func ego_audit(w io.Writer, req *http.Request, entries []storage.AuditEntry, count, currentPage uint64) {
+	totalSize := ctx(req).Server().Store().AuditSize(req.Context())
+
+//line audit.ego:13
+	_, _ = io.WriteString(w, "\n\n")
+//line audit.ego:14
+	ego_layout(w, req, func() {
+//line audit.ego:15
+		_, _ = io.WriteString(w, "\n\n<header class=\"row\">\n  <div class=\"col-5\">\n    <h3>")
+//line audit.ego:18
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AuditLog"))))
+//line audit.ego:18
+		_, _ = io.WriteString(w, "</h3>\n  </div>\n  ")
+//line audit.ego:20
+		if totalSize > count {
+//line audit.ego:21
+			_, _ = io.WriteString(w, "\n    <div class=\"col-7 d-flex justify-content-end\">\n      ")
+//line audit.ego:22
+			ego_paging(w, req, "/audit", totalSize, count, currentPage)
+//line audit.ego:23
+			_, _ = io.WriteString(w, "\n    </div>\n  ")
+//line audit.ego:24
+		}
+//line audit.ego:25
+		_, _ = io.WriteString(w, "\n</header>\n\n")
+//line audit.ego:27
+		if !ctx(req).Server().AuditEnabled() {
+//line audit.ego:28
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-info\">")
+//line audit.ego:28
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AuditDisabled"))))
+//line audit.ego:28
+			_, _ = io.WriteString(w, "</div>\n")
+//line audit.ego:29
+		}
+//line audit.ego:30
+		_, _ = io.WriteString(w, "\n\n")
+//line audit.ego:31
+		if len(entries) > 0 {
+//line audit.ego:32
+			_, _ = io.WriteString(w, "\n  <div class=\"table-responsive\">\n    <table class=\"table table-striped table-bordered table-light\">\n      <thead>\n        <tr>\n          <th>")
+//line audit.ego:36
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "When"))))
+//line audit.ego:36
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line audit.ego:37
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Actor"))))
+//line audit.ego:37
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line audit.ego:38
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "RemoteAddress"))))
+//line audit.ego:38
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line audit.ego:39
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Action"))))
+//line audit.ego:39
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line audit.ego:40
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Target"))))
+//line audit.ego:40
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line audit.ego:41
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Jobs"))))
+//line audit.ego:41
+			_, _ = io.WriteString(w, "</th>\n        </tr>\n      </thead>\n      ")
+//line audit.ego:44
+			for _, entry := range entries {
+//line audit.ego:45
+				_, _ = io.WriteString(w, "\n        <tr>\n          <td>")
+//line audit.ego:46
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(Timeago(entry.At))))
+//line audit.ego:46
+				_, _ = io.WriteString(w, "</td>\n          <td><code>")
+//line audit.ego:47
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(entry.Actor)))
+//line audit.ego:47
+				_, _ = io.WriteString(w, "</code></td>\n          <td>")
+//line audit.ego:48
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(entry.Remote)))
+//line audit.ego:48
+				_, _ = io.WriteString(w, "</td>\n          <td><code>")
+//line audit.ego:49
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(entry.Command)))
+//line audit.ego:49
+				_, _ = io.WriteString(w, "</code></td>\n          <td>")
+//line audit.ego:50
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(entry.Target)))
+//line audit.ego:50
+				_, _ = io.WriteString(w, "</td>\n          <td>")
+//line audit.ego:51
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(entry.Count)))
+//line audit.ego:51
+				_, _ = io.WriteString(w, "</td>\n        </tr>\n      ")
+//line audit.ego:53
+			}
+//line audit.ego:54
+			_, _ = io.WriteString(w, "\n    </table>\n  </div>\n")
+//line audit.ego:56
+		} else if ctx(req).Server().AuditEnabled() {
+//line audit.ego:57
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-success\">")
+//line audit.ego:57
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "NoAuditEntriesFound"))))
+//line audit.ego:57
+			_, _ = io.WriteString(w, "</div>\n")
+//line audit.ego:58
+		}
+//line audit.ego:59
+		_, _ = io.WriteString(w, "\n")
+//line audit.ego:59
+	})
+//line audit.ego:60
+	_, _ = io.WriteString(w, "\n")
+//line audit.ego:60
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
@@ -0,0 +1,128 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line completed.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+)
+
+func ego_listCompleted(w io.Writer, req *http.Request, query string) {
+	cqs := completedQueues(req)
+
+//line completed.ego:11
+	_, _ = io.WriteString(w, "\n\n")
+//line completed.ego:12
+	ego_layout(w, req, func() {
+//line completed.ego:13
+		_, _ = io.WriteString(w, "\n\n<header class=\"row\">\n  <div class=\"col-5\">\n    <h3>")
+//line completed.ego:16
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "CompletedJobs"))))
+//line completed.ego:16
+		_, _ = io.WriteString(w, "</h3>\n  </div>\n  <div class=\"col-7 d-flex justify-content-end\">\n    <form class=\"form-inline\" action=\"")
+//line completed.ego:19
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line completed.ego:19
+		_, _ = io.WriteString(w, "/completed\" method=\"get\">\n      <input class=\"form-control\" type=\"search\" name=\"q\" value=\"")
+//line completed.ego:20
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(query)))
+//line completed.ego:20
+		_, _ = io.WriteString(w, "\" placeholder=\"")
+//line completed.ego:20
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Search"))))
+//line completed.ego:20
+		_, _ = io.WriteString(w, "\" />\n    </form>\n  </div>\n</header>\n\n")
+//line completed.ego:25
+		if query != "" {
+//line completed.ego:26
+			_, _ = io.WriteString(w, "\n  ")
+//line completed.ego:26
+			jobs := searchCompleted(req, cqs, query, 100)
+//line completed.ego:27
+			_, _ = io.WriteString(w, "\n  ")
+//line completed.ego:27
+			if len(jobs) > 0 {
+//line completed.ego:28
+				_, _ = io.WriteString(w, "\n    ")
+//line completed.ego:28
+				ego_completedTable(w, req, jobs)
+//line completed.ego:29
+				_, _ = io.WriteString(w, "\n  ")
+//line completed.ego:29
+			} else {
+//line completed.ego:30
+				_, _ = io.WriteString(w, "\n    <div class=\"alert alert-success\">")
+//line completed.ego:30
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "NoCompletedJobsFound"))))
+//line completed.ego:30
+				_, _ = io.WriteString(w, "</div>\n  ")
+//line completed.ego:31
+			}
+//line completed.ego:32
+			_, _ = io.WriteString(w, "\n")
+//line completed.ego:32
+		} else if len(cqs) > 0 {
+//line completed.ego:33
+			_, _ = io.WriteString(w, "\n  <div class=\"table-responsive\">\n    <table class=\"queues table table-hover table-bordered table-striped table-light\">\n      <thead>\n        <th>")
+//line completed.ego:36
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Queue"))))
+//line completed.ego:36
+			_, _ = io.WriteString(w, "</th>\n        <th>")
+//line completed.ego:37
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Size"))))
+//line completed.ego:37
+			_, _ = io.WriteString(w, "</th>\n      </thead>\n      ")
+//line completed.ego:39
+			for _, cq := range cqs {
+//line completed.ego:40
+				_, _ = io.WriteString(w, "\n        <tr>\n          <td>\n            <a href=\"")
+//line completed.ego:42
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line completed.ego:42
+				_, _ = io.WriteString(w, "/completed/")
+//line completed.ego:42
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(cq.Name)))
+//line completed.ego:42
+				_, _ = io.WriteString(w, "\">")
+//line completed.ego:42
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(cq.Name)))
+//line completed.ego:42
+				_, _ = io.WriteString(w, "</a>\n          </td>\n          <td>")
+//line completed.ego:44
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(cq.Size))))
+//line completed.ego:44
+				_, _ = io.WriteString(w, "</td>\n        </tr>\n      ")
+//line completed.ego:46
+			}
+//line completed.ego:47
+			_, _ = io.WriteString(w, "\n    </table>\n  </div>\n")
+//line completed.ego:49
+		} else {
+//line completed.ego:50
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-success\">")
+//line completed.ego:50
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "NoCompletedJobsFound"))))
+//line completed.ego:50
+			_, _ = io.WriteString(w, "</div>\n")
+//line completed.ego:51
+		}
+//line completed.ego:52
+		_, _ = io.WriteString(w, "\n")
+//line completed.ego:52
+	})
+//line completed.ego:53
+	_, _ = io.WriteString(w, "\n")
+//line completed.ego:53
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
@@ -0,0 +1,98 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line completed_queue.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+
+	"github.com/contribsys/faktory/storage"
+)
+
+func ego_completedQueue(w io.Writer, req *http.Request, name string, set storage.SortedSet, query string, count, currentPage uint64) {
+	totalSize := uint64(set.Size(req.Context()))
+
+//line completed_queue.ego:13
+	_, _ = io.WriteString(w, "\n\n")
+//line completed_queue.ego:14
+	ego_layout(w, req, func() {
+//line completed_queue.ego:15
+		_, _ = io.WriteString(w, "\n\n<header class=\"row\">\n  <div class=\"col-5\">\n    <h3>")
+//line completed_queue.ego:18
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "CompletedJobs"))))
+//line completed_queue.ego:18
+		_, _ = io.WriteString(w, ": ")
+//line completed_queue.ego:18
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(name)))
+//line completed_queue.ego:18
+		_, _ = io.WriteString(w, "</h3>\n  </div>\n  <div class=\"col-7 d-flex justify-content-end\">\n    <form class=\"form-inline\" action=\"")
+//line completed_queue.ego:21
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line completed_queue.ego:21
+		_, _ = io.WriteString(w, "/completed/")
+//line completed_queue.ego:21
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(name)))
+//line completed_queue.ego:21
+		_, _ = io.WriteString(w, "\" method=\"get\">\n      <input class=\"form-control\" type=\"search\" name=\"q\" value=\"")
+//line completed_queue.ego:22
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(query)))
+//line completed_queue.ego:22
+		_, _ = io.WriteString(w, "\" placeholder=\"")
+//line completed_queue.ego:22
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Search"))))
+//line completed_queue.ego:22
+		_, _ = io.WriteString(w, "\" />\n    </form>\n    ")
+//line completed_queue.ego:24
+		if query == "" && totalSize > count {
+//line completed_queue.ego:25
+			_, _ = io.WriteString(w, "\n      ")
+//line completed_queue.ego:25
+			ego_paging(w, req, "/completed/"+name, totalSize, count, currentPage)
+//line completed_queue.ego:26
+			_, _ = io.WriteString(w, "\n    ")
+//line completed_queue.ego:26
+		}
+//line completed_queue.ego:27
+		_, _ = io.WriteString(w, "\n  </div>\n</header>\n\n")
+//line completed_queue.ego:30
+		var jobs = completedPage(req, set, query, count, currentPage)
+//line completed_queue.ego:31
+		_, _ = io.WriteString(w, "\n")
+//line completed_queue.ego:31
+		if len(jobs) > 0 {
+//line completed_queue.ego:32
+			_, _ = io.WriteString(w, "\n  ")
+//line completed_queue.ego:32
+			ego_completedTable(w, req, jobs)
+//line completed_queue.ego:33
+			_, _ = io.WriteString(w, "\n")
+//line completed_queue.ego:33
+		} else {
+//line completed_queue.ego:34
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-success\">")
+//line completed_queue.ego:34
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "NoCompletedJobsFound"))))
+//line completed_queue.ego:34
+			_, _ = io.WriteString(w, "</div>\n")
+//line completed_queue.ego:35
+		}
+//line completed_queue.ego:36
+		_, _ = io.WriteString(w, "\n")
+//line completed_queue.ego:36
+	})
+//line completed_queue.ego:37
+	_, _ = io.WriteString(w, "\n")
+//line completed_queue.ego:37
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
@@ -0,0 +1,105 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line completed_table.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+
+	"github.com/contribsys/faktory/manager"
+)
+
+func ego_completedTable(w io.Writer, req *http.Request, jobs []*manager.CompletedJob) {
+
+//line completed_table.ego:12
+	_, _ = io.WriteString(w, "\n<div class=\"table-responsive\">\n  <table class=\"table table-striped table-bordered table-light\">\n    <thead>\n      <tr>\n        <th>")
+//line completed_table.ego:16
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Completed"))))
+//line completed_table.ego:16
+	_, _ = io.WriteString(w, "</th>\n        <th>JID</th>\n        <th>")
+//line completed_table.ego:18
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Queue"))))
+//line completed_table.ego:18
+	_, _ = io.WriteString(w, "</th>\n        <th>")
+//line completed_table.ego:19
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Job"))))
+//line completed_table.ego:19
+	_, _ = io.WriteString(w, "</th>\n        <th>")
+//line completed_table.ego:20
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Arguments"))))
+//line completed_table.ego:20
+	_, _ = io.WriteString(w, "</th>\n        <th>")
+//line completed_table.ego:21
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Enqueued"))))
+//line completed_table.ego:21
+	_, _ = io.WriteString(w, "</th>\n        <th>")
+//line completed_table.ego:22
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Duration"))))
+//line completed_table.ego:22
+	_, _ = io.WriteString(w, "</th>\n        <th>")
+//line completed_table.ego:23
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Worker"))))
+//line completed_table.ego:23
+	_, _ = io.WriteString(w, "</th>\n      </tr>\n    </thead>\n    ")
+//line completed_table.ego:26
+	for _, cj := range jobs {
+//line completed_table.ego:27
+		_, _ = io.WriteString(w, "\n      <tr>\n        <td>")
+//line completed_table.ego:28
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relativeTime(cj.AckedAt))))
+//line completed_table.ego:28
+		_, _ = io.WriteString(w, "</td>\n        <td><code>")
+//line completed_table.ego:29
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(cj.Jid)))
+//line completed_table.ego:29
+		_, _ = io.WriteString(w, "</code></td>\n        <td>\n          <a href=\"")
+//line completed_table.ego:31
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line completed_table.ego:31
+		_, _ = io.WriteString(w, "/completed/")
+//line completed_table.ego:31
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(cj.Queue)))
+//line completed_table.ego:31
+		_, _ = io.WriteString(w, "\">")
+//line completed_table.ego:31
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(cj.Queue)))
+//line completed_table.ego:31
+		_, _ = io.WriteString(w, "</a>\n        </td>\n        <td><code>")
+//line completed_table.ego:33
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayJobType(cj.Job))))
+//line completed_table.ego:33
+		_, _ = io.WriteString(w, "</code></td>\n        <td>\n          <div class=\"args\">")
+//line completed_table.ego:35
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayJobArgs(req, cj.Job))))
+//line completed_table.ego:35
+		_, _ = io.WriteString(w, "</div>\n        </td>\n        <td>")
+//line completed_table.ego:37
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relativeTime(cj.EnqueuedAt))))
+//line completed_table.ego:37
+		_, _ = io.WriteString(w, "</td>\n        <td>")
+//line completed_table.ego:38
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayDuration(cj.Duration()))))
+//line completed_table.ego:38
+		_, _ = io.WriteString(w, "</td>\n        <td><code>")
+//line completed_table.ego:39
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(cj.Wid)))
+//line completed_table.ego:39
+		_, _ = io.WriteString(w, "</code></td>\n      </tr>\n    ")
+//line completed_table.ego:41
+	}
+//line completed_table.ego:42
+	_, _ = io.WriteString(w, "\n  </table>\n</div>\n")
+//line completed_table.ego:44
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
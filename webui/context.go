@@ -4,10 +4,12 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/contribsys/faktory/manager"
 	"github.com/contribsys/faktory/server"
 	"github.com/contribsys/faktory/storage"
 )
@@ -24,11 +26,13 @@ type DefaultContext struct {
 
 	response http.ResponseWriter
 
-	webui   *WebUI
-	request *http.Request
-	strings map[string]string
-	locale  string
-	Root    string
+	webui     *WebUI
+	request   *http.Request
+	strings   map[string]string
+	locale    string
+	namespace *server.Namespace
+	scoped    bool
+	Root      string
 }
 
 func NewContext(ui *WebUI, req *http.Request, resp http.ResponseWriter) *DefaultContext {
@@ -47,14 +51,18 @@ func NewContext(ui *WebUI, req *http.Request, resp http.ResponseWriter) *Default
 
 	resp.Header().Set("Content-Language", locale)
 
+	ns, scoped := ui.namespaceFor(req)
+
 	return &DefaultContext{
-		Context:  req.Context(),
-		webui:    ui,
-		request:  req,
-		response: resp,
-		locale:   locale,
-		strings:  translations(locale),
-		Root:     req.Header.Get("X-Script-Name"),
+		Context:   req.Context(),
+		webui:     ui,
+		request:   req,
+		response:  resp,
+		locale:    locale,
+		strings:   translations(locale),
+		namespace: ns,
+		scoped:    scoped,
+		Root:      req.Header.Get("X-Script-Name"),
 	}
 }
 
@@ -66,14 +74,45 @@ func (d *DefaultContext) Request() *http.Request {
 	return d.request
 }
 
+// Store returns the store of the namespace being viewed.
 func (d *DefaultContext) Store() storage.Store {
-	return d.webui.Server.Store()
+	return d.Namespace().Store()
+}
+
+// Manager returns the manager of the namespace being viewed.
+func (d *DefaultContext) Manager() manager.Manager {
+	return d.Namespace().Manager()
 }
 
 func (d *DefaultContext) Server() *server.Server {
 	return d.webui.Server
 }
 
+// Namespace returns the namespace being viewed.
+func (d *DefaultContext) Namespace() *server.Namespace {
+	if d.namespace == nil {
+		return d.webui.Server.Namespace("")
+	}
+	return d.namespace
+}
+
+// Scoped returns true if the user logged in with a namespace's
+// credentials and may only see that namespace.
+func (d *DefaultContext) Scoped() bool {
+	return d.scoped
+}
+
+// CanDecrypt returns true if the user logged in with the decrypt
+// password.
+func (d *DefaultContext) CanDecrypt() bool {
+	pwd := d.webui.Options.DecryptPassword
+	if pwd == "" || d.scoped {
+		return false
+	}
+	_, password, ok := d.request.BasicAuth()
+	return ok && subtle.ConstantTimeCompare([]byte(password), []byte(pwd)) == 1
+}
+
 type Translator interface {
 	Locale() string
 	Translation(string) string
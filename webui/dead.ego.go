@@ -99,12 +99,24 @@ func ego_dead(w io.Writer, req *http.Request, key string, dead *client.Job) {
 //line dead.ego:53
 		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Delete"))))
 //line dead.ego:53
-		_, _ = io.WriteString(w, "</button>\n  </div>\n</form>\n")
-//line dead.ego:56
-	})
+		_, _ = io.WriteString(w, "</button>\n    <a class=\"btn btn-default btn-sm\" href=\"")
+//line dead.ego:54
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line dead.ego:54
+		_, _ = io.WriteString(w, "/edit/dead/")
+//line dead.ego:54
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(key)))
+//line dead.ego:54
+		_, _ = io.WriteString(w, "\">")
+//line dead.ego:54
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Edit"))))
+//line dead.ego:54
+		_, _ = io.WriteString(w, "</a>\n  </div>\n</form>\n")
 //line dead.ego:57
+	})
+//line dead.ego:58
 	_, _ = io.WriteString(w, "\n")
-//line dead.ego:57
+//line dead.ego:58
 }
 
 var _ fmt.Stringer
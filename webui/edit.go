@@ -0,0 +1,256 @@
+package webui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+var (
+	EDIT_PATH = regexp.MustCompile(`/edit/(retries|scheduled|dead)/([^/?]+)`)
+)
+
+// JobForm holds the editable attributes of a job as entered
+// on the Edit page.
+type JobForm struct {
+	Args       string
+	Custom     string
+	Queue      string
+	ReserveFor string
+}
+
+func newJobForm(job *client.Job) JobForm {
+	jf := JobForm{
+		Args:  prettyJSON(job.Args),
+		Queue: job.Queue,
+	}
+	if len(job.Custom) > 0 {
+		jf.Custom = prettyJSON(job.Custom)
+	}
+	if job.ReserveFor > 0 {
+		jf.ReserveFor = strconv.Itoa(job.ReserveFor)
+	}
+	return jf
+}
+
+func readJobForm(req *http.Request) JobForm {
+	return JobForm{
+		Args:       req.FormValue("args"),
+		Custom:     req.FormValue("custom"),
+		Queue:      strings.TrimSpace(req.FormValue("queue")),
+		ReserveFor: strings.TrimSpace(req.FormValue("reserve_for")),
+	}
+}
+
+func prettyJSON(val any) string {
+	data, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Apply returns a copy of the job with the form's attributes.  The
+// original jid, args and queue are kept in the `edited_from` custom
+// attribute so the change can be traced back.
+func (jf JobForm) Apply(job *client.Job) (*client.Job, error) {
+	edited := *job
+	edited.Args = nil
+
+	dec := json.NewDecoder(strings.NewReader(jf.Args))
+	dec.UseNumber()
+	if err := dec.Decode(&edited.Args); err != nil || edited.Args == nil {
+		return nil, fmt.Errorf("args must be a JSON array")
+	}
+
+	edited.Custom = nil
+	if strings.TrimSpace(jf.Custom) != "" {
+		dec = json.NewDecoder(strings.NewReader(jf.Custom))
+		dec.UseNumber()
+		if err := dec.Decode(&edited.Custom); err != nil {
+			return nil, fmt.Errorf("custom must be a JSON object")
+		}
+	}
+
+	if !storage.ValidQueueName.MatchString(jf.Queue) {
+		return nil, fmt.Errorf("invalid queue name: %q", jf.Queue)
+	}
+	edited.Queue = jf.Queue
+
+	edited.ReserveFor = 0
+	if jf.ReserveFor != "" {
+		val, err := strconv.Atoi(jf.ReserveFor)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("reserve_for must be a number of seconds")
+		}
+		edited.ReserveFor = val
+	}
+
+	if err := manager.ValidateJob(&edited); err != nil {
+		return nil, err
+	}
+
+	edited.SetCustom("edited_from", map[string]any{
+		"jid":   job.Jid,
+		"args":  job.Args,
+		"queue": job.Queue,
+		"at":    util.Nows(),
+	})
+	return &edited, nil
+}
+
+func editSet(store storage.Store, name string) (storage.SortedSet, string) {
+	switch name {
+	case "retries":
+		return store.Retries(), "/retries"
+	case "scheduled":
+		return store.Scheduled(), "/scheduled"
+	case "dead":
+		return store.Dead(), "/morgue"
+	}
+	return nil, ""
+}
+
+// editHandler lets the user fix a job's arguments and options.  The
+// edited job either replaces the original in its set or is pushed as
+// a new job, removing the original.  Offloaded args are restored and
+// encrypted jobs are decrypted for the form, and both are sealed again
+// when saved.
+func editHandler(w http.ResponseWriter, r *http.Request) {
+	match := EDIT_PATH.FindStringSubmatch(r.RequestURI)
+	if match == nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	key, err := url.QueryUnescape(match[2])
+	if err != nil {
+		http.Error(w, "Invalid URL input", http.StatusBadRequest)
+		return
+	}
+
+	c := r.Context()
+	store := ctx(r).Store()
+	set, listPath := editSet(store, match[1])
+	entry, err := set.Get(c, []byte(key))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		// the job was retried, deleted or processed in the meantime
+		Redirect(w, r, listPath, http.StatusTemporaryRedirect)
+		return
+	}
+	job, err := entry.Job()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	srv := ctx(r).Server()
+	job = srv.Rehydrate(job)
+	if srv.Offloaded(job) {
+		http.Error(w, "Unable to read the job's offloaded args", http.StatusInternalServerError)
+		return
+	}
+	if srv.Encrypted(job) {
+		plain, ok := visibleJob(r, job)
+		if !ok {
+			http.Error(w, "Encrypted jobs can only be edited with the decrypt password", http.StatusForbidden)
+			return
+		}
+		job = plain
+	}
+
+	if r.Method != "POST" {
+		ego_edit_job(w, r, set.Name(), key, job, newJobForm(job), nil)
+		return
+	}
+
+	jf := readJobForm(r)
+	edited, err := jf.Apply(job)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		ego_edit_job(w, r, set.Name(), key, job, jf, err)
+		return
+	}
+
+	mode := r.FormValue("mode")
+	switch mode {
+	case "replace":
+		err = replaceJob(r, set, key, edited)
+	case "push":
+		err = repushJob(r, set, key, entry, edited)
+	default:
+		http.Error(w, fmt.Sprintf("Invalid mode: %v", mode), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	audit(r, "edit "+mode, set.Name()+" "+job.Jid, 1)
+	if mode == "push" {
+		Redirect(w, r, "/queues/"+edited.Queue, http.StatusFound)
+		return
+	}
+	Redirect(w, r, listPath+"/"+url.QueryEscape(key), http.StatusFound)
+}
+
+// replaceJob swaps the job's payload within its set, keeping its
+// position.  The edited job passes through the push middleware, e.g.
+// schema validation, and is encrypted and offloaded like a pushed job.
+func replaceJob(r *http.Request, set storage.SortedSet, key string, edited *client.Job) error {
+	c := r.Context()
+	timestamp, _, ok := strings.Cut(key, "|")
+	if !ok {
+		return fmt.Errorf("invalid key: %s", key)
+	}
+
+	return ctx(r).Manager().Replace(c, edited, func(data []byte) error {
+		removed, err := set.Remove(c, []byte(key))
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("job %s was changed or removed while editing", edited.Jid)
+		}
+		return set.AddElement(c, timestamp, edited.Jid, data)
+	})
+}
+
+// repushJob enqueues the edited job with a new jid and removes the
+// original.  The original is restored if the push fails.
+func repushJob(r *http.Request, set storage.SortedSet, key string, entry storage.SortedEntry, edited *client.Job) error {
+	c := r.Context()
+	removed, err := set.Remove(c, []byte(key))
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("job %s was changed or removed while editing", edited.Jid)
+	}
+
+	edited.Jid = client.RandomJid()
+	edited.Failure = nil
+	edited.At = ""
+	edited.EnqueuedAt = ""
+	edited.CreatedAt = ""
+	err = ctx(r).Manager().Push(c, edited)
+	if err != nil {
+		timestamp, jid, _ := strings.Cut(key, "|")
+		if rerr := set.AddElement(c, timestamp, jid, entry.Value()); rerr != nil {
+			util.Error("Unable to restore edited job", rerr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line edit_job.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+
+	"github.com/contribsys/faktory/client"
+)
+
+func ego_edit_job(w io.Writer, req *http.Request, set string, key string, job *client.Job, form JobForm, formErr error) {
+	ego_layout(w, req, func() {
+//line edit_job.ego:12
+		_, _ = io.WriteString(w, "\n\n")
+//line edit_job.ego:13
+		ego_job_info(w, req, job)
+//line edit_job.ego:14
+		_, _ = io.WriteString(w, "\n\n<header>\n  <h3>")
+//line edit_job.ego:16
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "EditJob"))))
+//line edit_job.ego:16
+		_, _ = io.WriteString(w, "</h3>\n</header>\n\n")
+//line edit_job.ego:19
+		if formErr != nil {
+//line edit_job.ego:20
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-danger\">")
+//line edit_job.ego:20
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(formErr.Error())))
+//line edit_job.ego:20
+			_, _ = io.WriteString(w, "</div>\n")
+//line edit_job.ego:21
+		}
+//line edit_job.ego:22
+		_, _ = io.WriteString(w, "\n\n<form class=\"form-horizontal\" action=\"")
+//line edit_job.ego:23
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line edit_job.ego:23
+		_, _ = io.WriteString(w, "/edit/")
+//line edit_job.ego:23
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(set)))
+//line edit_job.ego:23
+		_, _ = io.WriteString(w, "/")
+//line edit_job.ego:23
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(key)))
+//line edit_job.ego:23
+		_, _ = io.WriteString(w, "\" method=\"post\">\n  ")
+//line edit_job.ego:24
+		_, _ = fmt.Fprint(w, csrfTag(req))
+//line edit_job.ego:25
+		_, _ = io.WriteString(w, "\n  <div class=\"mb-3\">\n    <label class=\"form-label\" for=\"edit-args\">")
+//line edit_job.ego:26
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Arguments"))))
+//line edit_job.ego:26
+		_, _ = io.WriteString(w, "</label>\n    <textarea class=\"form-control font-monospace\" id=\"edit-args\" name=\"args\" rows=\"8\">")
+//line edit_job.ego:27
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(form.Args)))
+//line edit_job.ego:27
+		_, _ = io.WriteString(w, "</textarea>\n  </div>\n  <div class=\"mb-3\">\n    <label class=\"form-label\" for=\"edit-custom\">")
+//line edit_job.ego:30
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Custom"))))
+//line edit_job.ego:30
+		_, _ = io.WriteString(w, "</label>\n    <textarea class=\"form-control font-monospace\" id=\"edit-custom\" name=\"custom\" rows=\"6\">")
+//line edit_job.ego:31
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(form.Custom)))
+//line edit_job.ego:31
+		_, _ = io.WriteString(w, "</textarea>\n  </div>\n  <div class=\"row mb-3\">\n    <div class=\"col-auto\">\n      <label class=\"form-label\" for=\"edit-queue\">")
+//line edit_job.ego:35
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Queue"))))
+//line edit_job.ego:35
+		_, _ = io.WriteString(w, "</label>\n      <input class=\"form-control\" type=\"text\" id=\"edit-queue\" name=\"queue\" value=\"")
+//line edit_job.ego:36
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(form.Queue)))
+//line edit_job.ego:36
+		_, _ = io.WriteString(w, "\" />\n    </div>\n    <div class=\"col-auto\">\n      <label class=\"form-label\" for=\"edit-reserve-for\">")
+//line edit_job.ego:39
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "ReserveFor"))))
+//line edit_job.ego:39
+		_, _ = io.WriteString(w, "</label>\n      <input class=\"form-control\" type=\"number\" min=\"0\" max=\"86400\" id=\"edit-reserve-for\" name=\"reserve_for\" value=\"")
+//line edit_job.ego:40
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(form.ReserveFor)))
+//line edit_job.ego:40
+		_, _ = io.WriteString(w, "\" />\n    </div>\n  </div>\n  <div>\n    <a class=\"btn btn-default\" href=\"")
+//line edit_job.ego:44
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line edit_job.ego:44
+		_, _ = io.WriteString(w, "/")
+//line edit_job.ego:44
+		if set == "dead" {
+//line edit_job.ego:44
+			_, _ = io.WriteString(w, "morgue")
+//line edit_job.ego:44
+		} else {
+//line edit_job.ego:44
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(set)))
+//line edit_job.ego:44
+		}
+//line edit_job.ego:44
+		_, _ = io.WriteString(w, "/")
+//line edit_job.ego:44
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(key)))
+//line edit_job.ego:44
+		_, _ = io.WriteString(w, "\">")
+//line edit_job.ego:44
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "GoBack"))))
+//line edit_job.ego:44
+		_, _ = io.WriteString(w, "</a>\n    <button class=\"btn btn-primary\" type=\"submit\" name=\"mode\" value=\"push\" data-confirm=\"")
+//line edit_job.ego:45
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AreYouSure"))))
+//line edit_job.ego:45
+		_, _ = io.WriteString(w, "\">")
+//line edit_job.ego:45
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "SaveAndEnqueue"))))
+//line edit_job.ego:45
+		_, _ = io.WriteString(w, "</button>\n    <button class=\"btn btn-warn\" type=\"submit\" name=\"mode\" value=\"replace\">")
+//line edit_job.ego:46
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "SaveInPlace"))))
+//line edit_job.ego:46
+		_, _ = io.WriteString(w, "</button>\n  </div>\n</form>\n\n")
+//line edit_job.ego:50
+	})
+//line edit_job.ego:51
+	_, _ = io.WriteString(w, "\n")
+//line edit_job.ego:51
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
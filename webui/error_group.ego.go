@@ -0,0 +1,213 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line error_group.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+)
+
+func ego_errorGroup(w io.Writer, req *http.Request, eg *ErrorGroup, truncated bool) {
+
+//line error_group.ego:10
+	_, _ = io.WriteString(w, "\n\n")
+//line error_group.ego:11
+	ego_layout(w, req, func() {
+//line error_group.ego:12
+		_, _ = io.WriteString(w, "\n\n<header>\n  <h3>")
+//line error_group.ego:14
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Errors"))))
+//line error_group.ego:14
+		_, _ = io.WriteString(w, "</h3>\n</header>\n\n")
+//line error_group.ego:17
+		if truncated {
+//line error_group.ego:18
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-info\">")
+//line error_group.ego:18
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(fmt.Sprintf(t(req, "ErrorsTruncated"), ErrorScanLimit))))
+//line error_group.ego:18
+			_, _ = io.WriteString(w, "</div>\n")
+//line error_group.ego:19
+		}
+//line error_group.ego:20
+		_, _ = io.WriteString(w, "\n\n<div class=\"table-responsive\">\n  <table class=\"error table table-bordered table-striped table-light\">\n    <tbody>\n      <tr>\n        <th>")
+//line error_group.ego:25
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Job"))))
+//line error_group.ego:25
+		_, _ = io.WriteString(w, "</th>\n        <td><code>")
+//line error_group.ego:26
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Jobtype)))
+//line error_group.ego:26
+		_, _ = io.WriteString(w, "</code></td>\n      </tr>\n      <tr>\n        <th>")
+//line error_group.ego:29
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "ErrorClass"))))
+//line error_group.ego:29
+		_, _ = io.WriteString(w, "</th>\n        <td><code>")
+//line error_group.ego:30
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.ErrorType)))
+//line error_group.ego:30
+		_, _ = io.WriteString(w, "</code></td>\n      </tr>\n      <tr>\n        <th>")
+//line error_group.ego:33
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "ErrorMessage"))))
+//line error_group.ego:33
+		_, _ = io.WriteString(w, "</th>\n        <td>")
+//line error_group.ego:34
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Message)))
+//line error_group.ego:34
+		_, _ = io.WriteString(w, "</td>\n      </tr>\n      <tr>\n        <th>")
+//line error_group.ego:37
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Retries"))))
+//line error_group.ego:37
+		_, _ = io.WriteString(w, "</th>\n        <td>")
+//line error_group.ego:38
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Retries)))
+//line error_group.ego:38
+		_, _ = io.WriteString(w, "</td>\n      </tr>\n      <tr>\n        <th>")
+//line error_group.ego:41
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Dead"))))
+//line error_group.ego:41
+		_, _ = io.WriteString(w, "</th>\n        <td>")
+//line error_group.ego:42
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Dead)))
+//line error_group.ego:42
+		_, _ = io.WriteString(w, "</td>\n      </tr>\n      <tr>\n        <th>")
+//line error_group.ego:45
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "FirstSeen"))))
+//line error_group.ego:45
+		_, _ = io.WriteString(w, "</th>\n        <td>")
+//line error_group.ego:46
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(Timeago(eg.FirstSeen))))
+//line error_group.ego:46
+		_, _ = io.WriteString(w, "</td>\n      </tr>\n      <tr>\n        <th>")
+//line error_group.ego:49
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "LastSeen"))))
+//line error_group.ego:49
+		_, _ = io.WriteString(w, "</th>\n        <td>")
+//line error_group.ego:50
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(Timeago(eg.LastSeen))))
+//line error_group.ego:50
+		_, _ = io.WriteString(w, "</td>\n      </tr>\n    </tbody>\n  </table>\n</div>\n\n<form class=\"form-horizontal\" action=\"")
+//line error_group.ego:56
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line error_group.ego:56
+		_, _ = io.WriteString(w, "/errors/")
+//line error_group.ego:56
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.ID())))
+//line error_group.ego:56
+		_, _ = io.WriteString(w, "\" method=\"post\">\n  ")
+//line error_group.ego:57
+		_, _ = fmt.Fprint(w, csrfTag(req))
+//line error_group.ego:58
+		_, _ = io.WriteString(w, "\n  <div class=\"pull-left\">\n    <a class=\"btn btn-default\" href=\"")
+//line error_group.ego:59
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line error_group.ego:59
+		_, _ = io.WriteString(w, "/errors\">")
+//line error_group.ego:59
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "GoBack"))))
+//line error_group.ego:59
+		_, _ = io.WriteString(w, "</a>\n    <button class=\"btn btn-primary btn-sm\" type=\"submit\" name=\"action\" value=\"retry\" data-confirm=\"")
+//line error_group.ego:60
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AreYouSure"))))
+//line error_group.ego:60
+		_, _ = io.WriteString(w, "\">")
+//line error_group.ego:60
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "RetryAll"))))
+//line error_group.ego:60
+		_, _ = io.WriteString(w, "</button>\n    ")
+//line error_group.ego:61
+		if eg.Retries > 0 {
+//line error_group.ego:62
+			_, _ = io.WriteString(w, "\n      <button class=\"btn btn-danger btn-sm\" type=\"submit\" name=\"action\" value=\"kill\" data-confirm=\"")
+//line error_group.ego:62
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AreYouSure"))))
+//line error_group.ego:62
+			_, _ = io.WriteString(w, "\">")
+//line error_group.ego:62
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "KillAll"))))
+//line error_group.ego:62
+			_, _ = io.WriteString(w, "</button>\n    ")
+//line error_group.ego:63
+		}
+//line error_group.ego:64
+		_, _ = io.WriteString(w, "\n    <button class=\"btn btn-danger btn-sm\" type=\"submit\" name=\"action\" value=\"delete\" data-confirm=\"")
+//line error_group.ego:64
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AreYouSure"))))
+//line error_group.ego:64
+		_, _ = io.WriteString(w, "\">")
+//line error_group.ego:64
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "DeleteAll"))))
+//line error_group.ego:64
+		_, _ = io.WriteString(w, "</button>\n  </div>\n</form>\n\n<h4>")
+//line error_group.ego:68
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Samples"))))
+//line error_group.ego:68
+		_, _ = io.WriteString(w, "</h4>\n")
+//line error_group.ego:69
+		for _, sample := range eg.Samples {
+//line error_group.ego:70
+			_, _ = io.WriteString(w, "\n  <div class=\"table-responsive\">\n    <table class=\"error table table-bordered table-striped table-light\">\n      <tbody>\n        <tr>\n          <th>JID</th>\n          <td><a href=\"")
+//line error_group.ego:75
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line error_group.ego:75
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(sample.Path())))
+//line error_group.ego:75
+			_, _ = io.WriteString(w, "\"><code>")
+//line error_group.ego:75
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(sample.Job.Jid)))
+//line error_group.ego:75
+			_, _ = io.WriteString(w, "</code></a></td>\n        </tr>\n        <tr>\n          <th>")
+//line error_group.ego:78
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "ErrorMessage"))))
+//line error_group.ego:78
+			_, _ = io.WriteString(w, "</th>\n          <td>")
+//line error_group.ego:79
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(sample.Job.Failure.ErrorMessage)))
+//line error_group.ego:79
+			_, _ = io.WriteString(w, "</td>\n        </tr>\n        ")
+//line error_group.ego:81
+			if sample.Job.Failure.Backtrace != nil {
+//line error_group.ego:82
+				_, _ = io.WriteString(w, "\n          <tr>\n            <th>")
+//line error_group.ego:83
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "ErrorBacktrace"))))
+//line error_group.ego:83
+				_, _ = io.WriteString(w, "</th>\n            <td>\n              <code>\n                ")
+//line error_group.ego:86
+				for _, line := range sample.Job.Failure.Backtrace {
+//line error_group.ego:87
+					_, _ = io.WriteString(w, "\n                  ")
+//line error_group.ego:87
+					_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(line)))
+//line error_group.ego:87
+					_, _ = io.WriteString(w, "<br/>\n                ")
+//line error_group.ego:88
+				}
+//line error_group.ego:89
+				_, _ = io.WriteString(w, "\n              </code>\n            </td>\n          </tr>\n        ")
+//line error_group.ego:92
+			}
+//line error_group.ego:93
+			_, _ = io.WriteString(w, "\n      </tbody>\n    </table>\n  </div>\n")
+//line error_group.ego:96
+		}
+//line error_group.ego:97
+		_, _ = io.WriteString(w, "\n")
+//line error_group.ego:97
+	})
+//line error_group.ego:98
+	_, _ = io.WriteString(w, "\n")
+//line error_group.ego:98
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
@@ -0,0 +1,135 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line errors.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+)
+
+func ego_errors(w io.Writer, req *http.Request, groups []*ErrorGroup, truncated bool) {
+
+//line errors.ego:10
+	_, _ = io.WriteString(w, "\n\n")
+//line errors.ego:11
+	ego_layout(w, req, func() {
+//line errors.ego:12
+		_, _ = io.WriteString(w, "\n\n<header class=\"row\">\n  <div class=\"col-5\">\n    <h3>")
+//line errors.ego:15
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Errors"))))
+//line errors.ego:15
+		_, _ = io.WriteString(w, "</h3>\n  </div>\n</header>\n\n")
+//line errors.ego:19
+		if truncated {
+//line errors.ego:20
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-info\">")
+//line errors.ego:20
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(fmt.Sprintf(t(req, "ErrorsTruncated"), ErrorScanLimit))))
+//line errors.ego:20
+			_, _ = io.WriteString(w, "</div>\n")
+//line errors.ego:21
+		}
+//line errors.ego:22
+		_, _ = io.WriteString(w, "\n\n")
+//line errors.ego:23
+		if len(groups) > 0 {
+//line errors.ego:24
+			_, _ = io.WriteString(w, "\n  <div class=\"table-responsive\">\n    <table class=\"table table-striped table-bordered table-light\">\n      <thead>\n        <tr>\n          <th>")
+//line errors.ego:28
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Job"))))
+//line errors.ego:28
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line errors.ego:29
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Error"))))
+//line errors.ego:29
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line errors.ego:30
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Retries"))))
+//line errors.ego:30
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line errors.ego:31
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Dead"))))
+//line errors.ego:31
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line errors.ego:32
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "FirstSeen"))))
+//line errors.ego:32
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line errors.ego:33
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "LastSeen"))))
+//line errors.ego:33
+			_, _ = io.WriteString(w, "</th>\n        </tr>\n      </thead>\n      ")
+//line errors.ego:36
+			for _, eg := range groups {
+//line errors.ego:37
+				_, _ = io.WriteString(w, "\n        <tr>\n          <td><code>")
+//line errors.ego:38
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Jobtype)))
+//line errors.ego:38
+				_, _ = io.WriteString(w, "</code></td>\n          <td>\n            <a href=\"")
+//line errors.ego:40
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line errors.ego:40
+				_, _ = io.WriteString(w, "/errors/")
+//line errors.ego:40
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.ID())))
+//line errors.ego:40
+				_, _ = io.WriteString(w, "\">")
+//line errors.ego:40
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.ErrorType)))
+//line errors.ego:40
+				_, _ = io.WriteString(w, ": ")
+//line errors.ego:40
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Message)))
+//line errors.ego:40
+				_, _ = io.WriteString(w, "</a>\n          </td>\n          <td>")
+//line errors.ego:42
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Retries)))
+//line errors.ego:42
+				_, _ = io.WriteString(w, "</td>\n          <td>")
+//line errors.ego:43
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(eg.Dead)))
+//line errors.ego:43
+				_, _ = io.WriteString(w, "</td>\n          <td>")
+//line errors.ego:44
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(Timeago(eg.FirstSeen))))
+//line errors.ego:44
+				_, _ = io.WriteString(w, "</td>\n          <td>")
+//line errors.ego:45
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(Timeago(eg.LastSeen))))
+//line errors.ego:45
+				_, _ = io.WriteString(w, "</td>\n        </tr>\n      ")
+//line errors.ego:47
+			}
+//line errors.ego:48
+			_, _ = io.WriteString(w, "\n    </table>\n  </div>\n")
+//line errors.ego:50
+		} else {
+//line errors.ego:51
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-success\">")
+//line errors.ego:51
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "NoErrorsFound"))))
+//line errors.ego:51
+			_, _ = io.WriteString(w, "</div>\n")
+//line errors.ego:52
+		}
+//line errors.ego:53
+		_, _ = io.WriteString(w, "\n")
+//line errors.ego:53
+	})
+//line errors.ego:54
+	_, _ = io.WriteString(w, "\n")
+//line errors.ego:54
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
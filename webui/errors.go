@@ -0,0 +1,211 @@
+package webui
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+const (
+	// Keep this many example failures for each error group
+	ErrorSamples = 3
+	// Group no more than this many of the latest jobs in each set so
+	// the Errors page stays fast with a huge Retries or Dead set
+	ErrorScanLimit = 10000
+)
+
+var (
+	// Error messages often embed IDs, counts or addresses which would
+	// put every failure in its own group.  Each of these is replaced
+	// with "?" before grouping, in this order.
+	messageNormalizers = []*regexp.Regexp{
+		regexp.MustCompile(`"[^"]*"|\B'[^']*'\B`),
+		regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		regexp.MustCompile(`(?i)\b(0x[0-9a-f]+|[0-9a-f]{8,})\b`),
+		regexp.MustCompile(`\d+(\.\d+)?`),
+	}
+)
+
+// normalizeMessage replaces the variable parts of an error message
+// so similar failures are grouped together.
+func normalizeMessage(msg string) string {
+	for _, re := range messageNormalizers {
+		msg = re.ReplaceAllString(msg, "?")
+	}
+	return msg
+}
+
+// An ErrorSample is one failed job within an ErrorGroup.
+type ErrorSample struct {
+	Set string
+	Key string
+	Job *client.Job
+}
+
+// Path is the Web UI page for the job.
+func (es ErrorSample) Path() string {
+	if es.Set == "dead" {
+		return "/morgue/" + es.Key
+	}
+	return "/" + es.Set + "/" + es.Key
+}
+
+// An ErrorGroup collects the retries and dead jobs with the same
+// jobtype, error type and normalized error message.
+type ErrorGroup struct {
+	Jobtype   string
+	ErrorType string
+	Message   string
+	Retries   uint64
+	Dead      uint64
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Samples   []ErrorSample
+
+	// jids of the jobs in each set
+	jids map[string][]string
+}
+
+func (eg *ErrorGroup) ID() string {
+	sum := sha256.Sum256([]byte(eg.Jobtype + "\x00" + eg.ErrorType + "\x00" + eg.Message))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (eg *ErrorGroup) Count() uint64 {
+	return eg.Retries + eg.Dead
+}
+
+func (eg *ErrorGroup) add(set string, key string, job *client.Job) {
+	switch set {
+	case "retries":
+		eg.Retries++
+	case "dead":
+		eg.Dead++
+	}
+	eg.jids[set] = append(eg.jids[set], job.Jid)
+
+	if tm, err := util.ParseTime(job.Failure.FailedAt); err == nil {
+		if eg.FirstSeen.IsZero() || tm.Before(eg.FirstSeen) {
+			eg.FirstSeen = tm
+		}
+		if tm.After(eg.LastSeen) {
+			eg.LastSeen = tm
+		}
+	}
+	if len(eg.Samples) < ErrorSamples {
+		eg.Samples = append(eg.Samples, ErrorSample{Set: set, Key: key, Job: job})
+	}
+}
+
+// groupErrors scans the latest limit jobs of the Retries and Dead sets
+// and groups them by failure, largest group first.  truncated is true
+// if either set holds more jobs than that.
+func groupErrors(ctx context.Context, store storage.Store, limit int) (result []*ErrorGroup, truncated bool, err error) {
+	groups := map[string]*ErrorGroup{}
+
+	for _, set := range []storage.SortedSet{store.Retries(), store.Dead()} {
+		if set.Size(ctx) > uint64(limit) { // nolint:gosec
+			truncated = true
+		}
+		_, err := set.Page(ctx, -limit, limit, func(_ int, entry storage.SortedEntry) error {
+			job, err := entry.Job()
+			if err != nil || job.Failure == nil {
+				return nil
+			}
+			key, err := entry.Key()
+			if err != nil {
+				return nil
+			}
+
+			eg := &ErrorGroup{
+				Jobtype:   job.Type,
+				ErrorType: job.Failure.ErrorType,
+				Message:   normalizeMessage(job.Failure.ErrorMessage),
+			}
+			id := eg.ID()
+			if existing, ok := groups[id]; ok {
+				eg = existing
+			} else {
+				eg.jids = map[string][]string{}
+				groups[id] = eg
+			}
+			eg.add(set.Name(), string(key), job)
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	result = make([]*ErrorGroup, 0, len(groups))
+	for _, eg := range groups {
+		result = append(result, eg)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count() == result[j].Count() {
+			return result[i].LastSeen.After(result[j].LastSeen)
+		}
+		return result[i].Count() > result[j].Count()
+	})
+	return result, truncated, nil
+}
+
+func findErrorGroup(ctx context.Context, store storage.Store, id string) (*ErrorGroup, bool, error) {
+	groups, truncated, err := groupErrors(ctx, store, ErrorScanLimit)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, eg := range groups {
+		if eg.ID() == id {
+			return eg, truncated, nil
+		}
+	}
+	return nil, truncated, nil
+}
+
+// actOnErrors applies the action to every job in the group with
+// MUTATE operations filtered by jid.  Retrying or deleting a group
+// applies to both Retries and Dead, killing only to Retries.
+func actOnErrors(c context.Context, ns *server.Namespace, eg *ErrorGroup, action string) (map[client.Structure]uint64, error) {
+	cmd := ""
+	targets := []client.Structure{client.Retries, client.Dead}
+	switch action {
+	case "retry":
+		cmd = "requeue"
+	case "kill":
+		cmd = "kill"
+		targets = []client.Structure{client.Retries}
+	case "delete":
+		cmd = "discard"
+	default:
+		return nil, fmt.Errorf("invalid action: %v", action)
+	}
+
+	counts := map[client.Structure]uint64{}
+	for _, target := range targets {
+		jids := eg.jids[string(target)]
+		if len(jids) == 0 {
+			continue
+		}
+		op := client.Operation{
+			Cmd:    cmd,
+			Target: target,
+			Filter: &client.JobFilter{Jids: jids},
+		}
+		count, err := ns.Mutate(c, op)
+		counts[target] = count
+		if err != nil {
+			return counts, err
+		}
+	}
+	return counts, nil
+}
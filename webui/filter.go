@@ -0,0 +1,220 @@
+package webui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+// A JobFilter narrows the jobs shown on the Retries, Scheduled, Dead
+// and queue pages.  Empty fields match every job.  Age is measured from
+// when the job was created.
+type JobFilter struct {
+	Jobtype   string
+	Regexp    string
+	ErrorType string
+	MinAge    time.Duration
+	MaxAge    time.Duration
+
+	pattern *regexp.Regexp
+}
+
+// parseFilter reads the filter from the request's query or form values
+// and returns nil if no filter was given.
+func parseFilter(req *http.Request) (*JobFilter, error) {
+	f := &JobFilter{
+		Jobtype:   req.FormValue("jobtype"),
+		Regexp:    req.FormValue("regexp"),
+		ErrorType: req.FormValue("errtype"),
+	}
+
+	var err error
+	if f.Regexp != "" {
+		f.pattern, err = regexp.Compile(f.Regexp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp: %w", err)
+		}
+	}
+	if val := req.FormValue("min_age"); val != "" {
+		f.MinAge, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min_age: %w", err)
+		}
+	}
+	if val := req.FormValue("max_age"); val != "" {
+		f.MaxAge, err = time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_age: %w", err)
+		}
+	}
+
+	if f.Jobtype == "" && f.Regexp == "" && f.ErrorType == "" && f.MinAge == 0 && f.MaxAge == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// currentFilter returns the request's filter.  Handlers validate the
+// filter with parseFilter so errors are ignored here.
+func currentFilter(req *http.Request) *JobFilter {
+	f, _ := parseFilter(req)
+	return f
+}
+
+func (f *JobFilter) Values() url.Values {
+	values := url.Values{}
+	if f == nil {
+		return values
+	}
+	if f.Jobtype != "" {
+		values.Set("jobtype", f.Jobtype)
+	}
+	if f.Regexp != "" {
+		values.Set("regexp", f.Regexp)
+	}
+	if f.ErrorType != "" {
+		values.Set("errtype", f.ErrorType)
+	}
+	if f.MinAge != 0 {
+		values.Set("min_age", f.MinAge.String())
+	}
+	if f.MaxAge != 0 {
+		values.Set("max_age", f.MaxAge.String())
+	}
+	return values
+}
+
+// Match returns true if the job, with the given raw payload,
+// passes the filter.
+func (f *JobFilter) Match(job *client.Job, data []byte) bool {
+	if f == nil {
+		return true
+	}
+	if f.Jobtype != "" && f.Jobtype != job.Type && f.Jobtype != displayJobType(job) {
+		return false
+	}
+	if f.ErrorType != "" && (job.Failure == nil || job.Failure.ErrorType != f.ErrorType) {
+		return false
+	}
+	if f.pattern != nil && !f.pattern.Match(data) {
+		return false
+	}
+	if f.MinAge != 0 || f.MaxAge != 0 {
+		created, err := util.ParseTime(job.CreatedAt)
+		if err != nil {
+			return false
+		}
+		age := time.Since(created)
+		if f.MinAge != 0 && age < f.MinAge {
+			return false
+		}
+		if f.MaxAge != 0 && age > f.MaxAge {
+			return false
+		}
+	}
+	return true
+}
+
+// eachMatchingEntry calls fn for each entry in the set which
+// matches the filter, stopping early if fn returns false.
+func eachMatchingEntry(c context.Context, set storage.SortedSet, f *JobFilter, fn func(entry storage.SortedEntry, job *client.Job) bool) error {
+	done := fmt.Errorf("done")
+	err := set.Each(c, func(_ int, entry storage.SortedEntry) error {
+		job, err := entry.Job()
+		if err != nil {
+			return nil
+		}
+		if f.Match(job, entry.Value()) && !fn(entry, job) {
+			return done
+		}
+		return nil
+	})
+	if err == done {
+		return nil
+	}
+	return err
+}
+
+// eachMatchingJob calls fn for each job in the queue which
+// matches the filter, stopping early if fn returns false.
+func eachMatchingJob(c context.Context, q storage.Queue, f *JobFilter, fn func(data []byte, job *client.Job) bool) error {
+	done := fmt.Errorf("done")
+	err := q.Each(c, func(_ int, data []byte) error {
+		var job client.Job
+		if err := util.JsonUnmarshal(data, &job); err != nil {
+			return nil
+		}
+		if f.Match(&job, data) && !fn(data, &job) {
+			return done
+		}
+		return nil
+	})
+	if err == done {
+		return nil
+	}
+	return err
+}
+
+// setSize is the number of jobs shown for the set, after filtering.
+func setSize(req *http.Request, set storage.SortedSet) uint64 {
+	f := currentFilter(req)
+	if f == nil {
+		return set.Size(req.Context())
+	}
+	count := uint64(0)
+	err := eachMatchingEntry(req.Context(), set, f, func(storage.SortedEntry, *client.Job) bool {
+		count++
+		return true
+	})
+	if err != nil {
+		util.Error("Error filtering sorted set", err)
+	}
+	return count
+}
+
+// queueSize is the number of jobs shown for the queue, after filtering.
+func queueSize(req *http.Request, q storage.Queue) uint64 {
+	f := currentFilter(req)
+	if f == nil {
+		return q.Size(req.Context())
+	}
+	count := uint64(0)
+	err := eachMatchingJob(req.Context(), q, f, func([]byte, *client.Job) bool {
+		count++
+		return true
+	})
+	if err != nil {
+		util.Error("Error filtering queue", err)
+	}
+	return count
+}
+
+func durationValue(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func bulkActionLabel(action string) string {
+	switch action {
+	case "retry":
+		return "RetryNow"
+	case "add_to_queue":
+		return "AddToQueue"
+	case "delete":
+		return "Delete"
+	case "kill":
+		return "Kill"
+	case "move":
+		return "MoveToQueue"
+	}
+	return action
+}
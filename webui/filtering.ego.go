@@ -0,0 +1,179 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line filtering.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+)
+
+func ego_filtering(w io.Writer, req *http.Request, path string, actions []string) {
+	f := currentFilter(req)
+	if f == nil {
+		f = &JobFilter{}
+	}
+
+//line filtering.ego:14
+	_, _ = io.WriteString(w, "\n\n<div class=\"col-12 filter\">\n  <form action=\"")
+//line filtering.ego:16
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, path))))
+//line filtering.ego:16
+	_, _ = io.WriteString(w, "\" method=\"get\" class=\"row g-2 align-items-end\">\n    <div class=\"col-auto\">\n      <label class=\"form-label\" for=\"filter-jobtype\">")
+//line filtering.ego:18
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Jobtype"))))
+//line filtering.ego:18
+	_, _ = io.WriteString(w, "</label>\n      <input class=\"form-control form-control-sm\" type=\"text\" id=\"filter-jobtype\" name=\"jobtype\" value=\"")
+//line filtering.ego:19
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(f.Jobtype)))
+//line filtering.ego:19
+	_, _ = io.WriteString(w, "\" />\n    </div>\n    <div class=\"col-auto\">\n      <label class=\"form-label\" for=\"filter-regexp\">")
+//line filtering.ego:22
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Regexp"))))
+//line filtering.ego:22
+	_, _ = io.WriteString(w, "</label>\n      <input class=\"form-control form-control-sm\" type=\"text\" id=\"filter-regexp\" name=\"regexp\" value=\"")
+//line filtering.ego:23
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(f.Regexp)))
+//line filtering.ego:23
+	_, _ = io.WriteString(w, "\" />\n    </div>\n    <div class=\"col-auto\">\n      <label class=\"form-label\" for=\"filter-errtype\">")
+//line filtering.ego:26
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "ErrorClass"))))
+//line filtering.ego:26
+	_, _ = io.WriteString(w, "</label>\n      <input class=\"form-control form-control-sm\" type=\"text\" id=\"filter-errtype\" name=\"errtype\" value=\"")
+//line filtering.ego:27
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(f.ErrorType)))
+//line filtering.ego:27
+	_, _ = io.WriteString(w, "\" />\n    </div>\n    <div class=\"col-auto\">\n      <label class=\"form-label\" for=\"filter-min-age\">")
+//line filtering.ego:30
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "MinAge"))))
+//line filtering.ego:30
+	_, _ = io.WriteString(w, "</label>\n      <input class=\"form-control form-control-sm\" type=\"text\" id=\"filter-min-age\" name=\"min_age\" placeholder=\"1h\" value=\"")
+//line filtering.ego:31
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(durationValue(f.MinAge))))
+//line filtering.ego:31
+	_, _ = io.WriteString(w, "\" />\n    </div>\n    <div class=\"col-auto\">\n      <label class=\"form-label\" for=\"filter-max-age\">")
+//line filtering.ego:34
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "MaxAge"))))
+//line filtering.ego:34
+	_, _ = io.WriteString(w, "</label>\n      <input class=\"form-control form-control-sm\" type=\"text\" id=\"filter-max-age\" name=\"max_age\" placeholder=\"72h\" value=\"")
+//line filtering.ego:35
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(durationValue(f.MaxAge))))
+//line filtering.ego:35
+	_, _ = io.WriteString(w, "\" />\n    </div>\n    <div class=\"col-auto\">\n      <button class=\"btn btn-primary btn-sm\" type=\"submit\">")
+//line filtering.ego:38
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Filter"))))
+//line filtering.ego:38
+	_, _ = io.WriteString(w, "</button>\n      ")
+//line filtering.ego:39
+	if !unfiltered(req) {
+//line filtering.ego:40
+		_, _ = io.WriteString(w, "\n        <a class=\"btn btn-secondary btn-sm\" href=\"")
+//line filtering.ego:40
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, path))))
+//line filtering.ego:40
+		_, _ = io.WriteString(w, "\">")
+//line filtering.ego:40
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "ClearFilter"))))
+//line filtering.ego:40
+		_, _ = io.WriteString(w, "</a>\n      ")
+//line filtering.ego:41
+	}
+//line filtering.ego:42
+	_, _ = io.WriteString(w, "\n    </div>\n  </form>\n\n  ")
+//line filtering.ego:45
+	if !unfiltered(req) {
+//line filtering.ego:46
+		_, _ = io.WriteString(w, "\n    <form action=\"")
+//line filtering.ego:46
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, path))))
+//line filtering.ego:46
+		_, _ = io.WriteString(w, "\" method=\"post\" class=\"row g-2 align-items-end mt-1\">\n      ")
+//line filtering.ego:47
+		_, _ = fmt.Fprint(w, csrfTag(req))
+//line filtering.ego:48
+		_, _ = io.WriteString(w, "\n      <input type=\"hidden\" name=\"scope\" value=\"filtered\" />\n      ")
+//line filtering.ego:49
+		for key, vals := range f.Values() {
+//line filtering.ego:50
+			_, _ = io.WriteString(w, "\n        <input type=\"hidden\" name=\"")
+//line filtering.ego:50
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(key)))
+//line filtering.ego:50
+			_, _ = io.WriteString(w, "\" value=\"")
+//line filtering.ego:50
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(vals[0])))
+//line filtering.ego:50
+			_, _ = io.WriteString(w, "\" />\n      ")
+//line filtering.ego:51
+		}
+//line filtering.ego:52
+		_, _ = io.WriteString(w, "\n      <div class=\"col-auto\">\n        <strong>")
+//line filtering.ego:53
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AllMatching"))))
+//line filtering.ego:53
+		_, _ = io.WriteString(w, "</strong>\n      </div>\n      <div class=\"col-auto\">\n        ")
+//line filtering.ego:56
+		for _, action := range actions {
+//line filtering.ego:57
+			_, _ = io.WriteString(w, "\n          ")
+//line filtering.ego:57
+			if action != "move" {
+//line filtering.ego:58
+				_, _ = io.WriteString(w, "\n            <button class=\"btn btn-danger btn-sm\" type=\"submit\" name=\"action\" value=\"")
+//line filtering.ego:58
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(action)))
+//line filtering.ego:58
+				_, _ = io.WriteString(w, "\" data-confirm=\"")
+//line filtering.ego:58
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AreYouSure"))))
+//line filtering.ego:58
+				_, _ = io.WriteString(w, "\">")
+//line filtering.ego:58
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, bulkActionLabel(action)))))
+//line filtering.ego:58
+				_, _ = io.WriteString(w, "</button>\n          ")
+//line filtering.ego:59
+			}
+//line filtering.ego:60
+			_, _ = io.WriteString(w, "\n        ")
+//line filtering.ego:60
+		}
+//line filtering.ego:61
+		_, _ = io.WriteString(w, "\n      </div>\n      ")
+//line filtering.ego:62
+		if validAction(actions, "move") {
+//line filtering.ego:63
+			_, _ = io.WriteString(w, "\n        <div class=\"col-auto\">\n          <input class=\"form-control form-control-sm\" type=\"text\" name=\"queue\" placeholder=\"")
+//line filtering.ego:64
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Queue"))))
+//line filtering.ego:64
+			_, _ = io.WriteString(w, "\" />\n        </div>\n        <div class=\"col-auto\">\n          <button class=\"btn btn-warn btn-sm\" type=\"submit\" name=\"action\" value=\"move\" data-confirm=\"")
+//line filtering.ego:67
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "AreYouSure"))))
+//line filtering.ego:67
+			_, _ = io.WriteString(w, "\">")
+//line filtering.ego:67
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "MoveToQueue"))))
+//line filtering.ego:67
+			_, _ = io.WriteString(w, "</button>\n        </div>\n      ")
+//line filtering.ego:69
+		}
+//line filtering.ego:70
+		_, _ = io.WriteString(w, "\n    </form>\n  ")
+//line filtering.ego:71
+	}
+//line filtering.ego:72
+	_, _ = io.WriteString(w, "\n</div>\n")
+//line filtering.ego:73
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
@@ -66,12 +66,16 @@ func t(req *http.Request, word string) string {
 	return dc.Translation(word)
 }
 
-func pageparam(_ *http.Request, pageValue uint64) string {
-	return fmt.Sprintf("page=%d", pageValue)
+// pageparam builds the query string for a page of results,
+// keeping the current filter.
+func pageparam(req *http.Request, pageValue uint64) string {
+	values := currentFilter(req).Values()
+	values.Set("page", strconv.FormatUint(pageValue, 10))
+	return values.Encode()
 }
 
 func currentStatus(req *http.Request) string {
-	if ctx(req).Server().Manager().WorkingCount() == 0 {
+	if ctx(req).Manager().WorkingCount() == 0 {
 		return "idle"
 	}
 	return "active"
@@ -106,6 +110,23 @@ func queues(req *http.Request) []Queue {
 	return queues
 }
 
+// tabs returns the navigation tabs the user may see.
+func tabs(req *http.Request) []Tab {
+	if ctx(req).Scoped() {
+		visible := []Tab{}
+		for _, tab := range DefaultTabs {
+			if tab.Path != "/audit" {
+				visible = append(visible, tab)
+			}
+		}
+		return visible
+	}
+	if len(ctx(req).Server().Namespaces()) > 1 {
+		return append(append([]Tab{}, DefaultTabs...), Tab{"Namespaces", "/namespaces"})
+	}
+	return DefaultTabs
+}
+
 func ctx(req *http.Request) *DefaultContext {
 	return req.Context().(*DefaultContext)
 }
@@ -137,6 +158,22 @@ func uintWithDelimiter(val uint64) string {
 
 func queueJobs(r *http.Request, q storage.Queue, count, currentPage uint64, fn func(idx int, key []byte, job *client.Job)) {
 	c := r.Context()
+	if f := currentFilter(r); f != nil {
+		skip := (currentPage - 1) * count
+		idx := uint64(0)
+		err := eachMatchingJob(c, q, f, func(data []byte, job *client.Job) bool {
+			if idx >= skip {
+				fn(int(idx-skip), data, job) // nolint:gosec
+			}
+			idx++
+			return idx < skip+count
+		})
+		if err != nil {
+			util.Warnf("Error iterating queue: %s", err.Error())
+		}
+		return
+	}
+
 	err := q.Page(c, int64((currentPage-1)*count), int64(count), func(idx int, data []byte) error { // nolint:gosec
 		var job client.Job
 		err := util.JsonUnmarshal(data, &job)
@@ -169,16 +206,31 @@ func relativeTime(moment string) string {
 	return Timeago(tm)
 }
 
-func unfiltered() bool {
-	return true
-}
-
-func filtering(_ string) string {
-	return ""
+func unfiltered(req *http.Request) bool {
+	return currentFilter(req) == nil
 }
 
 func setJobs(req *http.Request, set storage.SortedSet, count, currentPage uint64, fn func(idx int, key []byte, job *client.Job)) {
 	c := req.Context()
+	if f := currentFilter(req); f != nil {
+		skip := (currentPage - 1) * count
+		idx := uint64(0)
+		err := eachMatchingEntry(c, set, f, func(entry storage.SortedEntry, job *client.Job) bool {
+			if idx >= skip {
+				key, err := entry.Key()
+				if err == nil {
+					fn(int(idx-skip), key, job) // nolint:gosec
+				}
+			}
+			idx++
+			return idx < skip+count
+		})
+		if err != nil {
+			util.Error("Error iterating sorted set", err)
+		}
+		return
+	}
+
 	_, err := set.Page(c, int((currentPage-1)*count), int(count), func(idx int, entry storage.SortedEntry) error { // nolint:gosec
 		job, err := entry.Job()
 		if err != nil {
@@ -216,11 +268,12 @@ func busyReservations(req *http.Request, fn func(worker *manager.Reservation)) {
 
 func busyWorkers(req *http.Request, fn func(proc *server.ClientData)) {
 	hb := ctx(req).Server().Heartbeats()
-	wids := make([]string, len(hb))
-	idx := 0
-	for wid := range hb {
-		wids[idx] = wid
-		idx++
+	ns := ctx(req).Namespace().Name
+	wids := make([]string, 0, len(hb))
+	for wid, proc := range hb {
+		if proc.Namespace == ns {
+			wids = append(wids, wid)
+		}
 	}
 	sort.Strings(wids)
 	for idx := range wids {
@@ -229,6 +282,18 @@ func busyWorkers(req *http.Request, fn func(proc *server.ClientData)) {
 }
 
 func actOn(req *http.Request, set storage.SortedSet, action string, keys []string) error {
+	count := uint64(len(keys))
+	if len(keys) == 1 && keys[0] == "all" {
+		count = set.Size(req.Context())
+	}
+	err := applyAction(req, set, action, keys)
+	if err == nil {
+		audit(req, action, set.Name(), count)
+	}
+	return err
+}
+
+func applyAction(req *http.Request, set storage.SortedSet, action string, keys []string) error {
 	c := req.Context()
 	switch action {
 	case "delete":
@@ -260,16 +325,18 @@ func actOn(req *http.Request, set storage.SortedSet, action string, keys []strin
 		if len(keys) == 1 && keys[0] == "all" {
 			return ctx(req).Store().EnqueueAll(c, set)
 		} else {
-			// TODO Make this 180 day dead job expiry dynamic per-job or
-			// a global variable in TOML? PRs welcome.
-			expiry := time.Now().Add(180 * 24 * time.Hour)
+			mgr := ctx(req).Manager()
 			for idx := range keys {
 				entry, err := set.Get(c, []byte(keys[idx]))
 				if err != nil {
 					return err
 				}
 				if entry != nil {
-					err = set.MoveTo(c, ctx(req).Store().Dead(), entry, expiry)
+					job, err := entry.Job()
+					if err != nil {
+						return err
+					}
+					err = set.MoveTo(c, ctx(req).Store().Dead(), entry, mgr.DeadExpiry(job))
 					if err != nil {
 						return err
 					}
@@ -282,6 +349,142 @@ func actOn(req *http.Request, set storage.SortedSet, action string, keys []strin
 	}
 }
 
+// audit records an action taken by the Web UI user, identified by their
+// Basic Auth username if they gave one.
+func audit(req *http.Request, command string, target string, count uint64) {
+	ctx(req).Server().Audit(req.Context(), auditEntry(req, command, target, count))
+}
+
+func auditEntry(req *http.Request, command string, target string, count uint64) storage.AuditEntry {
+	actor := "web"
+	if user, _, ok := req.BasicAuth(); ok && user != "" {
+		actor = "web:" + user
+	}
+	if ns := ctx(req).Namespace(); ns.Name != "" {
+		target = strings.TrimSuffix(ns.Name+"/"+target, "/")
+	}
+	return storage.AuditEntry{
+		Actor:   actor,
+		Remote:  req.RemoteAddr,
+		Command: command,
+		Target:  target,
+		Count:   count,
+	}
+}
+
+func auditLog(req *http.Request, count, currentPage uint64) ([]storage.AuditEntry, error) {
+	start := int64((currentPage - 1) * count)                                     // nolint:gosec
+	return ctx(req).Server().Store().AuditLog(req.Context(), start, int64(count)) // nolint:gosec
+}
+
+type CompletedQueue struct {
+	Name string
+	Size uint64
+}
+
+func completedQueues(req *http.Request) []CompletedQueue {
+	c := req.Context()
+	store := ctx(req).Store()
+	names, err := store.CompletedQueues(c)
+	if err != nil {
+		util.Error("Unable to list completed queues", err)
+		return nil
+	}
+
+	cqs := make([]CompletedQueue, len(names))
+	for idx := range names {
+		cqs[idx] = CompletedQueue{names[idx], store.Completed(names[idx]).Size(c)}
+	}
+	return cqs
+}
+
+func completedJob(entry storage.SortedEntry) (*manager.CompletedJob, error) {
+	var cj manager.CompletedJob
+	err := util.JsonUnmarshal(entry.Value(), &cj)
+	if err != nil {
+		return nil, err
+	}
+	return &cj, nil
+}
+
+func completedPage(req *http.Request, set storage.SortedSet, query string, count, currentPage uint64) []*manager.CompletedJob {
+	if query != "" {
+		return searchSet(req, set, query, int(count)) // nolint:gosec
+	}
+
+	c := req.Context()
+	jobs := make([]*manager.CompletedJob, 0, count)
+	_, err := set.Page(c, int((currentPage-1)*count), int(count), func(idx int, entry storage.SortedEntry) error { // nolint:gosec
+		cj, err := completedJob(entry)
+		if err != nil {
+			util.Warnf("Error parsing JSON: %s", string(entry.Value()))
+			return err
+		}
+		jobs = append(jobs, cj)
+		return nil
+	})
+	if err != nil {
+		util.Error("Error iterating completed jobs", err)
+	}
+	return jobs
+}
+
+var errEnough = fmt.Errorf("enough")
+
+// searchSet returns up to limit entries whose payload contains
+// the query string, e.g. a JID, jobtype or argument value.
+func searchSet(req *http.Request, set storage.SortedSet, query string, limit int) []*manager.CompletedJob {
+	c := req.Context()
+	jobs := []*manager.CompletedJob{}
+	err := set.Find(c, "*"+globEscape(query)+"*", func(idx int, entry storage.SortedEntry) error {
+		cj, err := completedJob(entry)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, cj)
+		if len(jobs) >= limit {
+			return errEnough
+		}
+		return nil
+	})
+	if err != nil && err != errEnough {
+		util.Error("Error searching completed jobs", err)
+	}
+	return jobs
+}
+
+func searchCompleted(req *http.Request, cqs []CompletedQueue, query string, limit int) []*manager.CompletedJob {
+	jobs := []*manager.CompletedJob{}
+	for _, cq := range cqs {
+		jobs = append(jobs, searchSet(req, ctx(req).Store().Completed(cq.Name), query, limit-len(jobs))...)
+		if len(jobs) >= limit {
+			break
+		}
+	}
+	return jobs
+}
+
+// Redis's MATCH uses glob-style patterns, escape any
+// special characters in user input.
+func globEscape(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func displayDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
 func uptimeInDays(req *http.Request) string {
 	return fmt.Sprintf("%.0f", time.Since(ctx(req).Server().Stats.StartedAt).Seconds()/float64(86400))
 }
@@ -312,6 +515,9 @@ func redis_info(req *http.Request) (string, float64) {
 	c := req.Context()
 	store := ctx(req).Store().(storage.Redis)
 	redis := store.Redis()
+	if redis == nil {
+		return "Not backed by Redis", 0
+	}
 	a := time.Now().UnixNano()
 	res := redis.Info(c)
 	b := time.Now().UnixNano()
@@ -437,6 +643,45 @@ func displayJobType(j *client.Job) string {
 	return j.Type
 }
 
+// visibleJob returns the job with its args decrypted if the user may
+// see them.  It returns false for an encrypted job the user may not see.
+func visibleJob(req *http.Request, job *client.Job) (*client.Job, bool) {
+	c := ctx(req)
+	if !c.Server().Encrypted(job) {
+		return job, true
+	}
+	if !c.CanDecrypt() {
+		return job, false
+	}
+	plain, err := c.Server().Decrypt(job)
+	if err != nil {
+		util.Warnf("Unable to decrypt %s: %v", job.Jid, err)
+		return job, false
+	}
+	return plain, true
+}
+
+func displayJobArgs(req *http.Request, job *client.Job) string {
+	job, ok := visibleJob(req, job)
+	if !ok {
+		return "[encrypted]"
+	}
+	return displayArgs(job.Args)
+}
+
+func displayFullJobArgs(req *http.Request, job *client.Job) string {
+	job, ok := visibleJob(req, job)
+	if !ok {
+		return "[encrypted]"
+	}
+	return displayFullArgs(job.Args)
+}
+
+func displayCustom(req *http.Request, job *client.Job) map[string]any {
+	job, _ = visibleJob(req, job)
+	return job.Custom
+}
+
 func displayArgs(args []any) string {
 	return displayLimitedArgs(args, 1024)
 }
@@ -472,3 +717,69 @@ func displayLimitedArgs(args []any, limit int) string {
 	}
 	return b.String()
 }
+
+type NamedMetrics struct {
+	Name string
+	*storage.ExecutionMetrics
+}
+
+func metricsMinutes(req *http.Request) int {
+	retention := int(ctx(req).Manager().MetricsRetention() / time.Minute)
+	cnt, err := strconv.Atoi(req.URL.Query().Get("minutes"))
+	if err != nil || cnt <= 0 {
+		cnt = 60
+	}
+	if cnt > retention {
+		return retention
+	}
+	return cnt
+}
+
+func metricsMatches(req *http.Request, value string, defalt bool) string {
+	minutes := req.URL.Query().Get("minutes")
+	if minutes == value || (minutes == "" && defalt) {
+		return "active"
+	}
+	return ""
+}
+
+func executionHistory(req *http.Request, minutes int) ([]*storage.MinuteMetrics, error) {
+	history := []*storage.MinuteMetrics{}
+	since := time.Now().Add(-time.Duration(minutes-1) * time.Minute)
+	err := ctx(req).Store().ExecutionHistory(req.Context(), since, func(mm *storage.MinuteMetrics) {
+		history = append(history, mm)
+	})
+	return history, err
+}
+
+func sortedMetrics(metrics map[string]*storage.ExecutionMetrics) []NamedMetrics {
+	result := make([]NamedMetrics, 0, len(metrics))
+	for name, em := range metrics {
+		result = append(result, NamedMetrics{name, em})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Processed != result[j].Processed {
+			return result[i].Processed > result[j].Processed
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// executionSeries renders the per-minute totals as JSON for the
+// metrics chart: [[unix time, processed, failed], ...]
+func executionSeries(history []*storage.MinuteMetrics) string {
+	series := make([][3]uint64, len(history))
+	for idx, mm := range history {
+		series[idx][0] = uint64(mm.Minute.Unix()) // nolint:gosec
+		for _, em := range mm.Queues {
+			series[idx][1] += em.Processed
+			series[idx][2] += em.Failed
+		}
+	}
+	str, err := json.Marshal(series)
+	if err != nil {
+		return "[]"
+	}
+	return string(str)
+}
@@ -1,11 +1,27 @@
 package webui
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/faktorytest"
+	"github.com/contribsys/faktory/manager"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
 	"github.com/contribsys/faktory/util"
+	"github.com/stretchr/testify/assert"
 )
 
 type testJob struct {
@@ -139,3 +155,375 @@ func TestActiveJobUnwrapping(t *testing.T) {
 		})
 	}
 }
+
+func TestGlobEscape(t *testing.T) {
+	if globEscape("abc") != "abc" {
+		t.Errorf("Expected abc, got %s", globEscape("abc"))
+	}
+	if globEscape(`a*b?[c]`) != `a\*b\?\[c\]` {
+		t.Errorf("Unexpected escape: %s", globEscape(`a*b?[c]`))
+	}
+}
+
+func TestNormalizeMessage(t *testing.T) {
+	cases := map[string]string{
+		"connection refused":                                      "connection refused",
+		"Timeout after 30.5 seconds":                              "Timeout after ? seconds",
+		"User 12345 not found":                                    "User ? not found",
+		`Couldn't find Order with 'id'="abc"`:                     "Couldn't find Order with ?=?",
+		"record 0b1e4d3c-9f0a-4c2e-8a5b-0123456789ab missing":     "record ? missing",
+		"bad pointer 0xc000123abc in deadbeefcafe":                "bad pointer ? in ?",
+		"dial tcp 10.0.0.12:5432: connect: connection refused":    "dial tcp ?.?:?: connect: connection refused",
+		"HTTP 503 from https://api.example.com/v1/orders/981?x=7": "HTTP ? from https://api.example.com/v?/orders/??x=?",
+	}
+	for msg, expected := range cases {
+		if result := normalizeMessage(msg); result != expected {
+			t.Errorf("normalizeMessage(%q): expected %q, got %q", msg, expected, result)
+		}
+	}
+}
+
+func TestJobFilter(t *testing.T) {
+	req := httptest.NewRequest("GET", "/retries", nil)
+	f, err := parseFilter(req)
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+	assert.True(t, f.Match(client.NewJob("AnyJob"), nil))
+
+	_, err = parseFilter(httptest.NewRequest("GET", "/retries?regexp=%28", nil))
+	assert.Error(t, err)
+	_, err = parseFilter(httptest.NewRequest("GET", "/retries?min_age=soon", nil))
+	assert.Error(t, err)
+
+	req = httptest.NewRequest("GET", "/retries?jobtype=SomeJob&errtype=RuntimeError&regexp=user_%5Cd%2B&max_age=1h", nil)
+	f, err = parseFilter(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "errtype=RuntimeError&jobtype=SomeJob&max_age=1h0m0s&regexp=user_%5Cd%2B", f.Values().Encode())
+
+	job := client.NewJob("SomeJob", "user_42")
+	job.Failure = &client.Failure{ErrorType: "RuntimeError"}
+	data := []byte(`{"args":["user_42"]}`)
+	assert.True(t, f.Match(job, data))
+	assert.False(t, f.Match(job, []byte(`{"args":["user_x"]}`)))
+
+	job.Type = "OtherJob"
+	assert.False(t, f.Match(job, data))
+	job.Type = "SomeJob"
+
+	job.Failure.ErrorType = "ArgumentError"
+	assert.False(t, f.Match(job, data))
+	job.Failure.ErrorType = "RuntimeError"
+
+	job.CreatedAt = util.Thens(time.Now().Add(-2 * time.Hour))
+	assert.False(t, f.Match(job, data))
+
+	assert.Equal(t, "errtype=RuntimeError&jobtype=SomeJob&max_age=1h0m0s&page=2&regexp=user_%5Cd%2B", pageparam(req, 2))
+}
+
+func TestJobFormApply(t *testing.T) {
+	job := client.NewJob("InvoiceJob", "cust_12O", 5)
+	job.ReserveFor = 600
+
+	jf := newJobForm(job)
+	assert.Equal(t, "600", jf.ReserveFor)
+	assert.Equal(t, "", jf.Custom)
+
+	jf.Args = `["cust_120", 5]`
+	jf.Custom = `{"tenant": "acme"}`
+	jf.Queue = "critical"
+	edited, err := jf.Apply(job)
+	assert.NoError(t, err)
+	assert.Equal(t, job.Jid, edited.Jid)
+	assert.Equal(t, "critical", edited.Queue)
+	assert.Equal(t, 600, edited.ReserveFor)
+	assert.Equal(t, "cust_120", edited.Args[0])
+	assert.Equal(t, "cust_12O", job.Args[0])
+	assert.Equal(t, "default", job.Queue)
+
+	tenant, ok := edited.GetCustom("tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "acme", tenant)
+	from, ok := edited.GetCustom("edited_from")
+	assert.True(t, ok)
+	assert.Equal(t, job.Jid, from.(map[string]any)["jid"])
+	assert.Equal(t, job.Args, from.(map[string]any)["args"])
+
+	bad := []JobForm{
+		{Args: `{"not": "an array"}`, Queue: "default"},
+		{Args: `null`, Queue: "default"},
+		{Args: `[1]`, Custom: `[1]`, Queue: "default"},
+		{Args: `[1]`, Queue: "bad queue!"},
+		{Args: `[1]`, Queue: "default", ReserveFor: "-1"},
+		{Args: `[1]`, Queue: "default", ReserveFor: "100000"},
+	}
+	for _, jf := range bad {
+		_, err := jf.Apply(job)
+		assert.Error(t, err, "%+v", jf)
+	}
+}
+
+func TestEncryptedJobs(t *testing.T) {
+	srv := faktorytest.Start(t)
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	keyFile := filepath.Join(t.TempDir(), "keys")
+	assert.NoError(t, os.WriteFile(keyFile, []byte("k1:"+base64.StdEncoding.EncodeToString(key)), 0o600))
+	srv.Options.GlobalConfig = map[string]any{
+		"encryption": map[string]any{"key_file": keyFile},
+	}
+	srv.Reload()
+
+	cl := srv.Client()
+	assert.NoError(t, cl.Push(client.NewJob("SendEmail", "mike@example.com")))
+	job := client.NewJob("SendEmail", "mike@example.com")
+	job.At = util.Thens(time.Now().Add(time.Hour))
+	assert.NoError(t, cl.Push(job))
+
+	var entryKey string
+	assert.NoError(t, srv.Store().Scheduled().Each(context.Background(), func(_ int, e storage.SortedEntry) error {
+		data, err := e.Key()
+		entryKey = string(data)
+		return err
+	}))
+
+	ui := newWeb(srv.Server, Options{Password: "password", DecryptPassword: "decrypt"})
+	get := func(path string, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "http://localhost:7420"+path, nil)
+		req.SetBasicAuth("admin", password)
+		w := httptest.NewRecorder()
+		ui.App.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/queues/default", "password")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "[encrypted]")
+	assert.NotContains(t, w.Body.String(), "mike@example.com")
+
+	w = get("/queues/default", "decrypt")
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), "[encrypted]")
+	assert.Contains(t, w.Body.String(), "mike@example.com")
+
+	w = get("/queues/default", "wrong")
+	assert.Equal(t, 401, w.Code)
+
+	w = get("/edit/scheduled/"+url.QueryEscape(entryKey), "password")
+	assert.Equal(t, 403, w.Code)
+	w = get("/edit/scheduled/"+url.QueryEscape(entryKey), "decrypt")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "mike@example.com")
+
+	// the edited job is encrypted again
+	form := url.Values{"args": {`["bob@example.com"]`}, "queue": {"default"}, "mode": {"replace"}}
+	req := httptest.NewRequest("POST", "http://localhost:7420/edit/scheduled/"+url.QueryEscape(entryKey), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("admin", "decrypt")
+	w = httptest.NewRecorder()
+	ui.App.ServeHTTP(w, req)
+	assert.Equal(t, 302, w.Code)
+
+	jobs := srv.JobsOfType("SendEmail")
+	assert.Len(t, jobs, 2)
+	for _, job := range jobs {
+		assert.True(t, srv.Encrypted(job))
+		data, err := json.Marshal(job)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "@example.com")
+	}
+}
+
+func TestOffloadedJobEdit(t *testing.T) {
+	srv := faktorytest.Start(t)
+	srv.Options.GlobalConfig = map[string]any{
+		"payloads": map[string]any{"offload_size": int64(100)},
+	}
+	srv.Reload()
+	srv.Manager().AddMiddleware("push", func(ctx context.Context, next func() error) error {
+		mh := ctx.Value(manager.MiddlewareHelperKey).(manager.Context)
+		if len(mh.Job().Args) > 0 && mh.Job().Args[0] == "invalid" {
+			return fmt.Errorf("INVALID first arg")
+		}
+		return next()
+	})
+
+	big := strings.Repeat("x", 200)
+	job := client.NewJob("Report", big)
+	job.At = util.Thens(time.Now().Add(time.Hour))
+	assert.NoError(t, srv.Client().Push(job))
+
+	entryKey := func() string {
+		var key string
+		assert.NoError(t, srv.Store().Scheduled().Each(context.Background(), func(_ int, e storage.SortedEntry) error {
+			data, err := e.Key()
+			key = string(data)
+			return err
+		}))
+		return key
+	}
+	ui := newWeb(srv.Server, Options{})
+	send := func(method string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "http://localhost:7420/edit/scheduled/"+url.QueryEscape(entryKey()), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		ui.App.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), big)
+	assert.NotContains(t, w.Body.String(), "faktory.args")
+
+	// the push middleware sees the edited job
+	w = send("POST", url.Values{"args": {`["invalid"]`}, "queue": {"default"}, "mode": {"replace"}})
+	assert.Equal(t, 500, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID first arg")
+
+	// the edited args are offloaded again
+	edited := strings.Repeat("y", 200)
+	w = send("POST", url.Values{"args": {`["` + edited + `"]`}, "queue": {"default"}, "mode": {"replace"}})
+	assert.Equal(t, 302, w.Code)
+	jobs := srv.JobsOfType("Report")
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, []any{}, jobs[0].Args)
+	assert.True(t, srv.Offloaded(jobs[0]))
+	assert.Equal(t, []any{edited}, srv.Rehydrate(jobs[0]).Args)
+
+	w = send("POST", url.Values{"args": {`["` + big + `", 2]`}, "queue": {"default"}, "mode": {"push"}})
+	assert.Equal(t, 302, w.Code)
+	fetched, err := srv.Client().Fetch("default")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{big, float64(2)}, fetched.Args)
+	_, ok := fetched.GetCustom("faktory.args")
+	assert.False(t, ok)
+}
+
+func TestNamespacedLogin(t *testing.T) {
+	dir := t.TempDir()
+	s, err := server.NewServer(&server.ServerOptions{
+		GlobalConfig: map[string]any{
+			"namespaces": map[string]any{
+				"billing": map[string]any{"password": "billpass", "database": int64(1)},
+			},
+		},
+		Binding:          "localhost:0",
+		StorageDirectory: dir,
+		ConfigDirectory:  dir,
+		PoolSize:         server.DefaultMaxPoolSize,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.BootWithStore(storage.NewMemoryStore()))
+	defer s.Stop(nil)
+
+	bg := context.Background()
+	assert.NoError(t, s.Manager().Push(bg, client.NewJob("DefaultJob", 1)))
+	assert.NoError(t, s.Namespace("billing").Manager().Push(bg, client.NewJob("BillingJob", 2)))
+
+	ui := newWeb(s, Options{Password: "password"})
+	get := func(path, username, password string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "http://localhost:7420"+path, nil)
+		req.SetBasicAuth(username, password)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		ui.App.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/queues/default", "billing", "billpass")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "BillingJob")
+	assert.NotContains(t, w.Body.String(), "DefaultJob")
+	assert.NotContains(t, w.Body.String(), `href="/audit"`)
+
+	assert.Equal(t, 401, get("/queues/default", "billing", "wrong").Code)
+	assert.Equal(t, 401, get("/queues/default", "search", "billpass").Code)
+	assert.Equal(t, 403, get("/audit", "billing", "billpass").Code)
+	assert.Equal(t, 403, get("/namespaces", "billing", "billpass").Code)
+	// a scoped user can't switch namespaces with the cookie
+	cookie := &http.Cookie{Name: "faktory_namespace", Value: ""}
+	assert.NotContains(t, get("/queues/default", "billing", "billpass", cookie).Body.String(), "DefaultJob")
+
+	w = get("/queues/default", "admin", "password")
+	assert.Contains(t, w.Body.String(), "DefaultJob")
+	assert.NotContains(t, w.Body.String(), "BillingJob")
+
+	w = get("/namespaces", "admin", "password")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "billing")
+
+	req := httptest.NewRequest("POST", "http://localhost:7420/namespaces", strings.NewReader("namespace=billing"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("admin", "password")
+	w = httptest.NewRecorder()
+	ui.App.ServeHTTP(w, req)
+	assert.Equal(t, 302, w.Code)
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 1)
+
+	w = get("/queues/default", "admin", "password", cookies...)
+	assert.Contains(t, w.Body.String(), "BillingJob")
+	assert.NotContains(t, w.Body.String(), "DefaultJob")
+}
+
+func TestBulkOperationSteps(t *testing.T) {
+	srv := faktorytest.Start(t)
+	ns := srv.Namespace("")
+	store := ns.Store()
+	bg := context.Background()
+
+	src, err := store.GetQueue(bg, "misrouted")
+	assert.NoError(t, err)
+	data := []byte(`{"jid":"abc","jobtype":"Foo","queue":"misrouted","args":[1],"future_attr":{"a":1}}`)
+	var job client.Job
+	assert.NoError(t, json.Unmarshal(data, &job))
+
+	t.Run("Move", func(t *testing.T) {
+		assert.NoError(t, src.Push(bg, data))
+		op := &BulkOperation{Action: "move", Queue: "correct"}
+		assert.NoError(t, applyToQueued(bg, ns, src, data, &job, op))
+		// fetched or moved already
+		assert.NoError(t, applyToQueued(bg, ns, src, data, &job, op))
+
+		moved := srv.Jobs("correct")
+		if assert.Len(t, moved, 1) {
+			assert.Equal(t, "correct", moved[0].Queue)
+		}
+		dst, _ := store.GetQueue(bg, "correct")
+		payload, err := dst.Pop(bg)
+		assert.NoError(t, err)
+		assert.Contains(t, string(payload), `"future_attr":{"a":1}`)
+	})
+
+	t.Run("KillFetched", func(t *testing.T) {
+		assert.NoError(t, src.Push(bg, data))
+		_, err := src.Pop(bg)
+		assert.NoError(t, err)
+		op := &BulkOperation{Action: "kill"}
+		assert.NoError(t, applyToQueued(bg, ns, src, data, &job, op))
+		assert.EqualValues(t, 0, store.Dead().Size(bg))
+	})
+
+	t.Run("MoveFromSet", func(t *testing.T) {
+		assert.NoError(t, store.Retries().AddElement(bg, util.Nows(), job.Jid, data))
+		var entry storage.SortedEntry
+		err := store.Retries().Each(bg, func(_ int, e storage.SortedEntry) error {
+			entry = e
+			return nil
+		})
+		assert.NoError(t, err)
+		op := &BulkOperation{Action: "move", Queue: "correct"}
+		assert.NoError(t, applyToEntry(bg, ns, store.Retries(), entry, &job, op))
+		assert.NoError(t, applyToEntry(bg, ns, store.Retries(), entry, &job, op))
+
+		dst, _ := store.GetQueue(bg, "correct")
+		assert.EqualValues(t, 1, dst.Size(bg))
+		payload, err := dst.Pop(bg)
+		assert.NoError(t, err)
+		assert.Contains(t, string(payload), `"future_attr":{"a":1}`)
+		assert.Contains(t, string(payload), `"queue":"correct"`)
+		assert.Contains(t, string(payload), `"enqueued_at"`)
+	})
+}
@@ -41,7 +41,7 @@ func ego_job_info(w io.Writer, req *http.Request, job *client.Job) {
 //line job_info.ego:33
 	_, _ = io.WriteString(w, "</th>\n        <td>\n          <code class=\"code-wrap\">\n            <!-- We don't want to truncate any job arguments when viewing a single job's status page -->\n            <div class=\"args-extended\">")
 //line job_info.ego:37
-	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayFullArgs(job.Args))))
+	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayFullJobArgs(req, job))))
 //line job_info.ego:37
 	_, _ = io.WriteString(w, "</div>\n          </code>\n        </td>\n      </tr>\n      <tr>\n        <th>")
 //line job_info.ego:42
@@ -110,7 +110,7 @@ func ego_job_info(w io.Writer, req *http.Request, job *client.Job) {
 //line job_info.ego:65
 	_, _ = io.WriteString(w, "\n        </td>\n      </tr>\n      ")
 //line job_info.ego:67
-	if job.Custom != nil {
+	if custom := displayCustom(req, job); custom != nil {
 //line job_info.ego:68
 		_, _ = io.WriteString(w, "\n        <tr>\n          <th>")
 //line job_info.ego:69
@@ -118,7 +118,7 @@ func ego_job_info(w io.Writer, req *http.Request, job *client.Job) {
 //line job_info.ego:69
 		_, _ = io.WriteString(w, "</th>\n          <td>\n            ")
 //line job_info.ego:71
-		for k, v := range job.Custom {
+		for k, v := range custom {
 //line job_info.ego:72
 			_, _ = io.WriteString(w, "\n              <code>")
 //line job_info.ego:72
@@ -0,0 +1,146 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line metrics.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+
+	"github.com/contribsys/faktory/storage"
+)
+
+func ego_metrics(w io.Writer, req *http.Request, minutes int, history []*storage.MinuteMetrics) {
+	queues, jobtypes := storage.SumExecutions(history)
+
+//line metrics.ego:13
+	_, _ = io.WriteString(w, "\n\n")
+//line metrics.ego:14
+	ego_layout(w, req, func() {
+//line metrics.ego:15
+		_, _ = io.WriteString(w, "\n<script type=\"text/javascript\" src=\"")
+//line metrics.ego:15
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, "/static/metrics.js"))))
+//line metrics.ego:15
+		_, _ = io.WriteString(w, "\"></script>\n\n<header class=\"row\">\n  <div class=\"col-5\">\n    <h3>")
+//line metrics.ego:19
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Metrics"))))
+//line metrics.ego:19
+		_, _ = io.WriteString(w, "</h3>\n  </div>\n  ")
+//line metrics.ego:21
+		if minutes > 0 {
+//line metrics.ego:22
+			_, _ = io.WriteString(w, "\n  <div class=\"col-7 d-flex justify-content-end\">\n    <a href=\"")
+//line metrics.ego:23
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, "/metrics?minutes=15"))))
+//line metrics.ego:23
+			_, _ = io.WriteString(w, "\" class=\"history-graph ")
+//line metrics.ego:23
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(metricsMatches(req, "15", false))))
+//line metrics.ego:23
+			_, _ = io.WriteString(w, "\">")
+//line metrics.ego:23
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "FifteenMinutes"))))
+//line metrics.ego:23
+			_, _ = io.WriteString(w, "</a>\n    <a href=\"")
+//line metrics.ego:24
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, "/metrics"))))
+//line metrics.ego:24
+			_, _ = io.WriteString(w, "\" class=\"history-graph ")
+//line metrics.ego:24
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(metricsMatches(req, "60", true))))
+//line metrics.ego:24
+			_, _ = io.WriteString(w, "\">")
+//line metrics.ego:24
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "OneHour"))))
+//line metrics.ego:24
+			_, _ = io.WriteString(w, "</a>\n    <a href=\"")
+//line metrics.ego:25
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, "/metrics?minutes=240"))))
+//line metrics.ego:25
+			_, _ = io.WriteString(w, "\" class=\"history-graph ")
+//line metrics.ego:25
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(metricsMatches(req, "240", false))))
+//line metrics.ego:25
+			_, _ = io.WriteString(w, "\">")
+//line metrics.ego:25
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "FourHours"))))
+//line metrics.ego:25
+			_, _ = io.WriteString(w, "</a>\n    <a href=\"")
+//line metrics.ego:26
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, "/metrics?minutes=1440"))))
+//line metrics.ego:26
+			_, _ = io.WriteString(w, "\" class=\"history-graph ")
+//line metrics.ego:26
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(metricsMatches(req, "1440", false))))
+//line metrics.ego:26
+			_, _ = io.WriteString(w, "\">")
+//line metrics.ego:26
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "OneDay"))))
+//line metrics.ego:26
+			_, _ = io.WriteString(w, "</a>\n  </div>\n  ")
+//line metrics.ego:28
+		}
+//line metrics.ego:29
+		_, _ = io.WriteString(w, "\n</header>\n\n")
+//line metrics.ego:31
+		if minutes == 0 {
+//line metrics.ego:32
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-info\">")
+//line metrics.ego:32
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "MetricsDisabled"))))
+//line metrics.ego:32
+			_, _ = io.WriteString(w, "</div>\n")
+//line metrics.ego:33
+		} else {
+//line metrics.ego:34
+			_, _ = io.WriteString(w, "\n  <div id=\"metrics-chart\" data-series=\"")
+//line metrics.ego:34
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(executionSeries(history))))
+//line metrics.ego:34
+			_, _ = io.WriteString(w, "\" data-processed-label=\"")
+//line metrics.ego:34
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Processed"))))
+//line metrics.ego:34
+			_, _ = io.WriteString(w, "\" data-failed-label=\"")
+//line metrics.ego:34
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Failed"))))
+//line metrics.ego:34
+			_, _ = io.WriteString(w, "\"></div>\n\n  <h4>")
+//line metrics.ego:36
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Jobtypes"))))
+//line metrics.ego:36
+			_, _ = io.WriteString(w, "</h4>\n  ")
+//line metrics.ego:37
+			ego_metricsTable(w, req, jobtypes)
+//line metrics.ego:38
+			_, _ = io.WriteString(w, "\n  <h4>")
+//line metrics.ego:38
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Queues"))))
+//line metrics.ego:38
+			_, _ = io.WriteString(w, "</h4>\n  ")
+//line metrics.ego:39
+			ego_metricsTable(w, req, queues)
+//line metrics.ego:40
+			_, _ = io.WriteString(w, "\n")
+//line metrics.ego:40
+		}
+//line metrics.ego:41
+		_, _ = io.WriteString(w, "\n")
+//line metrics.ego:41
+	})
+//line metrics.ego:42
+	_, _ = io.WriteString(w, "\n")
+//line metrics.ego:42
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
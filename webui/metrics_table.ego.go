@@ -0,0 +1,89 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line metrics_table.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+
+	"github.com/contribsys/faktory/storage"
+)
+
+func ego_metricsTable(w io.Writer, req *http.Request, metrics map[string]*storage.ExecutionMetrics) {
+
+//line metrics_table.ego:12
+	_, _ = io.WriteString(w, "\n")
+//line metrics_table.ego:12
+	if len(metrics) > 0 {
+//line metrics_table.ego:13
+		_, _ = io.WriteString(w, "\n<div class=\"table-responsive\">\n  <table class=\"table table-hover table-bordered table-striped table-light\">\n    <thead>\n      <th>")
+//line metrics_table.ego:16
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Name"))))
+//line metrics_table.ego:16
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line metrics_table.ego:17
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Processed"))))
+//line metrics_table.ego:17
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line metrics_table.ego:18
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Failed"))))
+//line metrics_table.ego:18
+		_, _ = io.WriteString(w, "</th>\n      <th>p50</th>\n      <th>p95</th>\n      <th>p99</th>\n    </thead>\n    ")
+//line metrics_table.ego:23
+		for _, nm := range sortedMetrics(metrics) {
+//line metrics_table.ego:24
+			_, _ = io.WriteString(w, "\n      <tr>\n        <td><code>")
+//line metrics_table.ego:25
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(nm.Name)))
+//line metrics_table.ego:25
+			_, _ = io.WriteString(w, "</code></td>\n        <td>")
+//line metrics_table.ego:26
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(nm.Processed))))
+//line metrics_table.ego:26
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line metrics_table.ego:27
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(nm.Failed))))
+//line metrics_table.ego:27
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line metrics_table.ego:28
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayDuration(nm.Percentile(0.50)))))
+//line metrics_table.ego:28
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line metrics_table.ego:29
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayDuration(nm.Percentile(0.95)))))
+//line metrics_table.ego:29
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line metrics_table.ego:30
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayDuration(nm.Percentile(0.99)))))
+//line metrics_table.ego:30
+			_, _ = io.WriteString(w, "</td>\n      </tr>\n    ")
+//line metrics_table.ego:32
+		}
+//line metrics_table.ego:33
+		_, _ = io.WriteString(w, "\n  </table>\n</div>\n")
+//line metrics_table.ego:35
+	} else {
+//line metrics_table.ego:36
+		_, _ = io.WriteString(w, "\n<div class=\"alert alert-success\">")
+//line metrics_table.ego:36
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "NoMetricsFound"))))
+//line metrics_table.ego:36
+		_, _ = io.WriteString(w, "</div>\n")
+//line metrics_table.ego:37
+	}
+//line metrics_table.ego:38
+	_, _ = io.WriteString(w, "\n")
+//line metrics_table.ego:38
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
@@ -18,7 +18,7 @@ import (
 )
 
 func ego_listDead(w io.Writer, req *http.Request, set storage.SortedSet, count, currentPage uint64) {
-	totalSize := uint64(set.Size(req.Context()))
+	totalSize := setSize(req, set)
 
 //line morgue.ego:14
 	_, _ = io.WriteString(w, "\n\n")
@@ -43,7 +43,7 @@ func ego_listDead(w io.Writer, req *http.Request, set storage.SortedSet, count,
 //line morgue.ego:26
 		_, _ = io.WriteString(w, "\n  ")
 //line morgue.ego:26
-		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(filtering("dead"))))
+		ego_filtering(w, req, "/morgue", setActions["dead"])
 //line morgue.ego:27
 		_, _ = io.WriteString(w, "\n</header>\n\n")
 //line morgue.ego:29
@@ -115,7 +115,7 @@ func ego_listDead(w io.Writer, req *http.Request, set storage.SortedSet, count,
 //line morgue.ego:61
 				_, _ = io.WriteString(w, "</code></td>\n            <td>\n              <div class=\"args\">")
 //line morgue.ego:63
-				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayArgs(job.Args))))
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayJobArgs(req, job))))
 //line morgue.ego:63
 				_, _ = io.WriteString(w, "</div>\n            </td>\n            <td>\n              ")
 //line morgue.ego:66
@@ -147,7 +147,7 @@ func ego_listDead(w io.Writer, req *http.Request, set storage.SortedSet, count,
 //line morgue.ego:76
 			_, _ = io.WriteString(w, "</button>\n    </div>\n  </form>\n\n  ")
 //line morgue.ego:80
-			if unfiltered() {
+			if unfiltered(req) {
 //line morgue.ego:81
 				_, _ = io.WriteString(w, "\n    <form action=\"")
 //line morgue.ego:81
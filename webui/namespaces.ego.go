@@ -0,0 +1,163 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line namespaces.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/server"
+)
+
+func ego_namespaces(w io.Writer, req *http.Request, namespaces []*server.Namespace, states []*client.FaktoryState) {
+
+//line namespaces.ego:13
+	_, _ = io.WriteString(w, "\n\n")
+//line namespaces.ego:14
+	ego_layout(w, req, func() {
+//line namespaces.ego:15
+		_, _ = io.WriteString(w, "\n\n<h3>")
+//line namespaces.ego:16
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Namespaces"))))
+//line namespaces.ego:16
+		_, _ = io.WriteString(w, "</h3>\n\n<div class=\"table-responsive\">\n  <table class=\"namespaces table table-hover table-bordered table-striped table-light\">\n    <thead>\n      <th>")
+//line namespaces.ego:21
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Namespace"))))
+//line namespaces.ego:21
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:22
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Database"))))
+//line namespaces.ego:22
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:23
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Enqueued"))))
+//line namespaces.ego:23
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:24
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Retries"))))
+//line namespaces.ego:24
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:25
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Scheduled"))))
+//line namespaces.ego:25
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:26
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Dead"))))
+//line namespaces.ego:26
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:27
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Processed"))))
+//line namespaces.ego:27
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:28
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Failed"))))
+//line namespaces.ego:28
+		_, _ = io.WriteString(w, "</th>\n      <th>")
+//line namespaces.ego:29
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Actions"))))
+//line namespaces.ego:29
+		_, _ = io.WriteString(w, "</th>\n    </thead>\n    ")
+//line namespaces.ego:31
+		for idx, ns := range namespaces {
+			data := states[idx].Data
+//line namespaces.ego:33
+			_, _ = io.WriteString(w, "\n      <tr>\n        <td>\n          ")
+//line namespaces.ego:35
+			if ns.Name == "" {
+//line namespaces.ego:36
+				_, _ = io.WriteString(w, "\n            ")
+//line namespaces.ego:36
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "DefaultNamespace"))))
+//line namespaces.ego:37
+				_, _ = io.WriteString(w, "\n          ")
+//line namespaces.ego:37
+			} else {
+//line namespaces.ego:38
+				_, _ = io.WriteString(w, "\n            ")
+//line namespaces.ego:38
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(ns.Name)))
+//line namespaces.ego:39
+				_, _ = io.WriteString(w, "\n          ")
+//line namespaces.ego:39
+			}
+//line namespaces.ego:40
+			_, _ = io.WriteString(w, "\n          ")
+//line namespaces.ego:40
+			if ns == ctx(req).Namespace() {
+//line namespaces.ego:41
+				_, _ = io.WriteString(w, "\n            <span class=\"badge bg-secondary\">")
+//line namespaces.ego:41
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Current"))))
+//line namespaces.ego:41
+				_, _ = io.WriteString(w, "</span>\n          ")
+//line namespaces.ego:42
+			}
+//line namespaces.ego:43
+			_, _ = io.WriteString(w, "\n        </td>\n        <td>")
+//line namespaces.ego:44
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(ns.Database)))
+//line namespaces.ego:44
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line namespaces.ego:45
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(data.TotalEnqueued))))
+//line namespaces.ego:45
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line namespaces.ego:46
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(data.Sets["retries"]))))
+//line namespaces.ego:46
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line namespaces.ego:47
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(data.Sets["scheduled"]))))
+//line namespaces.ego:47
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line namespaces.ego:48
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(data.Sets["dead"]))))
+//line namespaces.ego:48
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line namespaces.ego:49
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(data.TotalProcessed))))
+//line namespaces.ego:49
+			_, _ = io.WriteString(w, "</td>\n        <td>")
+//line namespaces.ego:50
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(data.TotalFailures))))
+//line namespaces.ego:50
+			_, _ = io.WriteString(w, "</td>\n        <td>\n          <form action=\"")
+//line namespaces.ego:52
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line namespaces.ego:52
+			_, _ = io.WriteString(w, "/namespaces\" method=\"post\">\n            ")
+//line namespaces.ego:53
+			_, _ = fmt.Fprint(w, csrfTag(req))
+//line namespaces.ego:54
+			_, _ = io.WriteString(w, "\n            <button class=\"btn btn-primary btn-sm\" type=\"submit\" name=\"namespace\" value=\"")
+//line namespaces.ego:54
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(ns.Name)))
+//line namespaces.ego:54
+			_, _ = io.WriteString(w, "\">")
+//line namespaces.ego:54
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "View"))))
+//line namespaces.ego:54
+			_, _ = io.WriteString(w, "</button>\n          </form>\n        </td>\n      </tr>\n    ")
+//line namespaces.ego:58
+		}
+//line namespaces.ego:59
+		_, _ = io.WriteString(w, "\n  </table>\n</div>\n\n  ")
+//line namespaces.ego:62
+	})
+//line namespaces.ego:63
+	_, _ = io.WriteString(w, "\n")
+//line namespaces.ego:63
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
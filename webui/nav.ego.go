@@ -46,49 +46,61 @@ func ego_nav(w io.Writer, req *http.Request) {
 //line nav.ego:24
 	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, x))))
 //line nav.ego:25
-	_, _ = io.WriteString(w, "\n      </span>\n\n      <button class=\"navbar-toggler ms-3\" type=\"button\" data-bs-toggle=\"collapse\" data-bs-target=\"#navbar-menu\" aria-controls=\"navbar-menu\" aria-expanded=\"false\" aria-label=\"Toggle navigation\">\n        <span class=\"navbar-toggler-icon\"></span>\n      </button>\n\n      <div class=\"collapse navbar-collapse justify-content-center\" id=\"navbar-menu\">\n        <ul class=\"navbar-nav\" data-navbar=\"static\">\n          ")
-//line nav.ego:33
-	for _, tab := range DefaultTabs {
+	_, _ = io.WriteString(w, "\n      </span>\n\n      ")
+//line nav.ego:27
+	if ns := ctx(req).Namespace().Name; ns != "" {
+//line nav.ego:28
+		_, _ = io.WriteString(w, "\n        <span class=\"badge bg-secondary ms-3 namespace\">")
+//line nav.ego:28
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(ns)))
+//line nav.ego:28
+		_, _ = io.WriteString(w, "</span>\n      ")
+//line nav.ego:29
+	}
+//line nav.ego:30
+	_, _ = io.WriteString(w, "\n\n      <button class=\"navbar-toggler ms-3\" type=\"button\" data-bs-toggle=\"collapse\" data-bs-target=\"#navbar-menu\" aria-controls=\"navbar-menu\" aria-expanded=\"false\" aria-label=\"Toggle navigation\">\n        <span class=\"navbar-toggler-icon\"></span>\n      </button>\n\n      <div class=\"collapse navbar-collapse justify-content-center\" id=\"navbar-menu\">\n        <ul class=\"navbar-nav\" data-navbar=\"static\">\n          ")
+//line nav.ego:37
+	for _, tab := range tabs(req) {
 		if tab.Path == "/" {
-//line nav.ego:35
+//line nav.ego:39
 			_, _ = io.WriteString(w, "\n              <li class=\"nav-item")
-//line nav.ego:35
+//line nav.ego:39
 			if req.RequestURI == "/" {
-//line nav.ego:35
+//line nav.ego:39
 				_, _ = io.WriteString(w, " active")
-//line nav.ego:35
+//line nav.ego:39
 			}
-//line nav.ego:35
+//line nav.ego:39
 			_, _ = io.WriteString(w, "\">\n            ")
-//line nav.ego:36
+//line nav.ego:40
 		} else {
-//line nav.ego:37
+//line nav.ego:41
 			_, _ = io.WriteString(w, "\n              <li class=\"nav-item")
-//line nav.ego:37
+//line nav.ego:41
 			if strings.HasPrefix(req.RequestURI, tab.Path) {
-//line nav.ego:37
+//line nav.ego:41
 				_, _ = io.WriteString(w, " active")
-//line nav.ego:37
+//line nav.ego:41
 			}
-//line nav.ego:37
+//line nav.ego:41
 			_, _ = io.WriteString(w, "\">\n            ")
-//line nav.ego:38
+//line nav.ego:42
 		}
-//line nav.ego:39
+//line nav.ego:43
 		_, _ = io.WriteString(w, "\n              <a class=\"nav-link p-3\" href=\"")
-//line nav.ego:39
+//line nav.ego:43
 		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, tab.Path))))
-//line nav.ego:39
+//line nav.ego:43
 		_, _ = io.WriteString(w, "\">")
-//line nav.ego:39
+//line nav.ego:43
 		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, tab.Name))))
-//line nav.ego:39
+//line nav.ego:43
 		_, _ = io.WriteString(w, "</a>\n            </li>\n          ")
-//line nav.ego:41
+//line nav.ego:45
 	}
-//line nav.ego:42
+//line nav.ego:46
 	_, _ = io.WriteString(w, "\n        </ul>\n      </div>\n    </div>\n  </div>\n</div>\n")
-//line nav.ego:47
+//line nav.ego:51
 }
 
 var _ fmt.Stringer
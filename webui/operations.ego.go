@@ -0,0 +1,175 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line operations.ego:1
+
+package webui
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+import (
+	"net/http"
+)
+
+func ego_operations(w io.Writer, req *http.Request, ops []*BulkOperation) {
+
+//line operations.ego:10
+	_, _ = io.WriteString(w, "\n\n")
+//line operations.ego:11
+	ego_layout(w, req, func() {
+//line operations.ego:12
+		_, _ = io.WriteString(w, "\n\n<header class=\"row\">\n  <div class=\"col-5\">\n    <h3>")
+//line operations.ego:15
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "BulkOperations"))))
+//line operations.ego:15
+		_, _ = io.WriteString(w, "</h3>\n  </div>\n</header>\n\n")
+//line operations.ego:19
+		if len(ops) > 0 {
+//line operations.ego:20
+			_, _ = io.WriteString(w, "\n  <div class=\"table-responsive\" id=\"operations\" data-running=\"")
+//line operations.ego:20
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(ctx(req).webui.operations.Running())))
+//line operations.ego:20
+			_, _ = io.WriteString(w, "\">\n    <table class=\"table table-striped table-bordered table-light\">\n      <thead>\n        <tr>\n          <th>")
+//line operations.ego:24
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Started"))))
+//line operations.ego:24
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line operations.ego:25
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Action"))))
+//line operations.ego:25
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line operations.ego:26
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Target"))))
+//line operations.ego:26
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line operations.ego:27
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Filter"))))
+//line operations.ego:27
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line operations.ego:28
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Progress"))))
+//line operations.ego:28
+			_, _ = io.WriteString(w, "</th>\n          <th>")
+//line operations.ego:29
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Status"))))
+//line operations.ego:29
+			_, _ = io.WriteString(w, "</th>\n        </tr>\n      </thead>\n      ")
+//line operations.ego:32
+			for _, op := range ops {
+//line operations.ego:33
+				_, _ = io.WriteString(w, "\n        <tr>\n          <td>")
+//line operations.ego:34
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(Timeago(op.StartedAt))))
+//line operations.ego:34
+				_, _ = io.WriteString(w, "</td>\n          <td>\n            ")
+//line operations.ego:36
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, bulkActionLabel(op.Action)))))
+//line operations.ego:37
+				_, _ = io.WriteString(w, "\n            ")
+//line operations.ego:37
+				if op.Action == "move" {
+//line operations.ego:38
+					_, _ = io.WriteString(w, "\n              <a href=\"")
+//line operations.ego:38
+					_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line operations.ego:38
+					_, _ = io.WriteString(w, "/queues/")
+//line operations.ego:38
+					_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(op.Queue)))
+//line operations.ego:38
+					_, _ = io.WriteString(w, "\">")
+//line operations.ego:38
+					_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(op.Queue)))
+//line operations.ego:38
+					_, _ = io.WriteString(w, "</a>\n            ")
+//line operations.ego:39
+				}
+//line operations.ego:40
+				_, _ = io.WriteString(w, "\n          </td>\n          <td>")
+//line operations.ego:41
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(op.Target)))
+//line operations.ego:41
+				_, _ = io.WriteString(w, "</td>\n          <td><code>")
+//line operations.ego:42
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(op.Filter.Values().Encode())))
+//line operations.ego:42
+				_, _ = io.WriteString(w, "</code></td>\n          <td>\n            <div class=\"progress\">\n              <div class=\"progress-bar\" role=\"progressbar\" style=\"width: ")
+//line operations.ego:45
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(op.Percent())))
+//line operations.ego:45
+				_, _ = io.WriteString(w, "%\" aria-valuenow=\"")
+//line operations.ego:45
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(op.Percent())))
+//line operations.ego:45
+				_, _ = io.WriteString(w, "\" aria-valuemin=\"0\" aria-valuemax=\"100\"></div>\n            </div>\n            ")
+//line operations.ego:47
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(op.Processed()))))
+//line operations.ego:47
+				_, _ = io.WriteString(w, " / ")
+//line operations.ego:47
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(uintWithDelimiter(op.Total()))))
+//line operations.ego:48
+				_, _ = io.WriteString(w, "\n          </td>\n          <td>\n            ")
+//line operations.ego:50
+				if err := op.Err(); err != nil {
+//line operations.ego:51
+					_, _ = io.WriteString(w, "\n              <span class=\"text-danger\">")
+//line operations.ego:51
+					_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(err.Error())))
+//line operations.ego:51
+					_, _ = io.WriteString(w, "</span>\n            ")
+//line operations.ego:52
+				} else if op.Done() {
+//line operations.ego:53
+					_, _ = io.WriteString(w, "\n              ")
+//line operations.ego:53
+					_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Finished"))))
+//line operations.ego:54
+					_, _ = io.WriteString(w, "\n            ")
+//line operations.ego:54
+				} else {
+//line operations.ego:55
+					_, _ = io.WriteString(w, "\n              ")
+//line operations.ego:55
+					_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Running"))))
+//line operations.ego:56
+					_, _ = io.WriteString(w, "\n            ")
+//line operations.ego:56
+				}
+//line operations.ego:57
+				_, _ = io.WriteString(w, "\n          </td>\n        </tr>\n      ")
+//line operations.ego:59
+			}
+//line operations.ego:60
+			_, _ = io.WriteString(w, "\n    </table>\n  </div>\n")
+//line operations.ego:62
+		} else {
+//line operations.ego:63
+			_, _ = io.WriteString(w, "\n  <div class=\"alert alert-success\">")
+//line operations.ego:63
+			_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "NoOperationsFound"))))
+//line operations.ego:63
+			_, _ = io.WriteString(w, "</div>\n")
+//line operations.ego:64
+		}
+//line operations.ego:65
+		_, _ = io.WriteString(w, "\n<script type=\"text/javascript\" src=\"")
+//line operations.ego:65
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, "/static/operations.js"))))
+//line operations.ego:65
+		_, _ = io.WriteString(w, "\"></script>\n")
+//line operations.ego:66
+	})
+//line operations.ego:67
+	_, _ = io.WriteString(w, "\n")
+//line operations.ego:67
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
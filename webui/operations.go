@@ -0,0 +1,298 @@
+package webui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/server"
+	"github.com/contribsys/faktory/storage"
+	"github.com/contribsys/faktory/util"
+)
+
+const (
+	// Remember this many bulk operations for the Operations page
+	MaxOperations = 20
+)
+
+// A BulkOperation applies an action to every job matching a filter.
+// Filtered sets can hold many thousands of jobs so the operation runs
+// in the background and reports its progress.
+type BulkOperation struct {
+	ID     string
+	Action string
+	// Target is "retries", "scheduled", "dead" or a queue name
+	Target string
+	// Queue is the destination queue for the "move" action
+	Queue string
+	// Namespace is empty for the default namespace
+	Namespace string
+	Filter    *JobFilter
+	StartedAt time.Time
+
+	total     atomic.Uint64
+	processed atomic.Uint64
+
+	mu         sync.Mutex
+	finishedAt time.Time
+	err        error
+}
+
+func (op *BulkOperation) Total() uint64 {
+	return op.total.Load()
+}
+
+func (op *BulkOperation) Processed() uint64 {
+	return op.processed.Load()
+}
+
+func (op *BulkOperation) Percent() uint64 {
+	total := op.Total()
+	if total == 0 {
+		if op.Done() {
+			return 100
+		}
+		return 0
+	}
+	return op.Processed() * 100 / total
+}
+
+func (op *BulkOperation) Done() bool {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	return !op.finishedAt.IsZero()
+}
+
+func (op *BulkOperation) Err() error {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	return op.err
+}
+
+func (op *BulkOperation) finish(err error) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	op.finishedAt = time.Now()
+	op.err = err
+}
+
+type operations struct {
+	mu   sync.Mutex
+	list []*BulkOperation
+}
+
+func (ops *operations) add(op *BulkOperation) {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	ops.list = append([]*BulkOperation{op}, ops.list...)
+	if len(ops.list) > MaxOperations {
+		ops.list = ops.list[:MaxOperations]
+	}
+}
+
+// All returns the operations, newest first.
+func (ops *operations) All() []*BulkOperation {
+	ops.mu.Lock()
+	defer ops.mu.Unlock()
+	return append([]*BulkOperation{}, ops.list...)
+}
+
+// In returns the operations on the given namespace, newest first.
+func (ops *operations) In(namespace string) []*BulkOperation {
+	list := []*BulkOperation{}
+	for _, op := range ops.All() {
+		if op.Namespace == namespace {
+			list = append(list, op)
+		}
+	}
+	return list
+}
+
+func (ops *operations) Running() bool {
+	for _, op := range ops.All() {
+		if !op.Done() {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	setActions = map[string][]string{
+		"retries":   {"retry", "delete", "kill", "move"},
+		"scheduled": {"add_to_queue", "delete", "kill", "move"},
+		"dead":      {"retry", "delete", "move"},
+	}
+	queueActions = []string{"delete", "kill", "move"}
+)
+
+func validAction(actions []string, action string) bool {
+	for _, valid := range actions {
+		if valid == action {
+			return true
+		}
+	}
+	return false
+}
+
+// startOperation validates the filtered bulk action in the request and
+// starts it in the background with the given function, which must
+// return the matching jobs and the function to apply to each one.
+func startOperation(req *http.Request, target string, actions []string, collect func(c context.Context, op *BulkOperation) ([]func(context.Context) error, error)) (*BulkOperation, error) {
+	f, err := parseFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("bulk actions require a filter")
+	}
+
+	op := &BulkOperation{
+		ID:        util.RandomJid(),
+		Action:    req.FormValue("action"),
+		Target:    target,
+		Queue:     req.FormValue("queue"),
+		Namespace: ctx(req).Namespace().Name,
+		Filter:    f,
+		StartedAt: time.Now(),
+	}
+	if !validAction(actions, op.Action) {
+		return nil, fmt.Errorf("invalid action: %v", op.Action)
+	}
+	if op.Action == "move" && !storage.ValidQueueName.MatchString(op.Queue) {
+		return nil, fmt.Errorf("invalid queue name: %q", op.Queue)
+	}
+
+	s := ctx(req).Server()
+	entry := auditEntry(req, op.Action, target, 0)
+	ctx(req).webui.operations.add(op)
+
+	go func() {
+		c := context.Background()
+		steps, err := collect(c, op)
+		if err == nil {
+			op.total.Store(uint64(len(steps)))
+			for _, step := range steps {
+				if err = step(c); err != nil {
+					break
+				}
+				op.processed.Add(1)
+			}
+		}
+		if err != nil {
+			util.Warnf("Bulk %s of %s failed: %v", op.Action, op.Target, err)
+		}
+		op.finish(err)
+
+		entry.Count = op.Processed()
+		s.Audit(c, entry)
+	}()
+	return op, nil
+}
+
+func startSetOperation(req *http.Request, set storage.SortedSet) (*BulkOperation, error) {
+	ns := ctx(req).Namespace()
+	return startOperation(req, set.Name(), setActions[set.Name()], func(c context.Context, op *BulkOperation) ([]func(context.Context) error, error) {
+		steps := []func(context.Context) error{}
+		err := eachMatchingEntry(c, set, op.Filter, func(entry storage.SortedEntry, job *client.Job) bool {
+			steps = append(steps, func(c context.Context) error {
+				return applyToEntry(c, ns, set, entry, job, op)
+			})
+			return true
+		})
+		return steps, err
+	})
+}
+
+func applyToEntry(c context.Context, ns *server.Namespace, set storage.SortedSet, entry storage.SortedEntry, job *client.Job, op *BulkOperation) error {
+	store := ns.Store()
+	switch op.Action {
+	case "retry", "add_to_queue":
+		key, err := entry.Key()
+		if err != nil {
+			return err
+		}
+		return store.EnqueueFrom(c, set, key)
+	case "delete":
+		return set.RemoveEntry(c, entry)
+	case "kill":
+		return set.MoveTo(c, store.Dead(), entry, ns.Manager().DeadExpiry(job))
+	case "move":
+		key, err := entry.Key()
+		if err != nil {
+			return err
+		}
+		q, err := store.GetQueue(c, op.Queue)
+		if err != nil {
+			return err
+		}
+		payload, err := server.RewriteJob(entry.Value(), map[string]any{"queue": op.Queue, "enqueued_at": util.Nows()})
+		if err != nil {
+			return err
+		}
+		ok, err := set.Remove(c, key)
+		if err != nil || !ok {
+			// the job was removed or moved elsewhere under us
+			return err
+		}
+		return q.Push(c, payload)
+	}
+	return nil
+}
+
+func startQueueOperation(req *http.Request, q storage.Queue) (*BulkOperation, error) {
+	ns := ctx(req).Namespace()
+	return startOperation(req, q.Name(), queueActions, func(c context.Context, op *BulkOperation) ([]func(context.Context) error, error) {
+		steps := []func(context.Context) error{}
+		err := eachMatchingJob(c, q, op.Filter, func(data []byte, job *client.Job) bool {
+			steps = append(steps, func(c context.Context) error {
+				return applyToQueued(c, ns, q, data, job, op)
+			})
+			return true
+		})
+		return steps, err
+	})
+}
+
+// applyToQueued skips jobs which a worker fetched since the queue was
+// scanned, so they aren't also killed or moved.
+func applyToQueued(c context.Context, ns *server.Namespace, q storage.Queue, data []byte, job *client.Job, op *BulkOperation) error {
+	store := ns.Store()
+	switch op.Action {
+	case "delete":
+		_, err := q.Remove(c, data)
+		return err
+	case "kill":
+		ok, err := q.Remove(c, data)
+		if err != nil || !ok {
+			return err
+		}
+		expiry := util.Thens(ns.Manager().DeadExpiry(job))
+		return store.Dead().AddElement(c, expiry, job.Jid, data)
+	case "move":
+		dst, err := store.GetQueue(c, op.Queue)
+		if err != nil {
+			return err
+		}
+		payload, err := server.RewriteJob(data, map[string]any{"queue": op.Queue})
+		if err != nil {
+			return err
+		}
+		_, err = q.Move(c, dst, data, payload)
+		return err
+	}
+	return nil
+}
+
+// bulkRedirect shows the progress of a newly started bulk action.
+func bulkRedirect(w http.ResponseWriter, r *http.Request, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	Redirect(w, r, "/operations", http.StatusFound)
+}
@@ -9,11 +9,12 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/contribsys/faktory/client"
 	"github.com/contribsys/faktory/server"
 )
 
 func statsHandler(w http.ResponseWriter, r *http.Request) {
-	thing, err := ctx(r).Server().CurrentState()
+	thing, err := ctx(r).Namespace().CurrentState()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -66,6 +67,12 @@ func queueHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if r.FormValue("scope") == "filtered" {
+			_, err := startQueueOperation(r, q)
+			bulkRedirect(w, r, err)
+			return
+		}
+
 		keys := r.Form["bkey"]
 		if len(keys) > 0 {
 			// delete specific entries
@@ -83,24 +90,39 @@ func queueHandler(w http.ResponseWriter, r *http.Request) {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+			audit(r, "delete", q.Name(), uint64(len(bkeys)))
 		} else {
 			action := r.FormValue("action")
 			switch action {
 			case "delete":
 				// clear entire queue
+				count := q.Size(c)
 				_, err := q.Clear(c)
 				if err != nil {
 					http.Error(w, err.Error(), http.StatusInternalServerError)
 					return
 				}
+				audit(r, "clear", q.Name(), count)
+			case "move":
+				dst := r.FormValue("queue")
+				count, err := ctx(r).Namespace().MoveQueue(c, q.Name(), dst, nil)
+				if count > 0 {
+					audit(r, "move", q.Name()+" to "+dst, count)
+				}
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				Redirect(w, r, "/queues/"+dst, http.StatusFound)
+				return
 			case "pause":
-				err := ctx(r).webui.Server.Manager().PauseQueue(c, q.Name())
+				err := ctx(r).Manager().PauseQueue(c, q.Name())
 				if err != nil {
 					http.Error(w, err.Error(), http.StatusInternalServerError)
 					return
 				}
 			case "resume":
-				err := ctx(r).webui.Server.Manager().ResumeQueue(c, q.Name())
+				err := ctx(r).Manager().ResumeQueue(c, q.Name())
 				if err != nil {
 					http.Error(w, err.Error(), http.StatusInternalServerError)
 					return
@@ -114,6 +136,11 @@ func queueHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := parseFilter(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	currentPage := uint64(1)
 	p := r.URL.Query()["page"]
 	if p != nil {
@@ -133,6 +160,12 @@ func retriesHandler(w http.ResponseWriter, r *http.Request) {
 	set := ctx(r).Store().Retries()
 
 	if r.Method == "POST" {
+		if r.FormValue("scope") == "filtered" {
+			_, err := startSetOperation(r, set)
+			bulkRedirect(w, r, err)
+			return
+		}
+
 		action := r.FormValue("action")
 		keys := r.Form["key"]
 		err := actOn(r, set, action, keys)
@@ -144,6 +177,11 @@ func retriesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := parseFilter(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	currentPage := uint64(1)
 	p := r.URL.Query()["page"]
 	if p != nil {
@@ -214,6 +252,12 @@ func scheduledHandler(w http.ResponseWriter, r *http.Request) {
 	set := ctx(r).Store().Scheduled()
 
 	if r.Method == "POST" {
+		if r.FormValue("scope") == "filtered" {
+			_, err := startSetOperation(r, set)
+			bulkRedirect(w, r, err)
+			return
+		}
+
 		action := r.FormValue("action")
 		keys := r.Form["key"]
 		err := actOn(r, set, action, keys)
@@ -225,6 +269,11 @@ func scheduledHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := parseFilter(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	currentPage := uint64(1)
 	p := r.URL.Query()["page"]
 	if p != nil {
@@ -291,6 +340,12 @@ func morgueHandler(w http.ResponseWriter, r *http.Request) {
 	set := ctx(r).Store().Dead()
 
 	if r.Method == "POST" {
+		if r.FormValue("scope") == "filtered" {
+			_, err := startSetOperation(r, set)
+			bulkRedirect(w, r, err)
+			return
+		}
+
 		action := r.FormValue("action")
 		keys := r.Form["key"]
 		err := actOn(r, set, action, keys)
@@ -302,6 +357,11 @@ func morgueHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := parseFilter(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	currentPage := uint64(1)
 	p := r.URL.Query()["page"]
 	if p != nil {
@@ -350,6 +410,34 @@ func deadHandler(w http.ResponseWriter, r *http.Request) {
 	ego_dead(w, r, key, job)
 }
 
+func completedHandler(w http.ResponseWriter, r *http.Request) {
+	ego_listCompleted(w, r, r.URL.Query().Get("q"))
+}
+
+func completedQueueHandler(w http.ResponseWriter, r *http.Request) {
+	name := LAST_ELEMENT.FindStringSubmatch(r.URL.Path)
+	if name == nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	queueName := name[1]
+	set := ctx(r).Store().Completed(queueName)
+
+	currentPage := uint64(1)
+	p := r.URL.Query()["page"]
+	if p != nil {
+		val, err := strconv.Atoi(p[0])
+		if err != nil || val < 0 {
+			http.Error(w, "Invalid parameter", http.StatusBadRequest)
+			return
+		}
+		currentPage = uint64(val) // nolint:gosec
+	}
+	count := uint64(25)
+
+	ego_completedQueue(w, r, queueName, set, r.URL.Query().Get("q"), count, currentPage)
+}
+
 func busyHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "POST" {
 		wid := r.FormValue("wid")
@@ -366,8 +454,9 @@ func busyHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
+			ns := ctx(r).Namespace().Name
 			for _, client := range ctx(r).Server().Heartbeats() {
-				if wid == "all" || wid == client.Wid {
+				if client.Namespace == ns && (wid == "all" || wid == client.Wid) {
 					client.Signal(signal)
 				}
 			}
@@ -378,10 +467,126 @@ func busyHandler(w http.ResponseWriter, r *http.Request) {
 	ego_busy(w, r)
 }
 
+func errorsHandler(w http.ResponseWriter, r *http.Request) {
+	groups, truncated, err := groupErrors(r.Context(), ctx(r).Store(), ErrorScanLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ego_errors(w, r, groups, truncated)
+}
+
+func errorGroupHandler(w http.ResponseWriter, r *http.Request) {
+	name := LAST_ELEMENT.FindStringSubmatch(r.URL.Path)
+	if name == nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	c := r.Context()
+	eg, truncated, err := findErrorGroup(c, ctx(r).Store(), name[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if eg == nil {
+		// the jobs have been retried or deleted since the page was loaded
+		Redirect(w, r, "/errors", http.StatusFound)
+		return
+	}
+
+	if r.Method == "POST" {
+		action := r.FormValue("action")
+		counts, err := actOnErrors(c, ctx(r).Namespace(), eg, action)
+		for target, count := range counts {
+			audit(r, action, string(target), count)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		Redirect(w, r, "/errors", http.StatusFound)
+		return
+	}
+
+	ego_errorGroup(w, r, eg, truncated)
+}
+
+func operationsHandler(w http.ResponseWriter, r *http.Request) {
+	ego_operations(w, r, ctx(r).webui.operations.In(ctx(r).Namespace().Name))
+}
+
+func auditHandler(w http.ResponseWriter, r *http.Request) {
+	currentPage := uint64(1)
+	p := r.URL.Query()["page"]
+	if p != nil {
+		val, err := strconv.Atoi(p[0])
+		if err != nil || val < 1 {
+			http.Error(w, "Invalid parameter", http.StatusBadRequest)
+			return
+		}
+		currentPage = uint64(val) // nolint:gosec
+	}
+	count := uint64(25)
+
+	entries, err := auditLog(r, count, currentPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ego_audit(w, r, entries, count, currentPage)
+}
+
 func debugHandler(w http.ResponseWriter, r *http.Request) {
 	ego_debug(w, r)
 }
 
+func namespacesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		name := r.FormValue("namespace")
+		if ctx(r).Server().Namespace(name) == nil {
+			http.Error(w, "Invalid namespace", http.StatusBadRequest)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     "faktory_namespace",
+			Value:    name,
+			Path:     root(r) + "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	namespaces := ctx(r).Server().Namespaces()
+	states := make([]*client.FaktoryState, len(namespaces))
+	for idx, ns := range namespaces {
+		state, err := ns.CurrentState()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		states[idx] = state
+	}
+	ego_namespaces(w, r, namespaces, states)
+}
+
 func Redirect(w http.ResponseWriter, r *http.Request, path string, code int) {
 	http.Redirect(w, r, relative(r, path), code)
 }
+
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if ctx(r).Manager().MetricsRetention() == 0 {
+		ego_metrics(w, r, 0, nil)
+		return
+	}
+
+	minutes := metricsMinutes(r)
+	history, err := executionHistory(r, minutes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ego_metrics(w, r, minutes, history)
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/contribsys/faktory/client"
+	"github.com/contribsys/faktory/manager"
 	"github.com/contribsys/faktory/server"
 	"github.com/contribsys/faktory/storage"
 	"github.com/contribsys/faktory/util"
@@ -475,6 +476,341 @@ func TestPages(t *testing.T) {
 			assert.True(t, wrk.IsQuiet())
 		})
 
+		t.Run("Completed", func(t *testing.T) {
+			job := client.NewJob("CompletedWorker", "customer-1234")
+			data, err := json.Marshal(&manager.CompletedJob{Job: job, Wid: "wid123", ReservedAt: util.Nows(), AckedAt: util.Nows()})
+			assert.NoError(t, err)
+			assert.NoError(t, s.Store().Completed("default").AddElement(bg, util.Nows(), job.Jid, data))
+
+			req, err := ui.NewRequest("GET", "http://localhost:7420/completed", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			completedHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "/completed/default"), w.Body.String())
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/completed?q=customer-1234", nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			completedHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), job.Jid), w.Body.String())
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/completed/default", nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			completedQueueHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "CompletedWorker"), w.Body.String())
+			assert.True(t, strings.Contains(w.Body.String(), "wid123"), w.Body.String())
+		})
+
+		t.Run("Metrics", func(t *testing.T) {
+			req, err := ui.NewRequest("GET", "http://localhost:7420/metrics", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			metricsHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "[job_metrics]"), w.Body.String())
+
+			s.Manager().SetMetricsRetention(time.Hour)
+			defer s.Manager().SetMetricsRetention(0)
+			sample := storage.ExecutionSample{At: time.Now(), Queue: "default", Jobtype: "MetricsWorker", Duration: time.Second}
+			assert.NoError(t, s.Store().RecordExecution(bg, sample, time.Hour))
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/metrics?minutes=15", nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			metricsHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "MetricsWorker"), w.Body.String())
+			assert.True(t, strings.Contains(w.Body.String(), "metrics-chart"), w.Body.String())
+		})
+
+		t.Run("Errors", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(bg))
+			fail := func(set storage.SortedSet, jobtype string, msg string) {
+				job := client.NewJob(jobtype, 1)
+				job.Failure = &client.Failure{
+					FailedAt:     util.Nows(),
+					ErrorType:    "Net::ReadTimeout",
+					ErrorMessage: msg,
+					Backtrace:    []string{"app/jobs/sync_job.rb:12"},
+				}
+				assert.NoError(t, set.Add(bg, job))
+			}
+			retries := s.Store().Retries()
+			dead := s.Store().Dead()
+			fail(retries, "SyncJob", "timed out after 5 seconds")
+			fail(retries, "SyncJob", "timed out after 10 seconds")
+			fail(dead, "SyncJob", "timed out after 30 seconds")
+			fail(retries, "OtherJob", "timed out after 5 seconds")
+
+			req, err := ui.NewRequest("GET", "http://localhost:7420/errors", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			errorsHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "timed out after ? seconds"), w.Body.String())
+
+			groups, truncated, err := groupErrors(bg, s.Store(), ErrorScanLimit)
+			assert.NoError(t, err)
+			assert.False(t, truncated)
+			assert.Len(t, groups, 2)
+
+			// only the latest retry and dead job
+			latest, truncated, err := groupErrors(bg, s.Store(), 1)
+			assert.NoError(t, err)
+			assert.True(t, truncated)
+			total := uint64(0)
+			for _, eg := range latest {
+				total += eg.Count()
+			}
+			assert.EqualValues(t, 2, total)
+			eg := groups[0]
+			assert.Equal(t, "SyncJob", eg.Jobtype)
+			assert.EqualValues(t, 2, eg.Retries)
+			assert.EqualValues(t, 1, eg.Dead)
+			assert.Len(t, eg.Samples, 3)
+			assert.False(t, eg.FirstSeen.IsZero())
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/errors/"+eg.ID(), nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			errorGroupHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "app/jobs/sync_job.rb:12"), w.Body.String())
+
+			payload := url.Values{"action": {"kill"}}
+			req, err = ui.NewRequest("POST", "http://localhost:7420/errors/"+eg.ID(), strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w = httptest.NewRecorder()
+			errorGroupHandler(w, req)
+			assert.Equal(t, 302, w.Code)
+			assert.EqualValues(t, 1, retries.Size(bg))
+			assert.EqualValues(t, 3, dead.Size(bg))
+
+			payload = url.Values{"action": {"retry"}}
+			req, err = ui.NewRequest("POST", "http://localhost:7420/errors/"+eg.ID(), strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w = httptest.NewRecorder()
+			errorGroupHandler(w, req)
+			assert.Equal(t, 302, w.Code)
+			assert.EqualValues(t, 1, retries.Size(bg))
+			assert.EqualValues(t, 0, dead.Size(bg))
+			q, err := s.Store().GetQueue(bg, "default")
+			assert.NoError(t, err)
+			assert.EqualValues(t, 3, q.Size(bg))
+
+			// the group is gone now
+			req, err = ui.NewRequest("GET", "http://localhost:7420/errors/"+eg.ID(), nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			errorGroupHandler(w, req)
+			assert.Equal(t, 302, w.Code)
+		})
+
+		t.Run("Audit", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(bg))
+			req, err := ui.NewRequest("GET", "http://localhost:7420/audit", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			auditHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "[audit]"), w.Body.String())
+
+			s.Options.GlobalConfig = map[string]any{"audit": map[string]any{"enabled": true}}
+			s.Reload()
+			defer func() {
+				s.Options.GlobalConfig = map[string]any{}
+				s.Reload()
+			}()
+
+			// Flush keeps the audit log
+			before := s.Store().AuditSize(bg)
+			dead := s.Store().Dead()
+			assert.NoError(t, dead.Add(bg, client.NewJob("AuditWorker", 1)))
+			assert.NoError(t, dead.Add(bg, client.NewJob("AuditWorker", 2)))
+
+			payload := url.Values{
+				"key":    {"all"},
+				"action": {"delete"},
+			}
+			req, err = ui.NewRequest("POST", "http://localhost:7420/morgue", strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("mike", "secret")
+			w = httptest.NewRecorder()
+			morgueHandler(w, req)
+			assert.Equal(t, 302, w.Code)
+			assert.EqualValues(t, 0, dead.Size(bg))
+
+			entries, err := s.Store().AuditLog(bg, 0, 10)
+			assert.NoError(t, err)
+			assert.Len(t, entries, int(before)+1)
+			assert.Equal(t, "web:mike", entries[0].Actor)
+			assert.Equal(t, "delete", entries[0].Command)
+			assert.Equal(t, "dead", entries[0].Target)
+			assert.EqualValues(t, 2, entries[0].Count)
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/audit", nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			auditHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "web:mike"), w.Body.String())
+			assert.False(t, strings.Contains(w.Body.String(), "[audit]"), w.Body.String())
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/audit?page=0", nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			auditHandler(w, req)
+			assert.Equal(t, 400, w.Code)
+		})
+
+		t.Run("EditJob", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(bg))
+			dead := s.Store().Dead()
+			job := client.NewJob("InvoiceJob", "cust_12O")
+			job.Failure = &client.Failure{ErrorType: "NotFound", FailedAt: util.Nows()}
+			data, err := json.Marshal(job)
+			assert.NoError(t, err)
+			ts := util.Nows()
+			assert.NoError(t, dead.AddElement(bg, ts, job.Jid, data))
+			key := fmt.Sprintf("%s|%s", ts, job.Jid)
+
+			req, err := ui.NewRequest("GET", "http://localhost:7420/edit/dead/"+key, nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			editHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "cust_12O"), w.Body.String())
+
+			payload := url.Values{
+				"args":  {`["cust_120"`},
+				"queue": {"default"},
+				"mode":  {"replace"},
+			}
+			req, err = ui.NewRequest("POST", "http://localhost:7420/edit/dead/"+key, strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w = httptest.NewRecorder()
+			editHandler(w, req)
+			assert.Equal(t, 400, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "args must be a JSON array"), w.Body.String())
+
+			payload.Set("args", `["cust_120"]`)
+			req, err = ui.NewRequest("POST", "http://localhost:7420/edit/dead/"+key, strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w = httptest.NewRecorder()
+			editHandler(w, req)
+			assert.Equal(t, 302, w.Code)
+
+			entry, err := dead.Get(bg, []byte(key))
+			assert.NoError(t, err)
+			edited, err := entry.Job()
+			assert.NoError(t, err)
+			assert.Equal(t, job.Jid, edited.Jid)
+			assert.Equal(t, "cust_120", edited.Args[0])
+			assert.NotNil(t, edited.Failure)
+
+			payload.Set("queue", "invoices")
+			payload.Set("mode", "push")
+			req, err = ui.NewRequest("POST", "http://localhost:7420/edit/dead/"+key, strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w = httptest.NewRecorder()
+			editHandler(w, req)
+			assert.Equal(t, 302, w.Code)
+			assert.EqualValues(t, 0, dead.Size(bg))
+
+			q, err := s.Store().GetQueue(bg, "invoices")
+			assert.NoError(t, err)
+			assert.EqualValues(t, 1, q.Size(bg))
+			err = q.Each(bg, func(_ int, data []byte) error {
+				var pushed client.Job
+				assert.NoError(t, json.Unmarshal(data, &pushed))
+				assert.NotEqual(t, job.Jid, pushed.Jid)
+				assert.Nil(t, pushed.Failure)
+				from, ok := pushed.GetCustom("edited_from")
+				assert.True(t, ok)
+				assert.Equal(t, job.Jid, from.(map[string]any)["jid"])
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+
+		t.Run("BulkActions", func(t *testing.T) {
+			assert.NoError(t, s.Store().Flush(bg))
+			retries := s.Store().Retries()
+			for i := 0; i < 3; i++ {
+				job := client.NewJob("FilterJob", i)
+				job.Failure = &client.Failure{ErrorType: "RuntimeError", RetryCount: 1, NextAt: util.Thens(time.Now().Add(time.Hour))}
+				assert.NoError(t, retries.Add(bg, job))
+			}
+			other := client.NewJob("OtherJob", 1)
+			other.Failure = &client.Failure{ErrorType: "RuntimeError", RetryCount: 1, NextAt: util.Thens(time.Now().Add(time.Hour))}
+			assert.NoError(t, retries.Add(bg, other))
+
+			req, err := ui.NewRequest("GET", "http://localhost:7420/retries?jobtype=FilterJob", nil)
+			assert.NoError(t, err)
+			w := httptest.NewRecorder()
+			retriesHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "FilterJob"), w.Body.String())
+			assert.False(t, strings.Contains(w.Body.String(), "OtherJob"), w.Body.String())
+			assert.True(t, strings.Contains(w.Body.String(), "scope"), w.Body.String())
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/retries?regexp=%28", nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			retriesHandler(w, req)
+			assert.Equal(t, 400, w.Code)
+
+			// bulk actions require a filter
+			payload := url.Values{
+				"scope":  {"filtered"},
+				"action": {"move"},
+				"queue":  {"elsewhere"},
+			}
+			req, err = ui.NewRequest("POST", "http://localhost:7420/retries", strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w = httptest.NewRecorder()
+			retriesHandler(w, req)
+			assert.Equal(t, 400, w.Code)
+
+			payload.Set("jobtype", "FilterJob")
+			req, err = ui.NewRequest("POST", "http://localhost:7420/retries", strings.NewReader(payload.Encode()))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w = httptest.NewRecorder()
+			retriesHandler(w, req)
+			assert.Equal(t, 302, w.Code)
+			assert.Equal(t, "/operations", w.Header().Get("Location"))
+
+			op := ui.operations.All()[0]
+			assert.Eventually(t, op.Done, 5*time.Second, 10*time.Millisecond)
+			assert.NoError(t, op.Err())
+			assert.EqualValues(t, 3, op.Processed())
+			assert.EqualValues(t, 100, op.Percent())
+			assert.EqualValues(t, 1, retries.Size(bg))
+
+			q, err := s.Store().GetQueue(bg, "elsewhere")
+			assert.NoError(t, err)
+			assert.EqualValues(t, 3, q.Size(bg))
+
+			req, err = ui.NewRequest("GET", "http://localhost:7420/operations", nil)
+			assert.NoError(t, err)
+			w = httptest.NewRecorder()
+			operationsHandler(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.True(t, strings.Contains(w.Body.String(), "jobtype=FilterJob"), w.Body.String())
+		})
+
 	})
 }
 
@@ -34,7 +34,11 @@ func ego_paging(w io.Writer, req *http.Request, url string, total_size, count, c
 //line paging.ego:15
 		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(relative(req, url))))
 //line paging.ego:15
-		_, _ = io.WriteString(w, "?page=1\">&laquo;</a>\n    </li>\n    ")
+		_, _ = io.WriteString(w, "?")
+//line paging.ego:15
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(pageparam(req, 1))))
+//line paging.ego:15
+		_, _ = io.WriteString(w, "\">&laquo;</a>\n    </li>\n    ")
 //line paging.ego:17
 		if current_page > 1 {
 //line paging.ego:18
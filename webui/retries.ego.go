@@ -18,7 +18,7 @@ import (
 )
 
 func ego_listRetries(w io.Writer, req *http.Request, set storage.SortedSet, count, currentPage uint64) {
-	totalSize := uint64(set.Size(req.Context()))
+	totalSize := setSize(req, set)
 
 //line retries.ego:14
 	_, _ = io.WriteString(w, "\n\n")
@@ -43,7 +43,7 @@ func ego_listRetries(w io.Writer, req *http.Request, set storage.SortedSet, coun
 //line retries.ego:27
 		_, _ = io.WriteString(w, "\n  ")
 //line retries.ego:27
-		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(filtering("retries"))))
+		ego_filtering(w, req, "/retries", setActions["retries"])
 //line retries.ego:28
 		_, _ = io.WriteString(w, "\n</header>\n\n")
 //line retries.ego:30
@@ -123,7 +123,7 @@ func ego_listRetries(w io.Writer, req *http.Request, set storage.SortedSet, coun
 //line retries.ego:64
 				_, _ = io.WriteString(w, "</code></td>\n            <td>\n              <div class=\"args\">")
 //line retries.ego:66
-				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayArgs(job.Args))))
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayJobArgs(req, job))))
 //line retries.ego:66
 				_, _ = io.WriteString(w, "</div>\n            </td>\n            <td>\n              <div>")
 //line retries.ego:69
@@ -151,7 +151,7 @@ func ego_listRetries(w io.Writer, req *http.Request, set storage.SortedSet, coun
 //line retries.ego:78
 			_, _ = io.WriteString(w, "</button>\n    </div>\n  </form>\n\n  ")
 //line retries.ego:82
-			if unfiltered() {
+			if unfiltered(req) {
 //line retries.ego:83
 				_, _ = io.WriteString(w, "\n    <form action=\"")
 //line retries.ego:83
@@ -99,12 +99,24 @@ func ego_retry(w io.Writer, req *http.Request, key string, retry *client.Job) {
 //line retry.ego:52
 		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Delete"))))
 //line retry.ego:52
-		_, _ = io.WriteString(w, "</button>\n  </div>\n</form>\n")
-//line retry.ego:55
-	})
+		_, _ = io.WriteString(w, "</button>\n    <a class=\"btn btn-default btn-sm\" href=\"")
+//line retry.ego:53
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line retry.ego:53
+		_, _ = io.WriteString(w, "/edit/retries/")
+//line retry.ego:53
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(key)))
+//line retry.ego:53
+		_, _ = io.WriteString(w, "\">")
+//line retry.ego:53
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Edit"))))
+//line retry.ego:53
+		_, _ = io.WriteString(w, "</a>\n  </div>\n</form>\n")
 //line retry.ego:56
+	})
+//line retry.ego:57
 	_, _ = io.WriteString(w, "\n")
-//line retry.ego:56
+//line retry.ego:57
 }
 
 var _ fmt.Stringer
@@ -18,7 +18,7 @@ import (
 )
 
 func ego_listScheduled(w io.Writer, req *http.Request, set storage.SortedSet, count, currentPage uint64) {
-	totalSize := uint64(set.Size(req.Context()))
+	totalSize := setSize(req, set)
 
 //line scheduled.ego:14
 	_, _ = io.WriteString(w, "\n\n")
@@ -43,7 +43,7 @@ func ego_listScheduled(w io.Writer, req *http.Request, set storage.SortedSet, co
 //line scheduled.ego:26
 		_, _ = io.WriteString(w, "\n  ")
 //line scheduled.ego:26
-		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(filtering("scheduled"))))
+		ego_filtering(w, req, "/scheduled", setActions["scheduled"])
 //line scheduled.ego:27
 		_, _ = io.WriteString(w, "\n</header>\n\n")
 //line scheduled.ego:29
@@ -111,7 +111,7 @@ func ego_listScheduled(w io.Writer, req *http.Request, set storage.SortedSet, co
 //line scheduled.ego:57
 				_, _ = io.WriteString(w, "</code></td>\n            <td>\n               <div class=\"args\">")
 //line scheduled.ego:59
-				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayArgs(job.Args))))
+				_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(displayJobArgs(req, job))))
 //line scheduled.ego:59
 				_, _ = io.WriteString(w, "</div>\n            </td>\n          </tr>\n        ")
 //line scheduled.ego:62
@@ -51,12 +51,24 @@ func ego_scheduled_job(w io.Writer, req *http.Request, key string, job *client.J
 //line scheduled_job.ego:20
 		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Delete"))))
 //line scheduled_job.ego:20
-		_, _ = io.WriteString(w, "</button>\n  </div>\n</form>\n\n")
-//line scheduled_job.ego:24
-	})
+		_, _ = io.WriteString(w, "</button>\n    <a class=\"btn btn-default\" href=\"")
+//line scheduled_job.ego:21
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(root(req))))
+//line scheduled_job.ego:21
+		_, _ = io.WriteString(w, "/edit/scheduled/")
+//line scheduled_job.ego:21
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(key)))
+//line scheduled_job.ego:21
+		_, _ = io.WriteString(w, "\">")
+//line scheduled_job.ego:21
+		_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(t(req, "Edit"))))
+//line scheduled_job.ego:21
+		_, _ = io.WriteString(w, "</a>\n  </div>\n</form>\n\n")
 //line scheduled_job.ego:25
+	})
+//line scheduled_job.ego:26
 	_, _ = io.WriteString(w, "\n")
-//line scheduled_job.ego:25
+//line scheduled_job.ego:26
 }
 
 var _ fmt.Stringer
@@ -0,0 +1,52 @@
+// Draws the per-minute processed and failed counts on the Metrics page
+// as a simple SVG bar chart.
+document.addEventListener("DOMContentLoaded", function () {
+  var el = document.getElementById("metrics-chart");
+  if (!el) {
+    return;
+  }
+
+  var series = JSON.parse(el.dataset.series || "[]");
+  if (series.length === 0) {
+    return;
+  }
+
+  var ns = "http://www.w3.org/2000/svg";
+  var width = el.clientWidth || 800;
+  var height = 160;
+  var max = 1;
+  series.forEach(function (point) {
+    max = Math.max(max, point[1]);
+  });
+
+  var svg = document.createElementNS(ns, "svg");
+  svg.setAttribute("width", width);
+  svg.setAttribute("height", height);
+  svg.setAttribute("class", "metrics-chart");
+
+  var slot = width / series.length;
+  var barWidth = Math.max(1, slot - 1);
+  series.forEach(function (point, idx) {
+    var when = new Date(point[0] * 1000).toLocaleTimeString();
+    [
+      [point[1], "processed", el.dataset.processedLabel],
+      [point[2], "failed", el.dataset.failedLabel],
+    ].forEach(function (bar) {
+      if (bar[0] === 0) {
+        return;
+      }
+      var h = Math.max(1, Math.round((bar[0] / max) * height));
+      var rect = document.createElementNS(ns, "rect");
+      rect.setAttribute("x", idx * slot);
+      rect.setAttribute("y", height - h);
+      rect.setAttribute("width", barWidth);
+      rect.setAttribute("height", h);
+      rect.setAttribute("class", bar[1]);
+      var title = document.createElementNS(ns, "title");
+      title.textContent = when + " " + bar[2] + ": " + bar[0];
+      rect.appendChild(title);
+      svg.appendChild(rect);
+    });
+  });
+  el.appendChild(svg);
+});
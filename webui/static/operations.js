@@ -0,0 +1,11 @@
+// Reloads the Operations page while any bulk operation is running
+// so its progress stays current.
+document.addEventListener("DOMContentLoaded", function () {
+  var el = document.getElementById("operations");
+  if (!el || el.dataset.running !== "true") {
+    return;
+  }
+  setTimeout(function () {
+    window.location.reload();
+  }, 2000);
+});
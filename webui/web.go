@@ -33,6 +33,10 @@ var (
 		{"Retries", "/retries"},
 		{"Scheduled", "/scheduled"},
 		{"Dead", "/morgue"},
+		{"Errors", "/errors"},
+		{"Completed", "/completed"},
+		{"Metrics", "/metrics"},
+		{"Audit", "/audit"},
 	}
 
 	//go:embed static/*.css static/*.js static/img/*
@@ -89,6 +93,7 @@ type WebUI struct {
 	proxy       *http.ServeMux
 	Title       string
 	ExtraCssUrl string
+	operations  operations
 
 	Options Options
 }
@@ -96,6 +101,9 @@ type WebUI struct {
 type Options struct {
 	Binding  string
 	Password string //gosec:disable
+	// DecryptPassword logs in users who may see the args of encrypted
+	// jobs, everyone else sees "[encrypted]".
+	DecryptPassword string //gosec:disable
 }
 
 func defaultOptions() Options {
@@ -125,9 +133,20 @@ func newWeb(s *server.Server, opts Options) *WebUI {
 	app.HandleFunc("/scheduled/", Log(ui, scheduledJobHandler))
 	app.HandleFunc("/morgue", Log(ui, morgueHandler))
 	app.HandleFunc("/morgue/", Log(ui, deadHandler))
+	app.HandleFunc("/edit/", Log(ui, editHandler))
+	app.HandleFunc("/errors", Log(ui, GetOnly(errorsHandler)))
+	app.HandleFunc("/errors/", Log(ui, errorGroupHandler))
+	app.HandleFunc("/completed", Log(ui, GetOnly(completedHandler)))
+	app.HandleFunc("/completed/", Log(ui, GetOnly(completedQueueHandler)))
+	app.HandleFunc("/metrics", Log(ui, GetOnly(metricsHandler)))
+	app.HandleFunc("/operations", Log(ui, GetOnly(operationsHandler)))
+	app.HandleFunc("/audit", Log(ui, AdminOnly(GetOnly(auditHandler))))
 	app.HandleFunc("/busy", Log(ui, busyHandler))
-	app.HandleFunc("/debug", Log(ui, debugHandler))
+	app.HandleFunc("/debug", Log(ui, AdminOnly(debugHandler)))
+	app.HandleFunc("/namespaces", Log(ui, AdminOnly(namespacesHandler)))
 	app.HandleFunc("/health", healthHandler(ui))
+	app.HandleFunc("/api/push", API(ui, PostOnly(apiPushHandler)))
+	app.HandleFunc("/api/push/bulk", API(ui, PostOnly(apiPushBulkHandler)))
 
 	// app.HandleFunc("/debug/pprof/", pprof.Index)
 	// app.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
@@ -208,6 +227,13 @@ func (l *Lifecycle) opts(s *server.Server) Options {
 		pwd = s.Options.Password
 	}
 	opts.Password = pwd
+
+	decrypt := s.Options.String("web", "decrypt_password", "")
+	if decrypt != "" && pwd == "" {
+		util.Warnf("Ignoring web decrypt_password as the Web UI has no password")
+		decrypt = ""
+	}
+	opts.DecryptPassword = decrypt
 	return opts
 }
 
@@ -317,20 +343,62 @@ func setup(ui *WebUI, pass http.HandlerFunc, debug bool) http.HandlerFunc {
 		}
 	}
 	if ui.Options.Password != "" {
-		return basicAuth(ui.Options.Password, genericSetup)
+		return basicAuth(genericSetup, ui.authenticate)
 	}
 	return genericSetup
 }
 
-func basicAuth(pwd string, pass http.HandlerFunc) http.HandlerFunc {
+// authenticate accepts the Web UI and decrypt passwords with any
+// username, or a namespace's name and password which limits the user
+// to that namespace.
+func (ui *WebUI) authenticate(username, password string) bool {
+	for _, pwd := range []string{ui.Options.Password, ui.Options.DecryptPassword} {
+		if pwd != "" && subtle.ConstantTimeCompare([]byte(password), []byte(pwd)) == 1 {
+			return true
+		}
+	}
+	_, scoped := ui.namespaceLogin(username, password)
+	return scoped
+}
+
+// namespaceLogin returns the namespace whose credentials were given.
+func (ui *WebUI) namespaceLogin(username, password string) (*server.Namespace, bool) {
+	if username == "" || ui.Options.Password == "" {
+		return nil, false
+	}
+	ns := ui.Server.Namespace(username)
+	if ns == nil || !ns.Authenticate(password) {
+		return nil, false
+	}
+	return ns, true
+}
+
+// namespaceFor returns the namespace the request may see and true if
+// the user logged in with a namespace's credentials and can't see any
+// other namespace.  Everyone else picks a namespace on the Namespaces
+// page, which is remembered in a cookie.
+func (ui *WebUI) namespaceFor(req *http.Request) (*server.Namespace, bool) {
+	username, password, _ := req.BasicAuth()
+	if ns, ok := ui.namespaceLogin(username, password); ok {
+		return ns, true
+	}
+	if cookie, _ := req.Cookie("faktory_namespace"); cookie != nil && cookie.Value != "" {
+		if ns := ui.Server.Namespace(cookie.Value); ns != nil {
+			return ns, false
+		}
+	}
+	return ui.Server.Namespace(""), false
+}
+
+func basicAuth(pass http.HandlerFunc, valid func(username, password string) bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_, password, ok := r.BasicAuth()
+		username, password, ok := r.BasicAuth()
 		if !ok {
 			w.Header().Set("WWW-Authenticate", `Basic realm="Faktory"`)
 			http.Error(w, "Authorization required", http.StatusUnauthorized)
 			return
 		}
-		if subtle.ConstantTimeCompare([]byte(password), []byte(pwd)) != 1 {
+		if !valid(username, password) {
 			w.Header().Set("WWW-Authenticate", `Basic realm="Faktory"`)
 			http.Error(w, "Authorization failed", http.StatusUnauthorized)
 			return
@@ -359,6 +427,17 @@ func PostOnly(h http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// AdminOnly rejects users who logged in with a namespace's credentials.
+func AdminOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ctx(r).Scoped() {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
 func cache(h http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Cache-Control", "public, max-age=3600")